@@ -0,0 +1,354 @@
+package amqp
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+var (
+	ErrAMQPNack           = errors.New("message was rejected by the broker")
+	ErrAMQPConfirmTimeout = errors.New("timed out waiting for the broker to confirm the message")
+	ErrAMQPUnroutable     = errors.New("message was returned as unroutable by the broker")
+)
+
+const (
+	amqpConnectionName = "ocm-log-forwarder"
+	amqpExchangeKind   = "topic"
+	amqpContentType    = "application/json"
+
+	// returned messages are drained after each message is confirmed, so the buffer only needs to
+	// hold the returns of a single message.
+	amqpReturnBuffer = 1
+)
+
+// AMQP is a backend which publishes each service log as a persistent message to an amqp 0-9-1
+// exchange, such as a rabbitmq exchange.  A service log is only considered sent once the broker
+// confirms the message, and the connection is re-established if it is lost.
+type AMQP struct {
+	Config       *config.AMQPConfig
+	Dial         amqp091.Config
+	Dialer       Dialer
+	Connection   Connection
+	Channel      Channel
+	SentMessages []string
+
+	returns chan amqp091.Return
+}
+
+// Dialer opens a connection to an amqp broker.
+type Dialer func(url string, dial amqp091.Config) (Connection, error)
+
+// Connection is a connection to an amqp broker.
+type Connection interface {
+	Channel() (Channel, error)
+	IsClosed() bool
+	Close() error
+}
+
+// Channel is the subset of an amqp channel which is used to publish confirmed messages.
+type Channel interface {
+	Confirm(noWait bool) error
+	ExchangeDeclarePassive(name, kind string, durable, autoDelete, internal, noWait bool, args amqp091.Table) error
+	NotifyReturn(returns chan amqp091.Return) chan amqp091.Return
+	Publish(proc *processor.Processor, exchange, key string, mandatory bool, msg amqp091.Publishing) (Confirmation, error)
+	IsClosed() bool
+	Close() error
+}
+
+// Confirmation is the pending confirmation of a published message.
+type Confirmation interface {
+	Wait() bool
+}
+
+// dial opens a connection to an amqp broker with the amqp091 client.
+func dial(url string, dial amqp091.Config) (Connection, error) {
+	connection, err := amqp091.DialConfig(url, dial)
+	if err != nil {
+		return nil, err
+	}
+
+	return &brokerConnection{Connection: connection}, nil
+}
+
+// brokerConnection is a Connection of the amqp091 client.
+type brokerConnection struct {
+	*amqp091.Connection
+}
+
+func (connection *brokerConnection) Channel() (Channel, error) {
+	channel, err := connection.Connection.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	return &brokerChannel{Channel: channel}, nil
+}
+
+// brokerChannel is a Channel of the amqp091 client.
+type brokerChannel struct {
+	*amqp091.Channel
+}
+
+func (channel *brokerChannel) Publish(
+	proc *processor.Processor,
+	exchange, key string,
+	mandatory bool,
+	msg amqp091.Publishing,
+) (Confirmation, error) {
+	return channel.PublishWithDeferredConfirmWithContext(proc.Context, exchange, key, mandatory, false, msg)
+}
+
+func (mq *AMQP) Initialize(proc *processor.Processor) error {
+	amqpConfig, err := config.GetAMQPConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure amqp backend - %w", err)
+	}
+
+	mq.Config = amqpConfig
+	mq.Dialer = dial
+	mq.Dial = amqp091.Config{Properties: amqp091.Table{"connection_name": amqpConnectionName}}
+
+	// without sasl mechanisms, the credentials of the url are used
+	if mq.Config.AuthType == config.DefaultBackendAuthTypeBasic {
+		username, password, err := config.GetAMQPBasicAuth(proc.KubeClient, proc.Context)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve amqp credentials - %w", err)
+		}
+
+		mq.Dial.SASL = []amqp091.Authentication{&amqp091.PlainAuth{Username: username, Password: password}}
+	}
+
+	// the tls configuration is only used for amqps urls
+	if mq.Config.TLSCA != "" {
+		if mq.Dial.TLSClientConfig, err = getTLSConfig(mq.Config); err != nil {
+			return err
+		}
+	}
+
+	// connect now so that an unreachable broker or a missing exchange fails fast
+	if err := mq.connect(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (mq *AMQP) Send(proc *processor.Processor, response *poller.Response) error {
+	var published int
+
+	for _, logEntry := range response.Logs {
+		if mq.HasSent(logEntry) {
+			continue
+		}
+
+		if !mq.connected() {
+			if err := mq.reconnect(proc); err != nil {
+				mq.Log(log.Err(err).Str("cluster", proc.Config.ClusterID), "failed to reconnect to amqp broker")
+
+				break
+			}
+		}
+
+		if err := mq.publish(proc, logEntry); err != nil {
+			mq.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to publish message")
+
+			continue
+		}
+
+		mq.SentMessages = append(mq.SentMessages, logEntry.ID())
+		published++
+	}
+
+	if published > 0 {
+		mq.Log(
+			log.Info().Str("cluster", proc.Config.ClusterID).Str("exchange", mq.Config.Exchange).Int("message_count", published),
+			"published service logs to amqp exchange",
+		)
+	}
+
+	return nil
+}
+
+func (mq *AMQP) String() string {
+	return config.DefaultBackendAMQP
+}
+
+func (mq *AMQP) HasSent(message *v1.LogEntry) bool {
+	for i := range mq.SentMessages {
+		if message.ID() == mq.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (mq *AMQP) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", mq.String())).Msg(message)
+}
+
+// connect opens a connection and a channel in confirm mode, and verifies that the exchange exists.
+func (mq *AMQP) connect() error {
+	connection, err := mq.Dialer(mq.Config.URL, mq.Dial)
+	if err != nil {
+		return fmt.Errorf("unable to connect to amqp broker - %w", err)
+	}
+
+	channel, err := connection.Channel()
+	if err != nil {
+		connection.Close()
+
+		return fmt.Errorf("unable to open amqp channel - %w", err)
+	}
+
+	if err := channel.Confirm(false); err != nil {
+		connection.Close()
+
+		return fmt.Errorf("unable to enable publisher confirms - %w", err)
+	}
+
+	// the default exchange always exists and may not be declared
+	if mq.Config.Exchange != "" {
+		err := channel.ExchangeDeclarePassive(mq.Config.Exchange, amqpExchangeKind, true, false, false, false, nil)
+		if err != nil {
+			connection.Close()
+
+			return fmt.Errorf("unable to find amqp exchange [%s] - %w", mq.Config.Exchange, err)
+		}
+	}
+
+	mq.Connection, mq.Channel = connection, channel
+	mq.returns = channel.NotifyReturn(make(chan amqp091.Return, amqpReturnBuffer))
+
+	return nil
+}
+
+// connected determines if the connection and channel are open.  A channel is closed by the broker
+// on a channel error, such as publishing to an exchange which was deleted.
+func (mq *AMQP) connected() bool {
+	return mq.Connection != nil && !mq.Connection.IsClosed() && mq.Channel != nil && !mq.Channel.IsClosed()
+}
+
+// reconnect closes the current connection and connects again using an exponential backoff.
+func (mq *AMQP) reconnect(proc *processor.Processor) error {
+	if mq.Connection != nil {
+		mq.Connection.Close()
+	}
+
+	retry := backoff.WithContext(
+		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), uint64(mq.Config.ReconnectAttempts)),
+		proc.Context,
+	)
+
+	notify := func(err error, wait time.Duration) {
+		mq.Log(log.Warn().Err(err).Str("cluster", proc.Config.ClusterID).Dur("wait", wait), "retrying amqp connection")
+	}
+
+	if err := backoff.RetryNotify(mq.connect, retry, notify); err != nil {
+		return fmt.Errorf("unable to reconnect after [%d] attempts - %w", mq.Config.ReconnectAttempts, err)
+	}
+
+	mq.Log(log.Info().Str("cluster", proc.Config.ClusterID), "reconnected to amqp broker")
+
+	return nil
+}
+
+// publish publishes a service log and waits for the broker to confirm it.
+func (mq *AMQP) publish(proc *processor.Processor, logEntry *v1.LogEntry) error {
+	data, err := utils.MarshalLogEntry(logEntry)
+	if err != nil {
+		return fmt.Errorf("unable to build message - %w", err)
+	}
+
+	key := routingKey(mq.Config.RoutingKey, proc.Config.ClusterID, logEntry)
+
+	confirmation, err := mq.Channel.Publish(
+		proc,
+		mq.Config.Exchange,
+		key,
+		mq.Config.Mandatory,
+		amqp091.Publishing{
+			ContentType:  amqpContentType,
+			DeliveryMode: amqp091.Persistent,
+			MessageId:    logEntry.ID(),
+			Timestamp:    logEntry.Timestamp(),
+			AppId:        amqpConnectionName,
+			Headers: amqp091.Table{
+				utils.TemplateFieldClusterID: proc.Config.ClusterID,
+				utils.TemplateFieldSeverity:  string(logEntry.Severity()),
+			},
+			Body: data,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to publish message with routing key [%s] - %w", key, err)
+	}
+
+	acked := make(chan bool, 1)
+	go func() { acked <- confirmation.Wait() }()
+
+	select {
+	case ack := <-acked:
+		if !ack {
+			return fmt.Errorf("routing key [%s] - %w", key, ErrAMQPNack)
+		}
+	case <-time.After(mq.Config.AckTimeout):
+		// closing the channel rejects the pending confirmation, and the channel is opened again
+		// before the next message
+		mq.Channel.Close()
+
+		return fmt.Errorf("routing key [%s] - %w", key, ErrAMQPConfirmTimeout)
+	}
+
+	// the broker returns an unroutable mandatory message before it confirms it
+	for {
+		select {
+		case returned, ok := <-mq.returns:
+			// the returns are closed with the channel, in which case the message was already confirmed
+			if !ok {
+				return nil
+			}
+
+			if returned.MessageId == logEntry.ID() {
+				return fmt.Errorf("routing key [%s]: %s - %w", key, returned.ReplyText, ErrAMQPUnroutable)
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// routingKey resolves the routing key template for a service log.  Dots in the placeholder
+// values are replaced, so that a value always resolves to a single word of a topic routing key.
+func routingKey(template, clusterID string, logEntry *v1.LogEntry) string {
+	fields := utils.TemplateFields(clusterID, logEntry)
+
+	for key, value := range fields {
+		fields[key] = strings.ReplaceAll(value, ".", "_")
+	}
+
+	return utils.ResolveTemplate(template, fields)
+}
+
+func getTLSConfig(amqpConfig *config.AMQPConfig) (*tls.Config, error) {
+	pool, err := utils.CertPoolFromFile(amqpConfig.TLSCA)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
@@ -0,0 +1,303 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	amqp091 "github.com/rabbitmq/amqp091-go"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+const (
+	outcomeNack    = "nack"
+	outcomeTimeout = "timeout"
+	outcomeReturn  = "return"
+)
+
+var errBrokerUnreachable = errors.New("connection refused")
+
+// brokerStandIn is a stand-in for an amqp broker, which confirms each message unless its id has
+// an outcome, in which case the message is rejected, never confirmed or returned as unroutable.
+// A number of dials may fail, as if the broker is unreachable.
+type brokerStandIn struct {
+	outcomes  map[string]string
+	failDials int
+
+	mutex     sync.Mutex
+	dials     int
+	published []string
+}
+
+func (broker *brokerStandIn) dial(url string, dial amqp091.Config) (Connection, error) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	broker.dials++
+
+	if broker.failDials > 0 {
+		broker.failDials--
+
+		return nil, errBrokerUnreachable
+	}
+
+	return &connectionStandIn{broker: broker}, nil
+}
+
+type connectionStandIn struct {
+	broker *brokerStandIn
+	closed bool
+}
+
+func (connection *connectionStandIn) Channel() (Channel, error) {
+	return &channelStandIn{broker: connection.broker, connection: connection, done: make(chan struct{})}, nil
+}
+
+func (connection *connectionStandIn) IsClosed() bool {
+	return connection.closed
+}
+
+func (connection *connectionStandIn) Close() error {
+	connection.closed = true
+
+	return nil
+}
+
+type channelStandIn struct {
+	broker     *brokerStandIn
+	connection *connectionStandIn
+	returns    chan amqp091.Return
+	done       chan struct{}
+	closed     bool
+}
+
+func (channel *channelStandIn) Confirm(noWait bool) error {
+	return nil
+}
+
+func (channel *channelStandIn) ExchangeDeclarePassive(string, string, bool, bool, bool, bool, amqp091.Table) error {
+	return nil
+}
+
+func (channel *channelStandIn) NotifyReturn(returns chan amqp091.Return) chan amqp091.Return {
+	channel.returns = returns
+
+	return returns
+}
+
+func (channel *channelStandIn) Publish(
+	proc *processor.Processor,
+	exchange, key string,
+	mandatory bool,
+	msg amqp091.Publishing,
+) (Confirmation, error) {
+	channel.broker.mutex.Lock()
+	defer channel.broker.mutex.Unlock()
+
+	channel.broker.published = append(channel.broker.published, msg.MessageId)
+
+	switch channel.broker.outcomes[msg.MessageId] {
+	case outcomeNack:
+		return &confirmationStandIn{ack: false}, nil
+	case outcomeTimeout:
+		return &confirmationStandIn{done: channel.done}, nil
+	case outcomeReturn:
+		if mandatory {
+			channel.returns <- amqp091.Return{MessageId: msg.MessageId, ReplyText: "NO_ROUTE"}
+		}
+	}
+
+	return &confirmationStandIn{ack: true}, nil
+}
+
+func (channel *channelStandIn) IsClosed() bool {
+	return channel.closed || channel.connection.closed
+}
+
+// Close closes the channel, which rejects the pending confirmations and closes the returns.
+func (channel *channelStandIn) Close() error {
+	if !channel.closed {
+		channel.closed = true
+
+		close(channel.done)
+		close(channel.returns)
+	}
+
+	return nil
+}
+
+// confirmationStandIn is a confirmation which is either available immediately, or rejected once
+// the channel is closed.
+type confirmationStandIn struct {
+	ack  bool
+	done chan struct{}
+}
+
+func (confirmation *confirmationStandIn) Wait() bool {
+	if confirmation.done != nil {
+		<-confirmation.done
+
+		return false
+	}
+
+	return confirmation.ack
+}
+
+func TestAMQP_Send(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		outcomes   map[string]string
+		disconnect bool
+		failDials  int
+		wantSent   []string
+		wantDials  int
+	}{
+		{
+			name:      "ensure confirmed messages are sent",
+			outcomes:  map[string]string{},
+			wantSent:  []string{"1", "2"},
+			wantDials: 1,
+		},
+		{
+			name:      "ensure rejected messages are not sent",
+			outcomes:  map[string]string{"1": outcomeNack},
+			wantSent:  []string{"2"},
+			wantDials: 1,
+		},
+		{
+			name:      "ensure unconfirmed messages are not sent and the channel is opened again",
+			outcomes:  map[string]string{"1": outcomeTimeout},
+			wantSent:  []string{"2"},
+			wantDials: 2,
+		},
+		{
+			name:      "ensure unroutable mandatory messages are not sent",
+			outcomes:  map[string]string{"1": outcomeReturn},
+			wantSent:  []string{"2"},
+			wantDials: 1,
+		},
+		{
+			name:       "ensure a lost connection is re-established",
+			outcomes:   map[string]string{},
+			disconnect: true,
+			failDials:  1,
+			wantSent:   []string{"1", "2"},
+			wantDials:  3,
+		},
+		{
+			name:       "ensure messages are left for the next poll when the broker is unreachable",
+			outcomes:   map[string]string{},
+			disconnect: true,
+			failDials:  2,
+			wantSent:   []string{},
+			wantDials:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			broker := &brokerStandIn{outcomes: tt.outcomes}
+			mq := &AMQP{
+				Config: &config.AMQPConfig{
+					Exchange:          "ocm",
+					RoutingKey:        "ocm.{severity}.{cluster_id}",
+					Mandatory:         true,
+					AckTimeout:        100 * time.Millisecond,
+					ReconnectAttempts: 1,
+				},
+				Dialer: broker.dial,
+			}
+
+			if err := mq.connect(); err != nil {
+				t.Fatalf("AMQP.connect() error = %v", err)
+			}
+
+			if tt.disconnect {
+				mq.Connection.Close()
+			}
+
+			broker.failDials = tt.failDials
+
+			logs := []*v1.LogEntry{}
+
+			for _, id := range []string{"1", "2"} {
+				logEntry, err := v1.NewLogEntry().ID(id).Severity(v1.SeverityWarning).Build()
+				if err != nil {
+					t.Fatalf("unable to build log entry - %v", err)
+				}
+
+				logs = append(logs, logEntry)
+			}
+
+			proc := &processor.Processor{Config: &config.Config{ClusterID: "test"}, Context: context.Background()}
+
+			if err := mq.Send(proc, &poller.Response{Logs: logs}); err != nil {
+				t.Fatalf("AMQP.Send() error = %v", err)
+			}
+
+			if strings.Join(mq.SentMessages, ",") != strings.Join(tt.wantSent, ",") {
+				t.Errorf("AMQP.Send() sent = %v, want %v", mq.SentMessages, tt.wantSent)
+			}
+
+			if broker.dials != tt.wantDials {
+				t.Errorf("AMQP.Send() dials = %d, want %d", broker.dials, tt.wantDials)
+			}
+		})
+	}
+}
+
+func Test_routingKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		template    string
+		serviceName string
+		want        string
+	}{
+		{
+			name:     "ensure placeholders are resolved",
+			template: "ocm.{severity}.{cluster_id}",
+			want:     "ocm.Warning.test",
+		},
+		{
+			name:        "ensure dots in values are replaced",
+			template:    "ocm.{service_name}",
+			serviceName: "cluster.upgrade",
+			want:        "ocm.cluster_upgrade",
+		},
+		{
+			name:     "ensure unknown placeholders are unchanged",
+			template: "ocm.{unknown}",
+			want:     "ocm.{unknown}",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().ID("1").Severity(v1.SeverityWarning).ServiceName(tt.serviceName).Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			if got := routingKey(tt.template, "test", logEntry); got != tt.want {
+				t.Errorf("routingKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
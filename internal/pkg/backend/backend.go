@@ -3,8 +3,26 @@ package backend
 import (
 	"fmt"
 
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/amqp"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/chat"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/cloudwatch"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/elasticsearch"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/events"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/file"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/forward"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/gelf"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/jetstream"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/opensearch"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/otlp"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/pagerduty"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/postgres"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/redisstream"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/s3"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/servicelog"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/stdout"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend/syslog"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
@@ -16,6 +34,26 @@ type Backend interface {
 	String() string
 }
 
+// Lookup is optionally implemented by backends which can be queried for the service logs they
+// already store, such as search engines and databases.  It allows the deduplication state of a
+// backend to survive a restart rather than re-sending every service log.
+type Lookup interface {
+	// Recent returns the ids of the most recently stored service logs for the cluster.
+	Recent(*processor.Processor) ([]string, error)
+
+	// Present returns the subset of the service log ids which are already stored.
+	Present(*processor.Processor, []string) ([]string, error)
+
+	// MarkSent stores service log ids as sent so that they are not sent again.
+	MarkSent(...string)
+}
+
+var (
+	_ Lookup = &elasticsearch.ElasticSearch{}
+	_ Lookup = &opensearch.OpenSearch{}
+	_ Lookup = &postgres.Postgres{}
+)
+
 func Initialize(proc *processor.Processor) (Backend, error) {
 	var backend Backend
 
@@ -24,6 +62,38 @@ func Initialize(proc *processor.Processor) (Backend, error) {
 		backend = &elasticsearch.ElasticSearch{}
 	case config.DefaultBackendStdOut:
 		backend = &stdout.StdOut{}
+	case config.DefaultBackendSlack, config.DefaultBackendTeams:
+		backend = &chat.Chat{Platform: proc.Config.Backend}
+	case config.DefaultBackendPagerDuty:
+		backend = &pagerduty.PagerDuty{}
+	case config.DefaultBackendS3:
+		backend = &s3.S3{}
+	case config.DefaultBackendCloudWatch:
+		backend = &cloudwatch.CloudWatch{}
+	case config.DefaultBackendSyslog:
+		backend = &syslog.Syslog{}
+	case config.DefaultBackendOTLP:
+		backend = &otlp.OTLP{}
+	case config.DefaultBackendGELF:
+		backend = &gelf.GELF{}
+	case config.DefaultBackendForward:
+		backend = &forward.Forward{}
+	case config.DefaultBackendOpenSearch:
+		backend = &opensearch.OpenSearch{}
+	case config.DefaultBackendPostgres:
+		backend = &postgres.Postgres{}
+	case config.DefaultBackendFile:
+		backend = &file.File{}
+	case config.DefaultBackendEvents:
+		backend = &events.Events{}
+	case config.DefaultBackendServiceLog:
+		backend = &servicelog.ServiceLog{}
+	case config.DefaultBackendNATS:
+		backend = &jetstream.JetStream{}
+	case config.DefaultBackendRedis:
+		backend = &redisstream.RedisStream{}
+	case config.DefaultBackendAMQP:
+		backend = &amqp.AMQP{}
 	default:
 		return backend, fmt.Errorf(
 			"backend from environment [%s=%s] - %w",
@@ -35,8 +105,34 @@ func Initialize(proc *processor.Processor) (Backend, error) {
 
 	// initialize the backend from the environment
 	if err := backend.Initialize(proc); err != nil {
-		return backend, fmt.Errorf("unable to initialize elasticsearch backend - %w", err)
+		return backend, fmt.Errorf("unable to initialize %s backend - %w", backend.String(), err)
+	}
+
+	// seed the deduplication state from the backend if it supports it
+	if lookup, ok := backend.(Lookup); ok {
+		seed(proc, backend, lookup)
 	}
 
 	return backend, nil
 }
+
+// seed marks the service logs which were recently stored by the backend as sent.  This is best
+// effort; if the backend cannot be queried, service logs are looked up or re-sent instead.
+func seed(proc *processor.Processor, backend Backend, lookup Lookup) {
+	ids, err := lookup.Recent(proc)
+	if err != nil {
+		proc.Log(
+			log.Warn().Err(err).Str("cluster", proc.Config.ClusterID).Str("backend", backend.String()),
+			"unable to seed sent service logs from backend",
+		)
+
+		return
+	}
+
+	lookup.MarkSent(ids...)
+
+	proc.Log(
+		log.Info().Str("cluster", proc.Config.ClusterID).Str("backend", backend.String()).Int("count", len(ids)),
+		"seeded sent service logs from backend",
+	)
+}
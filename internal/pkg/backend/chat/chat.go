@@ -0,0 +1,201 @@
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/format"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	chatRequestTimeout  = 10 * time.Second
+	chatResponseMaxRead = 512
+)
+
+var (
+	ErrChatResponse = errors.New("unexpected response from chat webhook")
+)
+
+// Chat is a backend which sends service logs as notifications to a chat platform
+// via an incoming webhook.  If a formatter is set, the notification is the plain text of
+// the service log in the format of the formatter rather than a card.
+type Chat struct {
+	Platform     string
+	WebhookURL   string
+	ConsoleURL   string
+	Formatter    format.Formatter
+	Threshold    v1.Severity
+	Limiter      *rate.Limiter
+	Client       *http.Client
+	SentMessages []string
+}
+
+func (chat *Chat) Initialize(proc *processor.Processor) (err error) {
+	chat.WebhookURL, err = config.GetChatWebhookURL(proc.KubeClient, proc.Context)
+	if err != nil {
+		return fmt.Errorf("unable to configure chat webhook - %w", err)
+	}
+
+	chat.Threshold, err = config.GetChatSeverityThreshold()
+	if err != nil {
+		return fmt.Errorf("unable to configure chat severity threshold - %w", err)
+	}
+
+	limit, err := config.GetChatRateLimit()
+	if err != nil {
+		return fmt.Errorf("unable to configure chat rate limit - %w", err)
+	}
+
+	chat.ConsoleURL = config.GetChatConsoleURL()
+
+	chatFormat, err := config.GetChatFormat()
+	if err != nil {
+		return fmt.Errorf("unable to configure chat format - %w", err)
+	}
+
+	if chatFormat != config.DefaultBackendChatFormatCard {
+		chat.Formatter, err = format.NewFormatter(chatFormat)
+		if err != nil {
+			return fmt.Errorf("unable to configure chat format - %w", err)
+		}
+	}
+
+	chat.Limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(limit)), limit)
+	chat.Client = &http.Client{Timeout: chatRequestTimeout}
+
+	return nil
+}
+
+func (chat *Chat) Send(proc *processor.Processor, response *poller.Response) error {
+	var deferred int
+
+	// send the oldest messages first so that the channel reads chronologically
+	logs := make([]*v1.LogEntry, len(response.Logs))
+	copy(logs, response.Logs)
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Timestamp().Before(logs[j].Timestamp())
+	})
+
+	for _, logEntry := range logs {
+		if chat.HasSent(logEntry) || !chat.AboveThreshold(logEntry) {
+			continue
+		}
+
+		// do not flood the channel; anything over the rate limit is left
+		// unsent and retried on the next poll
+		if !chat.Limiter.Allow() {
+			deferred++
+
+			continue
+		}
+
+		if err := chat.send(proc, logEntry); err != nil {
+			chat.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to send chat message")
+
+			continue
+		}
+	}
+
+	if deferred > 0 {
+		chat.Log(
+			log.Warn().Str("cluster", proc.Config.ClusterID).Int("deferred_count", deferred),
+			"rate limit reached; deferring remaining chat messages to next poll",
+		)
+	}
+
+	return nil
+}
+
+func (chat *Chat) String() string {
+	return chat.Platform
+}
+
+func (chat *Chat) HasSent(message *v1.LogEntry) bool {
+	for i := range chat.SentMessages {
+		if message.ID() == chat.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AboveThreshold determines if a message is at or above the configured severity
+// threshold for notification.
+func (chat *Chat) AboveThreshold(message *v1.LogEntry) bool {
+	return utils.SeverityAtLeast(message.Severity(), chat.Threshold)
+}
+
+func (chat *Chat) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", chat.String())).Msg(message)
+}
+
+func (chat *Chat) send(proc *processor.Processor, logEntry *v1.LogEntry) error {
+	payload, err := chat.payload(logEntry)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("unable to marshal chat payload - %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(proc.Context, http.MethodPost, chat.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to create chat request - %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := chat.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("unable to send chat request - %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, chatResponseMaxRead))
+
+		return fmt.Errorf("status [%d] with body [%s] - %w", response.StatusCode, string(responseBody), ErrChatResponse)
+	}
+
+	chat.Log(log.Info().Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "sent chat message")
+
+	// add the message to the list of sent messages
+	chat.SentMessages = append(chat.SentMessages, logEntry.ID())
+
+	return nil
+}
+
+// payload returns the webhook payload of a service log, which is the plain text rendered by the
+// formatter if one is set or a card otherwise.
+func (chat *Chat) payload(logEntry *v1.LogEntry) (interface{}, error) {
+	message := buildMessage(logEntry, chat.ConsoleURL)
+
+	if chat.Formatter == nil {
+		return message.payload(chat.Platform)
+	}
+
+	line, err := chat.Formatter.Format(logEntry)
+	if err != nil {
+		return nil, fmt.Errorf("unable to format service log - %w", err)
+	}
+
+	return message.textPayload(chat.Platform, string(line))
+}
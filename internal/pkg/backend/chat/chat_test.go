@@ -0,0 +1,458 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"golang.org/x/time/rate"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/format"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// webhookStandIn is a local stand-in for a chat webhook, which records the summaries of the
+// notifications that it accepts.  The first failures requests are rejected.
+type webhookStandIn struct {
+	summaries []string
+	failures  int
+
+	mutex     sync.Mutex
+	requests  int
+	delivered []string
+}
+
+func (standIn *webhookStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	standIn.requests++
+
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if standIn.requests <= standIn.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("internal_error"))
+
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	for _, summary := range standIn.summaries {
+		if strings.Contains(string(body), summary) {
+			standIn.delivered = append(standIn.delivered, summary)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// delivery returns the number of requests and the summaries of the accepted notifications in
+// the order that they were received.
+func (standIn *webhookStandIn) delivery() (int, []string) {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	return standIn.requests, append([]string{}, standIn.delivered...)
+}
+
+func testChatResponse(t *testing.T, ids ...string) *poller.Response {
+	t.Helper()
+
+	response := &poller.Response{}
+	start := time.Date(2023, 4, 5, 0, 0, 0, 0, time.UTC)
+
+	for _, id := range ids {
+		// ids are ordered by their timestamp, and an id starting with 'i' has a severity of info
+		severity := v1.SeverityWarning
+		if strings.HasPrefix(id, "i") {
+			severity = v1.SeverityInfo
+		}
+
+		logEntry, err := v1.NewLogEntry().
+			ID(id).
+			ClusterID("cluster").
+			Severity(severity).
+			Summary("summary-" + id).
+			Timestamp(start.Add(time.Duration(id[len(id)-1]-'0') * time.Minute)).
+			Build()
+		if err != nil {
+			t.Fatalf("unable to build log entry - %v", err)
+		}
+
+		response.Logs = append(response.Logs, logEntry)
+	}
+
+	return response
+}
+
+func TestChat_AboveThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		threshold v1.Severity
+		severity  v1.Severity
+		want      bool
+	}{
+		{
+			name:      "ensure severity below threshold returns false",
+			threshold: v1.SeverityWarning,
+			severity:  v1.SeverityInfo,
+			want:      false,
+		},
+		{
+			name:      "ensure severity equal to threshold returns true",
+			threshold: v1.SeverityWarning,
+			severity:  v1.SeverityWarning,
+			want:      true,
+		},
+		{
+			name:      "ensure critical severity above threshold returns true",
+			threshold: v1.SeverityError,
+			severity:  utils.SeverityCritical,
+			want:      true,
+		},
+		{
+			name:      "ensure unknown severity returns false",
+			threshold: v1.SeverityDebug,
+			severity:  v1.Severity("Unknown"),
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().ID("1").Severity(tt.severity).Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			chat := &Chat{Threshold: tt.threshold}
+			if got := chat.AboveThreshold(logEntry); got != tt.want {
+				t.Errorf("Chat.AboveThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buildMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		subscriptionID string
+		description    string
+		wantConsoleURL string
+		wantReferences []string
+	}{
+		{
+			name:           "ensure subscription is used for console link",
+			subscriptionID: "subscription",
+			description:    "no references here",
+			wantConsoleURL: "https://console.redhat.com/openshift/details/s/subscription",
+			wantReferences: []string{},
+		},
+		{
+			name:           "ensure external id is used for console link without subscription",
+			description:    "see https://docs.openshift.com/rosa/upgrading.html. and (https://access.redhat.com/articles/1).",
+			wantConsoleURL: "https://console.redhat.com/openshift/details/external",
+			wantReferences: []string{"https://docs.openshift.com/rosa/upgrading.html", "https://access.redhat.com/articles/1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().
+				ClusterUUID("external").
+				SubscriptionID(tt.subscriptionID).
+				Description(tt.description).
+				Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			message := buildMessage(logEntry, "https://console.redhat.com/openshift/details/")
+			if message.ConsoleURL != tt.wantConsoleURL {
+				t.Errorf("buildMessage() console url = %v, want %v", message.ConsoleURL, tt.wantConsoleURL)
+			}
+
+			if len(message.References) != len(tt.wantReferences) {
+				t.Fatalf("buildMessage() references = %v, want %v", message.References, tt.wantReferences)
+			}
+
+			for i := range tt.wantReferences {
+				if message.References[i] != tt.wantReferences[i] {
+					t.Errorf("buildMessage() reference = %v, want %v", message.References[i], tt.wantReferences[i])
+				}
+			}
+		})
+	}
+}
+
+func TestChat_payload(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		platform    string
+		formatter   format.Formatter
+		want        []string
+		wantMissing []string
+	}{
+		{
+			name:        "ensure a card is sent without a formatter",
+			platform:    config.DefaultBackendSlack,
+			want:        []string{`"attachments":[`, `"text":"[Warning] \u0026lt;!channel\u0026gt; upgrade"`},
+			wantMissing: []string{`"mrkdwn":false`},
+		},
+		{
+			name:        "ensure slack receives the escaped plain text of the formatter",
+			platform:    config.DefaultBackendSlack,
+			formatter:   &format.CEF{},
+			want:        []string{`"text":"CEF:0|Red Hat|OpenShift Cluster Manager|`, `\u0026lt;!channel\u0026gt;`, `"mrkdwn":false`},
+			wantMissing: []string{`"attachments"`, `\u003c!channel\u003e`},
+		},
+		{
+			name:        "ensure teams receives the plain text of the formatter",
+			platform:    config.DefaultBackendTeams,
+			formatter:   &format.JSON{},
+			want:        []string{`"summary":"[Warning] \u003c!channel\u003e upgrade"`, `"markdown":false`, `\"id\":\"id\"`},
+			wantMissing: []string{`"facts"`, `"potentialAction"`},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().
+				ID("id").
+				Severity(v1.SeverityWarning).
+				Summary("<!channel> upgrade").
+				Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			chat := &Chat{Platform: tt.platform, Formatter: tt.formatter}
+
+			payload, err := chat.payload(logEntry)
+			if err != nil {
+				t.Fatalf("Chat.payload() error = %v", err)
+			}
+
+			body, err := json.Marshal(payload)
+			if err != nil {
+				t.Fatalf("unable to marshal payload - %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("Chat.payload() = %s, want %s", body, want)
+				}
+			}
+
+			for _, missing := range tt.wantMissing {
+				if strings.Contains(string(body), missing) {
+					t.Errorf("Chat.payload() = %s, want no %s", body, missing)
+				}
+			}
+		})
+	}
+}
+
+func TestChatMessage_slackPayload(t *testing.T) {
+	t.Parallel()
+
+	message := &ChatMessage{
+		Severity:    "Warning",
+		Summary:     "<!channel> upgrade",
+		Description: "see <https://example.com|docs> & more",
+		References:  []string{"https://example.com/?a=1&b=2"},
+	}
+
+	payload := message.slackPayload()
+
+	if want := "[Warning] &lt;!channel&gt; upgrade"; payload.Text != want {
+		t.Errorf("ChatMessage.slackPayload() text = %v, want %v", payload.Text, want)
+	}
+
+	blocks := payload.Attachments[0].Blocks
+
+	if want := "see &lt;https://example.com|docs&gt; &amp; more"; blocks[2].Text.Text != want {
+		t.Errorf("ChatMessage.slackPayload() description = %v, want %v", blocks[2].Text.Text, want)
+	}
+
+	if want := "https://example.com/?a=1&amp;b=2"; blocks[4].Elements[0].(slackText).Text != want {
+		t.Errorf("ChatMessage.slackPayload() reference = %v, want %v", blocks[4].Elements[0], want)
+	}
+}
+
+func Test_slackEscapeTruncate(t *testing.T) {
+	t.Parallel()
+
+	got := slackEscapeTruncate("a&b<c>d", 10)
+	if want := "a&amp;b…"; got != want {
+		t.Errorf("slackEscapeTruncate() = %v, want %v", got, want)
+	}
+}
+
+func TestChat_Send(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		limit         rate.Limit
+		burst         int
+		failures      int
+		ids           []string
+		wantRequests  int
+		wantDelivered []string
+		wantSent      []string
+	}{
+		{
+			name:          "ensure service logs are sent oldest first above the severity threshold",
+			limit:         rate.Inf,
+			ids:           []string{"w3", "i1", "w2", "w1"},
+			wantRequests:  3,
+			wantDelivered: []string{"summary-w1", "summary-w2", "summary-w3"},
+			wantSent:      []string{"w1", "w2", "w3"},
+		},
+		{
+			name:          "ensure service logs over the rate limit are deferred",
+			limit:         rate.Every(time.Hour),
+			burst:         2,
+			ids:           []string{"w3", "w2", "w1"},
+			wantRequests:  2,
+			wantDelivered: []string{"summary-w1", "summary-w2"},
+			wantSent:      []string{"w1", "w2"},
+		},
+		{
+			name:          "ensure service logs rejected by the webhook are not marked as sent",
+			limit:         rate.Inf,
+			failures:      1,
+			ids:           []string{"w2", "w1"},
+			wantRequests:  2,
+			wantDelivered: []string{"summary-w2"},
+			wantSent:      []string{"w2"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			response := testChatResponse(t, tt.ids...)
+
+			standIn := &webhookStandIn{failures: tt.failures}
+			for _, logEntry := range response.Logs {
+				standIn.summaries = append(standIn.summaries, logEntry.Summary())
+			}
+
+			server := httptest.NewServer(standIn)
+			t.Cleanup(server.Close)
+
+			chat := &Chat{
+				Platform:   config.DefaultBackendSlack,
+				WebhookURL: server.URL,
+				Threshold:  v1.SeverityWarning,
+				Limiter:    rate.NewLimiter(tt.limit, tt.burst),
+				Client:     server.Client(),
+			}
+			proc := &processor.Processor{Config: &config.Config{ClusterID: "cluster"}, Context: context.TODO()}
+
+			if err := chat.Send(proc, response); err != nil {
+				t.Fatalf("Chat.Send() error = %v", err)
+			}
+
+			requests, delivered := standIn.delivery()
+			if requests != tt.wantRequests {
+				t.Errorf("Chat.Send() requests = %d, want %d", requests, tt.wantRequests)
+			}
+
+			if got := strings.Join(delivered, ","); got != strings.Join(tt.wantDelivered, ",") {
+				t.Errorf("Chat.Send() delivered = %v, want %v", got, tt.wantDelivered)
+			}
+
+			if got := strings.Join(chat.SentMessages, ","); got != strings.Join(tt.wantSent, ",") {
+				t.Errorf("Chat.Send() sent = %v, want %v", got, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestChat_Send_deferred(t *testing.T) {
+	t.Parallel()
+
+	response := testChatResponse(t, "w3", "w2", "w1")
+
+	standIn := &webhookStandIn{failures: 1}
+	for _, logEntry := range response.Logs {
+		standIn.summaries = append(standIn.summaries, logEntry.Summary())
+	}
+
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	// a single message is allowed per poll, as the tokens are replenished between polls
+	interval := 100 * time.Millisecond
+
+	chat := &Chat{
+		Platform:   config.DefaultBackendSlack,
+		WebhookURL: server.URL,
+		Threshold:  v1.SeverityWarning,
+		Limiter:    rate.NewLimiter(rate.Every(interval), 1),
+		Client:     server.Client(),
+	}
+	proc := &processor.Processor{Config: &config.Config{ClusterID: "cluster"}, Context: context.TODO()}
+
+	// the first poll is rejected by the webhook, and the oldest message is retried on each poll
+	// until it is delivered, before the newer messages which were deferred
+	for poll := 0; poll < 4; poll++ {
+		if poll > 0 {
+			time.Sleep(interval + interval/2)
+		}
+
+		if err := chat.Send(proc, response); err != nil {
+			t.Fatalf("Chat.Send() error = %v", err)
+		}
+	}
+
+	requests, delivered := standIn.delivery()
+	if requests != 4 {
+		t.Errorf("Chat.Send() requests = %d, want %d", requests, 4)
+	}
+
+	if got := strings.Join(delivered, ","); got != "summary-w1,summary-w2,summary-w3" {
+		t.Errorf("Chat.Send() delivered = %v, want %v", got, "summary-w1,summary-w2,summary-w3")
+	}
+
+	if got := strings.Join(chat.SentMessages, ","); got != "w1,w2,w3" {
+		t.Errorf("Chat.Send() sent = %v, want %v", got, "w1,w2,w3")
+	}
+}
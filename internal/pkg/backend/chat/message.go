@@ -0,0 +1,325 @@
+package chat
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	slackHeaderMaxLength  = 150
+	slackSectionMaxLength = 3000
+	slackEllipsis         = "…"
+)
+
+var referencePattern = regexp.MustCompile(`https?://[^\s<>()\[\]"']+`)
+
+// ChatMessage represents the platform independent contents of a chat notification
+// built from a service log message.
+type ChatMessage struct {
+	ID          string
+	ClusterID   string
+	ExternalID  string
+	Severity    string
+	ServiceName string
+	Summary     string
+	Description string
+	Timestamp   time.Time
+	ConsoleURL  string
+	References  []string
+}
+
+// buildMessage builds a chat message from a service log message.
+func buildMessage(logEntry *v1.LogEntry, consoleURL string) *ChatMessage {
+	return &ChatMessage{
+		ID:          logEntry.ID(),
+		ClusterID:   logEntry.ClusterID(),
+		ExternalID:  logEntry.ClusterUUID(),
+		Severity:    string(logEntry.Severity()),
+		ServiceName: logEntry.ServiceName(),
+		Summary:     logEntry.Summary(),
+		Description: logEntry.Description(),
+		Timestamp:   logEntry.Timestamp(),
+		ConsoleURL:  consoleLink(consoleURL, logEntry),
+		References:  references(logEntry.Description()),
+	}
+}
+
+// consoleLink returns the link to the cluster in the OpenShift Cluster Manager console.  The
+// subscription is preferred as it is what the console uses to address a cluster.
+func consoleLink(consoleURL string, logEntry *v1.LogEntry) string {
+	consoleURL = strings.TrimSuffix(consoleURL, "/")
+
+	if logEntry.SubscriptionID() != "" {
+		return fmt.Sprintf("%s/s/%s", consoleURL, logEntry.SubscriptionID())
+	}
+
+	return fmt.Sprintf("%s/%s", consoleURL, logEntry.ClusterUUID())
+}
+
+// references returns the links found in a service log description, which generally
+// point to documentation relevant to the service log.
+func references(description string) []string {
+	links := referencePattern.FindAllString(description, -1)
+
+	for i := range links {
+		links[i] = strings.TrimRight(links[i], ".,;:")
+	}
+
+	return links
+}
+
+// color returns the hex color code which represents the severity of the message.
+func (message *ChatMessage) color() string {
+	switch utils.SeverityLevel(v1.Severity(message.Severity)) {
+	case utils.SeverityLevelCritical:
+		return "8B0000"
+	case utils.SeverityLevelError:
+		return "D32F2F"
+	case utils.SeverityLevelWarning:
+		return "F9A825"
+	default:
+		return "1976D2"
+	}
+}
+
+// title returns the title of the message.
+func (message *ChatMessage) title() string {
+	return fmt.Sprintf("[%s] %s", message.Severity, message.Summary)
+}
+
+//
+// slack
+//
+
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Markdown    *bool             `json:"mrkdwn,omitempty"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string        `json:"type"`
+	Text     *slackText    `json:"text,omitempty"`
+	Fields   []slackText   `json:"fields,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+type slackButton struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+	URL  string    `json:"url"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackPayload renders the message as a slack incoming webhook payload.
+func (message *ChatMessage) slackPayload() *slackPayload {
+	blocks := []slackBlock{
+		{
+			Type: "header",
+			Text: &slackText{Type: "plain_text", Text: utils.Truncate(message.title(), slackHeaderMaxLength, slackEllipsis)},
+		},
+		{
+			Type: "section",
+			Fields: []slackText{
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Cluster:*\n%s", slackEscape(message.ClusterID))},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*External ID:*\n%s", slackEscape(message.ExternalID))},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Severity:*\n%s", slackEscape(message.Severity))},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Service:*\n%s", slackEscape(message.ServiceName))},
+				{Type: "mrkdwn", Text: fmt.Sprintf("*Timestamp:*\n%s", message.Timestamp.UTC().Format(time.RFC3339))},
+			},
+		},
+	}
+
+	if message.Description != "" {
+		blocks = append(blocks, slackBlock{
+			Type: "section",
+			Text: &slackText{Type: "mrkdwn", Text: slackEscapeTruncate(message.Description, slackSectionMaxLength)},
+		})
+	}
+
+	blocks = append(blocks, slackBlock{
+		Type: "actions",
+		Elements: []interface{}{
+			slackButton{
+				Type: "button",
+				Text: slackText{Type: "plain_text", Text: "View in OpenShift Cluster Manager"},
+				URL:  message.ConsoleURL,
+			},
+		},
+	})
+
+	if len(message.References) > 0 {
+		references := make([]interface{}, len(message.References))
+		for i := range message.References {
+			references[i] = slackText{Type: "mrkdwn", Text: slackEscape(message.References[i])}
+		}
+
+		blocks = append(blocks, slackBlock{Type: "context", Elements: references})
+	}
+
+	return &slackPayload{
+		Text: slackEscape(message.title()),
+		Attachments: []slackAttachment{
+			{Color: "#" + message.color(), Blocks: blocks},
+		},
+	}
+}
+
+// slackEscape escapes the control characters of slack mrkdwn, so that service log text can not
+// create links or mentions, such as '<!channel>'.
+func slackEscape(str string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(str)
+}
+
+// slackEscapeTruncate escapes a string and truncates it, so that the escaped string satisfies the
+// slack limit without cutting through an escaped character.
+func slackEscapeTruncate(str string, length int) string {
+	escaped := slackEscape(str)
+	if len([]rune(escaped)) <= length {
+		return escaped
+	}
+
+	var (
+		truncated strings.Builder
+		count     int
+	)
+
+	// keep room for the ellipsis
+	for _, r := range str {
+		character := slackEscape(string(r))
+
+		if count += len([]rune(character)); count > length-1 {
+			break
+		}
+
+		truncated.WriteString(character)
+	}
+
+	return truncated.String() + slackEllipsis
+}
+
+//
+// teams
+//
+
+type teamsPayload struct {
+	Type            string         `json:"@type"`
+	Context         string         `json:"@context"`
+	ThemeColor      string         `json:"themeColor"`
+	Summary         string         `json:"summary"`
+	Title           string         `json:"title,omitempty"`
+	Sections        []teamsSection `json:"sections"`
+	PotentialAction []teamsAction  `json:"potentialAction,omitempty"`
+}
+
+type teamsSection struct {
+	Facts    []teamsFact `json:"facts,omitempty"`
+	Text     string      `json:"text,omitempty"`
+	Markdown bool        `json:"markdown"`
+}
+
+type teamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type teamsAction struct {
+	Type    string        `json:"@type"`
+	Name    string        `json:"name"`
+	Targets []teamsTarget `json:"targets"`
+}
+
+type teamsTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+// teamsPayload renders the message as a microsoft teams connector card payload.
+func (message *ChatMessage) teamsPayload() *teamsPayload {
+	actions := []teamsAction{
+		{
+			Type:    "OpenUri",
+			Name:    "View in OpenShift Cluster Manager",
+			Targets: []teamsTarget{{OS: "default", URI: message.ConsoleURL}},
+		},
+	}
+
+	for i := range message.References {
+		actions = append(actions, teamsAction{
+			Type:    "OpenUri",
+			Name:    fmt.Sprintf("Reference %d", i+1),
+			Targets: []teamsTarget{{OS: "default", URI: message.References[i]}},
+		})
+	}
+
+	return &teamsPayload{
+		Type:       "MessageCard",
+		Context:    "http://schema.org/extensions",
+		ThemeColor: message.color(),
+		Summary:    message.title(),
+		Title:      message.title(),
+		Sections: []teamsSection{
+			{
+				Facts: []teamsFact{
+					{Name: "Cluster", Value: message.ClusterID},
+					{Name: "External ID", Value: message.ExternalID},
+					{Name: "Severity", Value: message.Severity},
+					{Name: "Service", Value: message.ServiceName},
+					{Name: "Timestamp", Value: message.Timestamp.UTC().Format(time.RFC3339)},
+				},
+				Text:     message.Description,
+				Markdown: true,
+			},
+		},
+		PotentialAction: actions,
+	}
+}
+
+// payload returns the rendered message for the requested chat platform.
+func (message *ChatMessage) payload(platform string) (interface{}, error) {
+	switch platform {
+	case config.DefaultBackendSlack:
+		return message.slackPayload(), nil
+	case config.DefaultBackendTeams:
+		return message.teamsPayload(), nil
+	default:
+		return nil, fmt.Errorf("chat platform [%s] - %w", platform, config.ErrBackendUnknown)
+	}
+}
+
+// textPayload returns a payload for the requested chat platform which contains only the plain
+// text of a formatted service log.  Markdown is disabled so that the text is posted verbatim.
+func (message *ChatMessage) textPayload(platform, text string) (interface{}, error) {
+	markdown := false
+
+	switch platform {
+	case config.DefaultBackendSlack:
+		return &slackPayload{Text: slackEscape(text), Markdown: &markdown}, nil
+	case config.DefaultBackendTeams:
+		return &teamsPayload{
+			Type:       "MessageCard",
+			Context:    "http://schema.org/extensions",
+			ThemeColor: message.color(),
+			Summary:    message.title(),
+			Sections:   []teamsSection{{Text: text, Markdown: markdown}},
+		}, nil
+	default:
+		return nil, fmt.Errorf("chat platform [%s] - %w", platform, config.ErrBackendUnknown)
+	}
+}
@@ -0,0 +1,145 @@
+package cloudwatch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: see https://docs.aws.amazon.com/AmazonCloudWatchLogs/latest/APIReference/API_PutLogEvents.html
+// for the limits of a PutLogEvents request.
+const (
+	cloudWatchBatchMaxBytes   = 1048576
+	cloudWatchBatchMaxCount   = 10000
+	cloudWatchBatchMaxSpan    = 24 * time.Hour
+	cloudWatchEventOverhead   = 26
+	cloudWatchEventMaxMessage = 262144 - cloudWatchEventOverhead
+
+	eventFieldDescription = "description"
+	eventFieldSummary     = "summary"
+)
+
+var (
+	ErrCloudWatchEventTooLarge = errors.New("event exceeds the maximum size after shortening the description and summary")
+)
+
+// CloudWatchEvent represents a single log event in a PutLogEvents request.
+type CloudWatchEvent struct {
+	id        string
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+// buildEvent builds a cloudwatch log event from a service log message.  The message is the
+// raw service log as json.  A message over the event size limit has its description, and then
+// its summary, shortened so that the message remains valid json.
+func buildEvent(logEntry *v1.LogEntry) (*CloudWatchEvent, error) {
+	message, err := utils.MarshalLogEntry(logEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range []string{eventFieldDescription, eventFieldSummary} {
+		if len(message) <= cloudWatchEventMaxMessage {
+			break
+		}
+
+		shortened, err := shortenField(logEntry, field, len(message)-cloudWatchEventMaxMessage)
+		if err != nil {
+			return nil, err
+		}
+
+		if message, err = utils.MarshalLogEntry(shortened); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(message) > cloudWatchEventMaxMessage {
+		return nil, fmt.Errorf("event of [%d] bytes - %w", len(message), ErrCloudWatchEventTooLarge)
+	}
+
+	return &CloudWatchEvent{
+		id:        logEntry.ID(),
+		Timestamp: logEntry.Timestamp().UnixMilli(),
+		Message:   string(message),
+	}, nil
+}
+
+// shortenField returns a copy of a service log with a field shortened by a number of bytes.  As
+// escaping only lengthens a string in json, the json of the copy is shortened by at least as many
+// bytes.  The field is cut between characters so that it remains valid UTF-8.
+func shortenField(logEntry *v1.LogEntry, field string, overflow int) (*v1.LogEntry, error) {
+	shorten := func(value string) string {
+		if overflow >= len(value) {
+			return ""
+		}
+
+		return strings.ToValidUTF8(value[:len(value)-overflow], "")
+	}
+
+	builder := v1.NewLogEntry().Copy(logEntry)
+
+	switch field {
+	case eventFieldDescription:
+		builder.Description(shorten(logEntry.Description()))
+	case eventFieldSummary:
+		builder.Summary(shorten(logEntry.Summary()))
+	}
+
+	shortened, err := builder.Build()
+	if err != nil {
+		return nil, fmt.Errorf("unable to shorten service log [%s] - %w", field, err)
+	}
+
+	return shortened, nil
+}
+
+// size returns the size of the event as counted against the batch size limit.
+func (event *CloudWatchEvent) size() int {
+	return len(event.Message) + cloudWatchEventOverhead
+}
+
+// buildBatches sorts events chronologically and splits them into batches that satisfy
+// the byte size, event count and time span limits of a single PutLogEvents request.
+func buildBatches(events []*CloudWatchEvent) [][]*CloudWatchEvent {
+	sorted := make([]*CloudWatchEvent, len(events))
+	copy(sorted, events)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp < sorted[j].Timestamp
+	})
+
+	batches := [][]*CloudWatchEvent{}
+
+	var batch []*CloudWatchEvent
+
+	var batchBytes int
+
+	for _, event := range sorted {
+		if len(batch) > 0 {
+			span := time.Duration(event.Timestamp-batch[0].Timestamp) * time.Millisecond
+
+			if len(batch) >= cloudWatchBatchMaxCount ||
+				batchBytes+event.size() > cloudWatchBatchMaxBytes ||
+				span > cloudWatchBatchMaxSpan {
+				batches = append(batches, batch)
+				batch, batchBytes = nil, 0
+			}
+		}
+
+		batch = append(batch, event)
+		batchBytes += event.size()
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
@@ -0,0 +1,83 @@
+package cloudwatch
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_buildBatches(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, 4, 5, 0, 0, 0, 0, time.UTC)
+
+	events := func(count int, interval time.Duration, messageSize int) []*CloudWatchEvent {
+		built := make([]*CloudWatchEvent, count)
+
+		// build in reverse order to ensure batches are sorted chronologically
+		for i := range built {
+			built[count-1-i] = &CloudWatchEvent{
+				Timestamp: start.Add(time.Duration(i) * interval).UnixMilli(),
+				Message:   strings.Repeat("a", messageSize),
+			}
+		}
+
+		return built
+	}
+
+	tests := []struct {
+		name        string
+		events      []*CloudWatchEvent
+		wantBatches []int
+	}{
+		{
+			name:        "ensure no events returns no batches",
+			events:      []*CloudWatchEvent{},
+			wantBatches: []int{},
+		},
+		{
+			name:        "ensure small number of events returns a single batch",
+			events:      events(5, time.Minute, 100),
+			wantBatches: []int{5},
+		},
+		{
+			name:        "ensure event count limit splits batches",
+			events:      events(cloudWatchBatchMaxCount+1, time.Millisecond, 1),
+			wantBatches: []int{cloudWatchBatchMaxCount, 1},
+		},
+		{
+			name:        "ensure byte size limit splits batches",
+			events:      events(5, time.Minute, 300000-cloudWatchEventOverhead),
+			wantBatches: []int{3, 2},
+		},
+		{
+			name:        "ensure time span limit splits batches",
+			events:      events(4, 10*time.Hour, 100),
+			wantBatches: []int{3, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := buildBatches(tt.events)
+			if len(got) != len(tt.wantBatches) {
+				t.Fatalf("buildBatches() batch count = %v, want %v", len(got), len(tt.wantBatches))
+			}
+
+			for i := range got {
+				if len(got[i]) != tt.wantBatches[i] {
+					t.Errorf("buildBatches() batch [%d] size = %v, want %v", i, len(got[i]), tt.wantBatches[i])
+				}
+
+				for j := 1; j < len(got[i]); j++ {
+					if got[i][j].Timestamp < got[i][j-1].Timestamp {
+						t.Errorf("buildBatches() batch [%d] is not in chronological order", i)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package cloudwatch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+const (
+	cloudWatchService        = "logs"
+	cloudWatchTargetPrefix   = "Logs_20140328"
+	cloudWatchContentType    = "application/x-amz-json-1.1"
+	cloudWatchRequestTimeout = 30 * time.Second
+
+	cloudWatchActionCreateLogGroup  = "CreateLogGroup"
+	cloudWatchActionCreateLogStream = "CreateLogStream"
+	cloudWatchActionPutLogEvents    = "PutLogEvents"
+
+	cloudWatchErrorResourceAlreadyExists = "ResourceAlreadyExistsException"
+	cloudWatchErrorResourceNotFound      = "ResourceNotFoundException"
+)
+
+var (
+	ErrCloudWatchResponse = errors.New("unexpected response from cloudwatch logs api")
+)
+
+// CloudWatchError represents an error returned by the cloudwatch logs api.
+type CloudWatchError struct {
+	Type       string `json:"__type"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"-"`
+}
+
+func (cwErr *CloudWatchError) Error() string {
+	return fmt.Sprintf("status [%d] type [%s] message [%s]", cwErr.StatusCode, cwErr.Code(), cwErr.Message)
+}
+
+func (cwErr *CloudWatchError) Unwrap() error {
+	return ErrCloudWatchResponse
+}
+
+// Code returns the error code without the namespace that the api prefixes it with.
+func (cwErr *CloudWatchError) Code() string {
+	if index := strings.LastIndex(cwErr.Type, "#"); index >= 0 {
+		return cwErr.Type[index+1:]
+	}
+
+	return cwErr.Type
+}
+
+// isCloudWatchError determines if an error is a cloudwatch api error with a specific code.
+func isCloudWatchError(err error, code string) bool {
+	var cwErr *CloudWatchError
+	if errors.As(err, &cwErr) {
+		return cwErr.Code() == code
+	}
+
+	return false
+}
+
+// CloudWatchClient is a minimal client for the cloudwatch logs json api which signs
+// requests with aws signature version 4.
+type CloudWatchClient struct {
+	Endpoint string
+	Region   string
+	AWS      aws.Config
+	HTTP     *http.Client
+	Signer   *v4.Signer
+}
+
+// NewCloudWatchClient returns a new cloudwatch logs client.
+func NewCloudWatchClient(awsConfig aws.Config, endpoint, region string) *CloudWatchClient {
+	return &CloudWatchClient{
+		Endpoint: endpoint,
+		Region:   region,
+		AWS:      awsConfig,
+		HTTP:     &http.Client{Timeout: cloudWatchRequestTimeout},
+		Signer:   v4.NewSigner(),
+	}
+}
+
+// Do performs a signed cloudwatch logs api action and decodes the response into output.
+func (client *CloudWatchClient) Do(ctx context.Context, action string, input, output interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("unable to marshal [%s] request - %w", action, err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, client.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to create [%s] request - %w", action, err)
+	}
+
+	request.Header.Set("Content-Type", cloudWatchContentType)
+	request.Header.Set("X-Amz-Target", fmt.Sprintf("%s.%s", cloudWatchTargetPrefix, action))
+
+	credentials, err := client.AWS.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve aws credentials - %w", err)
+	}
+
+	payloadHash := sha256.Sum256(body)
+	if err := client.Signer.SignHTTP(
+		ctx,
+		credentials,
+		request,
+		hex.EncodeToString(payloadHash[:]),
+		cloudWatchService,
+		client.Region,
+		time.Now(),
+	); err != nil {
+		return fmt.Errorf("unable to sign [%s] request - %w", action, err)
+	}
+
+	response, err := client.HTTP.Do(request)
+	if err != nil {
+		return fmt.Errorf("unable to send [%s] request - %w", action, err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read [%s] response - %w", action, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		cwErr := &CloudWatchError{StatusCode: response.StatusCode}
+		if jsonErr := json.Unmarshal(responseBody, cwErr); jsonErr != nil {
+			cwErr.Message = string(responseBody)
+		}
+
+		return fmt.Errorf("error in [%s] request - %w", action, cwErr)
+	}
+
+	if output == nil || len(responseBody) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(responseBody, output); err != nil {
+		return fmt.Errorf("unable to unmarshal [%s] response - %w", action, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package cloudwatch
+
+import (
+	"fmt"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	rejectedReasonTooNew  = "too_new"
+	rejectedReasonTooOld  = "too_old"
+	rejectedReasonExpired = "expired"
+)
+
+// CloudWatch is a backend which sends service logs to an aws cloudwatch logs log stream.
+type CloudWatch struct {
+	Client       *CloudWatchClient
+	Config       *config.CloudWatchConfig
+	Ready        bool
+	SentMessages []string
+}
+
+type putLogEventsInput struct {
+	LogGroupName  string             `json:"logGroupName"`
+	LogStreamName string             `json:"logStreamName"`
+	LogEvents     []*CloudWatchEvent `json:"logEvents"`
+}
+
+type putLogEventsOutput struct {
+	RejectedLogEventsInfo *rejectedLogEventsInfo `json:"rejectedLogEventsInfo"`
+}
+
+type rejectedLogEventsInfo struct {
+	TooNewLogEventStartIndex *int `json:"tooNewLogEventStartIndex"`
+	TooOldLogEventEndIndex   *int `json:"tooOldLogEventEndIndex"`
+	ExpiredLogEventEndIndex  *int `json:"expiredLogEventEndIndex"`
+}
+
+func (cw *CloudWatch) Initialize(proc *processor.Processor) (err error) {
+	var accessKeyID, secretAccessKey string
+
+	cw.Config, err = config.GetCloudWatchConfig(proc.Config.ClusterID)
+	if err != nil {
+		return fmt.Errorf("unable to configure cloudwatch backend - %w", err)
+	}
+
+	// retrieve static credentials if requested, otherwise we fall back to the default
+	// credential chain which includes irsa
+	if cw.Config.AuthType == config.DefaultBackendAuthTypeStatic {
+		accessKeyID, secretAccessKey, err = config.GetCloudWatchStaticCredentials(proc.KubeClient, proc.Context)
+		if err != nil {
+			return fmt.Errorf("unable to configure static auth type - %w", err)
+		}
+	}
+
+	awsConfig, err := utils.GetAWSConfig(proc.Context, cw.Config.Region, accessKeyID, secretAccessKey)
+	if err != nil {
+		return fmt.Errorf("unable to configure aws - %w", err)
+	}
+
+	cw.Client = NewCloudWatchClient(awsConfig, cw.Config.Endpoint, cw.Config.Region)
+
+	return cw.ensureLogStream(proc)
+}
+
+func (cw *CloudWatch) Send(proc *processor.Processor, response *poller.Response) error {
+	// the log group or stream may have been removed out from under us, in which
+	// case we attempt to recreate it before sending
+	if !cw.Ready {
+		if err := cw.ensureLogStream(proc); err != nil {
+			cw.Log(log.Err(err).Str("cluster", proc.Config.ClusterID), "failed to ensure cloudwatch log stream")
+
+			return nil
+		}
+	}
+
+	events := []*CloudWatchEvent{}
+
+	for _, logEntry := range response.Logs {
+		if cw.HasSent(logEntry) {
+			continue
+		}
+
+		event, err := buildEvent(logEntry)
+		if err != nil {
+			cw.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to build cloudwatch event")
+
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	// we want to do this serially as the events within a log stream must be sent
+	// in chronological order
+	for batchCount, batch := range buildBatches(events) {
+		if err := cw.put(proc, batch); err != nil {
+			cw.Log(log.Err(err).Str("cluster", proc.Config.ClusterID), fmt.Sprintf("batch number [%d] failed to send", batchCount))
+
+			if isCloudWatchError(err, cloudWatchErrorResourceNotFound) {
+				cw.Ready = false
+
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+func (cw *CloudWatch) String() string {
+	return config.DefaultBackendCloudWatch
+}
+
+func (cw *CloudWatch) HasSent(message *v1.LogEntry) bool {
+	for i := range cw.SentMessages {
+		if message.ID() == cw.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cw *CloudWatch) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", cw.String())).Msg(message)
+}
+
+// ensureLogStream creates the log group and log stream if they do not already exist.
+func (cw *CloudWatch) ensureLogStream(proc *processor.Processor) error {
+	err := cw.Client.Do(proc.Context, cloudWatchActionCreateLogGroup, map[string]string{
+		"logGroupName": cw.Config.LogGroup,
+	}, nil)
+	if err != nil && !isCloudWatchError(err, cloudWatchErrorResourceAlreadyExists) {
+		return fmt.Errorf("unable to create log group [%s] - %w", cw.Config.LogGroup, err)
+	}
+
+	err = cw.Client.Do(proc.Context, cloudWatchActionCreateLogStream, map[string]string{
+		"logGroupName":  cw.Config.LogGroup,
+		"logStreamName": cw.Config.LogStream,
+	}, nil)
+	if err != nil && !isCloudWatchError(err, cloudWatchErrorResourceAlreadyExists) {
+		return fmt.Errorf("unable to create log stream [%s/%s] - %w", cw.Config.LogGroup, cw.Config.LogStream, err)
+	}
+
+	cw.Log(
+		log.Info().Str("cluster", proc.Config.ClusterID).Str("log_group", cw.Config.LogGroup).Str("log_stream", cw.Config.LogStream),
+		"using cloudwatch log stream",
+	)
+
+	cw.Ready = true
+
+	return nil
+}
+
+// put sends a single batch of events to the log stream.
+func (cw *CloudWatch) put(proc *processor.Processor, batch []*CloudWatchEvent) error {
+	output := &putLogEventsOutput{}
+
+	cw.Log(
+		log.Info().Str("cluster", proc.Config.ClusterID).Str("log_group", cw.Config.LogGroup).Int("document_count", len(batch)),
+		"sending events to cloudwatch",
+	)
+
+	if err := cw.Client.Do(proc.Context, cloudWatchActionPutLogEvents, &putLogEventsInput{
+		LogGroupName:  cw.Config.LogGroup,
+		LogStreamName: cw.Config.LogStream,
+		LogEvents:     batch,
+	}, output); err != nil {
+		return err
+	}
+
+	for index, event := range batch {
+		switch output.RejectedLogEventsInfo.reason(index) {
+		case "":
+			cw.SentMessages = append(cw.SentMessages, event.id)
+		case rejectedReasonTooNew:
+			// events in the future are left unsent and retried on the next poll
+			cw.Log(log.Warn().Str("message_id", event.id), "cloudwatch rejected event as too new")
+		default:
+			// events that are too old will never be accepted, so we do not retry them
+			cw.Log(log.Warn().Str("message_id", event.id), "cloudwatch rejected event as too old or expired")
+			cw.SentMessages = append(cw.SentMessages, event.id)
+		}
+	}
+
+	return nil
+}
+
+// reason returns the reason that an event at a given index in a batch was rejected, or an
+// empty string if the event was accepted.
+func (info *rejectedLogEventsInfo) reason(index int) string {
+	switch {
+	case info == nil:
+		return ""
+	case info.TooNewLogEventStartIndex != nil && index >= *info.TooNewLogEventStartIndex:
+		return rejectedReasonTooNew
+	case info.TooOldLogEventEndIndex != nil && index <= *info.TooOldLogEventEndIndex:
+		return rejectedReasonTooOld
+	case info.ExpiredLogEventEndIndex != nil && index <= *info.ExpiredLogEventEndIndex:
+		return rejectedReasonExpired
+	default:
+		return ""
+	}
+}
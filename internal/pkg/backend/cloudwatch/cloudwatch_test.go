@@ -0,0 +1,292 @@
+package cloudwatch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+// cloudWatchStandIn is a local stand-in for the cloudwatch logs api, which responds to each action
+// with a fixed status and body, and records the actions that it receives.
+type cloudWatchStandIn struct {
+	responses map[string]cloudWatchResponse
+
+	mutex   sync.Mutex
+	actions []string
+	events  []*CloudWatchEvent
+}
+
+type cloudWatchResponse struct {
+	status int
+	body   string
+}
+
+func (standIn *cloudWatchStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), cloudWatchTargetPrefix+".")
+	standIn.actions = append(standIn.actions, action)
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+
+	if action == cloudWatchActionPutLogEvents {
+		input := &putLogEventsInput{}
+
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, input); err == nil {
+			standIn.events = append(standIn.events, input.LogEvents...)
+		}
+	}
+
+	response, ok := standIn.responses[action]
+	if !ok {
+		response = cloudWatchResponse{status: http.StatusOK, body: "{}"}
+	}
+
+	w.Header().Set("Content-Type", cloudWatchContentType)
+	w.WriteHeader(response.status)
+	_, _ = w.Write([]byte(response.body))
+}
+
+func newTestCloudWatch(t *testing.T, responses map[string]cloudWatchResponse) (*CloudWatch, *cloudWatchStandIn) {
+	t.Helper()
+
+	standIn := &cloudWatchStandIn{responses: responses}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	awsConfig := aws.Config{Credentials: credentials.NewStaticCredentialsProvider("id", "secret", "")}
+
+	return &CloudWatch{
+		Client: NewCloudWatchClient(awsConfig, server.URL, "us-east-1"),
+		Config: &config.CloudWatchConfig{LogGroup: "/ocm-log-forwarder/test", LogStream: "service-logs"},
+	}, standIn
+}
+
+func testLogEntries(t *testing.T, count int) []*v1.LogEntry {
+	t.Helper()
+
+	start := time.Date(2023, 4, 5, 0, 0, 0, 0, time.UTC)
+	logs := make([]*v1.LogEntry, count)
+
+	for i := range logs {
+		logEntry, err := v1.NewLogEntry().
+			ID(string(rune('a' + i))).
+			ClusterID("test").
+			Summary("test").
+			Timestamp(start.Add(time.Duration(i) * time.Minute)).
+			Build()
+		if err != nil {
+			t.Fatalf("unable to build log entry - %v", err)
+		}
+
+		logs[i] = logEntry
+	}
+
+	return logs
+}
+
+func TestCloudWatch_ensureLogStream(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		responses map[string]cloudWatchResponse
+		wantErr   bool
+		wantReady bool
+	}{
+		{
+			name: "ensure existing log group and log stream are used",
+			responses: map[string]cloudWatchResponse{
+				cloudWatchActionCreateLogGroup: {
+					status: http.StatusBadRequest,
+					body:   `{"__type":"com.amazonaws.logs#ResourceAlreadyExistsException","message":"exists"}`,
+				},
+				cloudWatchActionCreateLogStream: {
+					status: http.StatusBadRequest,
+					body:   `{"__type":"ResourceAlreadyExistsException","message":"exists"}`,
+				},
+			},
+			wantReady: true,
+		},
+		{
+			name:      "ensure missing log group and log stream are created",
+			responses: map[string]cloudWatchResponse{},
+			wantReady: true,
+		},
+		{
+			name: "ensure other errors are returned",
+			responses: map[string]cloudWatchResponse{
+				cloudWatchActionCreateLogGroup: {
+					status: http.StatusBadRequest,
+					body:   `{"__type":"AccessDeniedException","message":"denied"}`,
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cw, standIn := newTestCloudWatch(t, tt.responses)
+			proc := &processor.Processor{Config: &config.Config{ClusterID: "test"}, Context: context.TODO()}
+
+			if err := cw.ensureLogStream(proc); (err != nil) != tt.wantErr {
+				t.Fatalf("CloudWatch.ensureLogStream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if cw.Ready != tt.wantReady {
+				t.Errorf("CloudWatch.ensureLogStream() ready = %v, want %v", cw.Ready, tt.wantReady)
+			}
+
+			if !tt.wantErr && len(standIn.actions) != 2 {
+				t.Errorf("CloudWatch.ensureLogStream() actions = %v, want group and stream", standIn.actions)
+			}
+		})
+	}
+}
+
+func TestCloudWatch_Send(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		response  cloudWatchResponse
+		wantSent  []string
+		wantReady bool
+	}{
+		{
+			name:      "ensure accepted events are sent",
+			response:  cloudWatchResponse{status: http.StatusOK, body: `{}`},
+			wantSent:  []string{"a", "b", "c", "d"},
+			wantReady: true,
+		},
+		{
+			name: "ensure too new events are retried and too old or expired events are not",
+			response: cloudWatchResponse{
+				status: http.StatusOK,
+				body: `{"rejectedLogEventsInfo":` +
+					`{"tooOldLogEventEndIndex":0,"expiredLogEventEndIndex":1,"tooNewLogEventStartIndex":3}}`,
+			},
+			wantSent:  []string{"a", "b", "c"},
+			wantReady: true,
+		},
+		{
+			name: "ensure a missing log stream is recreated on the next send",
+			response: cloudWatchResponse{
+				status: http.StatusBadRequest,
+				body:   `{"__type":"ResourceNotFoundException","message":"missing"}`,
+			},
+			wantSent:  []string{},
+			wantReady: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cw, standIn := newTestCloudWatch(t, map[string]cloudWatchResponse{cloudWatchActionPutLogEvents: tt.response})
+			cw.Ready = true
+
+			proc := &processor.Processor{Config: &config.Config{ClusterID: "test"}, Context: context.TODO()}
+
+			// send in reverse order to ensure events are sent chronologically
+			logs := testLogEntries(t, 4)
+			for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+				logs[i], logs[j] = logs[j], logs[i]
+			}
+
+			if err := cw.Send(proc, &poller.Response{Logs: logs}); err != nil {
+				t.Fatalf("CloudWatch.Send() error = %v", err)
+			}
+
+			for i := 1; i < len(standIn.events); i++ {
+				if standIn.events[i].Timestamp < standIn.events[i-1].Timestamp {
+					t.Errorf("CloudWatch.Send() events are not in chronological order")
+				}
+			}
+
+			if strings.Join(cw.SentMessages, ",") != strings.Join(tt.wantSent, ",") {
+				t.Errorf("CloudWatch.Send() sent = %v, want %v", cw.SentMessages, tt.wantSent)
+			}
+
+			if cw.Ready != tt.wantReady {
+				t.Errorf("CloudWatch.Send() ready = %v, want %v", cw.Ready, tt.wantReady)
+			}
+		})
+	}
+}
+
+func Test_buildEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		summary     string
+		description string
+	}{
+		{
+			name:        "ensure a large description is shortened to valid json",
+			summary:     "test",
+			description: strings.Repeat("é\"", cloudWatchEventMaxMessage),
+		},
+		{
+			name:    "ensure a large summary is shortened to valid json",
+			summary: strings.Repeat("€", cloudWatchEventMaxMessage),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().ID("1").Summary(tt.summary).Description(tt.description).Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			event, err := buildEvent(logEntry)
+			if err != nil {
+				t.Fatalf("buildEvent() error = %v", err)
+			}
+
+			if len(event.Message) > cloudWatchEventMaxMessage {
+				t.Errorf("buildEvent() message size = %d, want at most %d", len(event.Message), cloudWatchEventMaxMessage)
+			}
+
+			if !json.Valid([]byte(event.Message)) || !utf8.ValidString(event.Message) {
+				t.Errorf("buildEvent() message is not valid json")
+			}
+
+			if !strings.Contains(event.Message, `"id":"1"`) {
+				t.Errorf("buildEvent() message is missing the service log id")
+			}
+		})
+	}
+}
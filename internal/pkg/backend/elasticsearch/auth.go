@@ -9,23 +9,60 @@ import (
 
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
 )
 
-func getAuthTypeBasic(proc *processor.Processor) (*elastic.Client, error) {
+func getAuthTypeBasic(proc *processor.Processor) ([]elastic.ClientOptionFunc, error) {
 	username, password, err := config.GetElasticSearchAuthTypeBasic(proc.KubeClient, proc.Context)
 	if err != nil {
-		return &elastic.Client{}, fmt.Errorf("unable to configure basic auth type - %w", err)
+		return nil, fmt.Errorf("unable to configure basic auth type - %w", err)
 	}
 
-	tlsConfig, err := getTLSConfig()
+	return []elastic.ClientOptionFunc{elastic.SetBasicAuth(username, password)}, nil
+}
+
+func getAuthTypeAPIKey(proc *processor.Processor) ([]elastic.ClientOptionFunc, error) {
+	apiKey, err := config.GetElasticSearchAuthTypeAPIKey(proc.KubeClient, proc.Context)
+	if err != nil {
+		return nil, fmt.Errorf("unable to configure api key auth type - %w", err)
+	}
+
+	return []elastic.ClientOptionFunc{authorizationHeader("ApiKey " + apiKey)}, nil
+}
+
+func getAuthTypeBearer(proc *processor.Processor) ([]elastic.ClientOptionFunc, error) {
+	token, err := config.GetElasticSearchAuthTypeBearer(proc.KubeClient, proc.Context)
+	if err != nil {
+		return nil, fmt.Errorf("unable to configure bearer auth type - %w", err)
+	}
+
+	return []elastic.ClientOptionFunc{authorizationHeader("Bearer " + token)}, nil
+}
+
+// authorizationHeader returns a client option which sends an authorization header with
+// every request.
+func authorizationHeader(value string) elastic.ClientOptionFunc {
+	headers := http.Header{}
+	headers.Set("Authorization", value)
+
+	return elastic.SetHeaders(headers)
+}
+
+// getClient returns an elasticsearch client with the provided authentication options.
+func getClient(proc *processor.Processor, authOptions ...elastic.ClientOptionFunc) (*elastic.Client, error) {
+	esTLSConfig, err := config.GetElasticSearchTLSConfig(proc.KubeClient, proc.Context)
+	if err != nil {
+		return &elastic.Client{}, fmt.Errorf("unable to configure tls - %w", err)
+	}
+
+	tlsConfig, err := getTLSConfig(esTLSConfig)
 	if err != nil {
 		return &elastic.Client{}, fmt.Errorf("unable to set tls config - %w", err)
 	}
 
-	client, err := elastic.NewClient(
+	options := []elastic.ClientOptionFunc{
 		elastic.SetSniff(false),
 		elastic.SetURL(config.GetElasticSearchURL()),
-		elastic.SetBasicAuth(username, password),
 		elastic.SetHttpClient(
 			&http.Client{
 				Transport: &http.Transport{
@@ -33,8 +70,9 @@ func getAuthTypeBasic(proc *processor.Processor) (*elastic.Client, error) {
 				},
 			},
 		),
-	)
+	}
 
+	client, err := elastic.NewClient(append(options, authOptions...)...)
 	if err != nil {
 		return client, fmt.Errorf("unable to create elasticsearch client - %w", err)
 	}
@@ -42,26 +80,39 @@ func getAuthTypeBasic(proc *processor.Processor) (*elastic.Client, error) {
 	return client, nil
 }
 
-func getTLSConfig() (*tls.Config, error) {
-	cert, key, verify := config.GetElasticSearchTLSConnectionInfo()
-
-	// if verify false is explicitly requested, return the connection info
+// getTLSConfig returns the tls configuration for the elasticsearch client.  The certificate
+// authority bundle, when provided, replaces the system roots for server verification, and the
+// client certificate, when provided, is presented regardless of server verification.
+func getTLSConfig(esTLSConfig *config.ElasticSearchTLSConfig) (*tls.Config, error) {
 	//nolint: gosec
-	if !verify {
-		return &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12}, nil
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !esTLSConfig.Verify,
+		ServerName:         esTLSConfig.ServerName,
+		MinVersion:         esTLSConfig.MinVersion,
 	}
 
-	// load the tls connection info
-	connectionCert, err := tls.LoadX509KeyPair(cert, key)
-	if err != nil {
-		//nolint: gosec
-		return &tls.Config{}, fmt.Errorf(
-			"unable to load key pair: cert=%s, key=%s - %w",
-			cert,
-			key,
-			err,
-		)
+	if len(esTLSConfig.CABundle) > 0 {
+		pool, err := utils.CertPoolFromPEM(esTLSConfig.CASource, esTLSConfig.CABundle)
+		if err != nil {
+			return tlsConfig, err
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if esTLSConfig.HasCertificate {
+		connectionCert, err := tls.LoadX509KeyPair(esTLSConfig.Certificate, esTLSConfig.Key)
+		if err != nil {
+			return tlsConfig, fmt.Errorf(
+				"unable to load key pair: cert=%s, key=%s - %w",
+				esTLSConfig.Certificate,
+				esTLSConfig.Key,
+				err,
+			)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{connectionCert}
 	}
 
-	return &tls.Config{Certificates: []tls.Certificate{connectionCert}, MinVersion: tls.VersionTLS12}, nil
+	return tlsConfig, nil
 }
@@ -1,8 +1,8 @@
 package elasticsearch
 
 import (
+	"context"
 	"crypto/tls"
-	"os"
 	"testing"
 
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
@@ -23,6 +23,13 @@ func Test_getTLSConfig(t *testing.T) {
 				config.DefaultEnvironmentBackendElasticTLSVerify: "false",
 			},
 		},
+		{
+			name:    "ensure ssl verify true does not require a client certificate",
+			wantErr: false,
+			env: map[string]string{
+				config.DefaultEnvironmentBackendElasticTLSVerify: "true",
+			},
+		},
 		{
 			name:    "ensure a bad cert configuration returns an error",
 			wantErr: true,
@@ -32,6 +39,13 @@ func Test_getTLSConfig(t *testing.T) {
 				config.DefaultEnvironmentBackendElasticTLSKey:         "/etc/pki/exist.key",
 			},
 		},
+		{
+			name:    "ensure a cert without a key returns an error",
+			wantErr: true,
+			env: map[string]string{
+				config.DefaultEnvironmentBackendElasticTLSCertificate: "../../../../test/certs/fake-tls.crt",
+			},
+		},
 		{
 			name:    "ensure a good cert configuration returns as expected",
 			wantErr: false,
@@ -41,15 +55,49 @@ func Test_getTLSConfig(t *testing.T) {
 				config.DefaultEnvironmentBackendElasticTLSKey:         "../../../../test/certs/fake-tls.key",
 			},
 		},
+		{
+			name:    "ensure a good ca bundle returns as expected",
+			wantErr: false,
+			env: map[string]string{
+				config.DefaultEnvironmentBackendElasticTLSCA:         "../../../../test/certs/fake-tls.crt",
+				config.DefaultEnvironmentBackendElasticTLSServerName: "elasticsearch.example.com",
+			},
+		},
+		{
+			name:    "ensure a bad ca bundle returns an error",
+			wantErr: true,
+			env: map[string]string{
+				config.DefaultEnvironmentBackendElasticTLSCA: "../../../../test/certs/fake-tls.key",
+			},
+		},
+		{
+			name:    "ensure both a ca file and ca secret returns an error",
+			wantErr: true,
+			env: map[string]string{
+				config.DefaultEnvironmentBackendElasticTLSCA:           "../../../../test/certs/fake-tls.crt",
+				config.DefaultEnvironmentBackendElasticTLSCASecretName: "elastic-ca",
+			},
+		},
+		{
+			name:    "ensure an unknown minimum tls version returns an error",
+			wantErr: true,
+			env: map[string]string{
+				config.DefaultEnvironmentBackendElasticTLSMinVersion: "1.4",
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			for k, v := range tt.env {
-				os.Setenv(k, v)
+				t.Setenv(k, v)
+			}
+
+			esTLSConfig, err := config.GetElasticSearchTLSConfig(nil, context.TODO())
+			if err == nil {
+				_, err = getTLSConfig(esTLSConfig)
 			}
 
-			_, err := getTLSConfig()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
 
@@ -0,0 +1,183 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/olivere/elastic/v7"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/redisclient"
+)
+
+const (
+	deadLetterDirectoryMode = 0o750
+	deadLetterFileMode      = 0o600
+
+	// Fields of a dead letter queue entry.
+	deadLetterFieldMessageID = "message_id"
+	deadLetterFieldRecord    = "dead_letter"
+)
+
+var ErrElasticSearchDeadLetter = errors.New("unable to write dead letters")
+
+// DeadLetter represents a destination for documents which elasticsearch permanently rejected,
+// for example due to a mapping error, so that they may be inspected and replayed later.
+type DeadLetter interface {
+	Write(proc *processor.Processor, records []*DeadLetterRecord) error
+	String() string
+}
+
+// DeadLetterRecord represents a rejected document along with the reason it was rejected.
+type DeadLetterRecord struct {
+	Timestamp string                 `json:"@timestamp"`
+	Index     string                 `json:"index"`
+	Status    int                    `json:"status"`
+	Error     *elastic.ErrorDetails  `json:"error,omitempty"`
+	Document  *ElasticSearchDocument `json:"document"`
+}
+
+// buildDeadLetterRecord builds a dead letter record from a failed bulk response item.
+func buildDeadLetterRecord(failed *elastic.BulkResponseItem, document *ElasticSearchDocument) *DeadLetterRecord {
+	return &DeadLetterRecord{
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Index:     failed.Index,
+		Status:    failed.Status,
+		Error:     failed.Error,
+		Document:  document,
+	}
+}
+
+// newDeadLetter returns the dead letter destination for the configuration.  A nil destination
+// means rejected documents are only logged and are attempted again on the next poll.
+func newDeadLetter(
+	proc *processor.Processor,
+	es *ElasticSearch,
+	deadLetterConfig *config.ElasticSearchDeadLetterConfig,
+) (DeadLetter, error) {
+	switch deadLetterConfig.Destination {
+	case config.DefaultBackendElasticDeadLetterFile:
+		return &FileDeadLetter{Path: deadLetterConfig.File}, nil
+	case config.DefaultBackendElasticDeadLetterIndex:
+		return &IndexDeadLetter{Client: es.Client, Index: deadLetterConfig.Index}, nil
+	case config.DefaultBackendElasticDeadLetterQueue:
+		client, err := redisclient.New(proc, deadLetterConfig.Redis)
+		if err != nil {
+			return nil, fmt.Errorf("unable to connect to dead letter queue [%s] - %w", deadLetterConfig.Queue, err)
+		}
+
+		return &QueueDeadLetter{Client: client, Stream: deadLetterConfig.Queue}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// FileDeadLetter appends dead letter records to a file as newline delimited json.
+type FileDeadLetter struct {
+	Path string
+}
+
+func (dl *FileDeadLetter) Write(proc *processor.Processor, records []*DeadLetterRecord) error {
+	if err := os.MkdirAll(filepath.Dir(dl.Path), deadLetterDirectoryMode); err != nil {
+		return fmt.Errorf("unable to create dead letter directory for file [%s] - %w", dl.Path, err)
+	}
+
+	file, err := os.OpenFile(dl.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, deadLetterFileMode)
+	if err != nil {
+		return fmt.Errorf("unable to open dead letter file [%s] - %w", dl.Path, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("unable to write dead letter to file [%s] - %w", dl.Path, err)
+		}
+	}
+
+	// ensure the records are on disk before the documents are considered handled
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("unable to sync dead letter file [%s] - %w", dl.Path, err)
+	}
+
+	return nil
+}
+
+func (dl *FileDeadLetter) String() string {
+	return config.DefaultBackendElasticDeadLetterFile
+}
+
+// IndexDeadLetter writes dead letter records to a separate elasticsearch index.  The records
+// nest the original document so that they are not subject to the mapping which rejected it.
+type IndexDeadLetter struct {
+	Client *elastic.Client
+	Index  string
+}
+
+func (dl *IndexDeadLetter) Write(proc *processor.Processor, records []*DeadLetterRecord) error {
+	bulk := dl.Client.Bulk()
+
+	for _, record := range records {
+		bulk.Add(elastic.NewBulkIndexRequest().Index(dl.Index).Id(record.Document.id).Doc(record))
+	}
+
+	response, err := bulk.Do(proc.Context)
+	if err != nil {
+		return fmt.Errorf("unable to write dead letters to index [%s] - %w", dl.Index, err)
+	}
+
+	if failed := response.Failed(); len(failed) > 0 {
+		return fmt.Errorf(
+			"[%d] dead letters rejected by index [%s] - %w",
+			len(failed),
+			dl.Index,
+			ErrElasticSearchDeadLetter,
+		)
+	}
+
+	return nil
+}
+
+func (dl *IndexDeadLetter) String() string {
+	return config.DefaultBackendElasticDeadLetterIndex
+}
+
+// QueueDeadLetter adds dead letter records as entries to a redis stream, so that they may be
+// consumed and replayed by another process.
+type QueueDeadLetter struct {
+	Client *redis.Client
+	Stream string
+}
+
+func (dl *QueueDeadLetter) Write(proc *processor.Processor, records []*DeadLetterRecord) error {
+	pipeline := dl.Client.Pipeline()
+
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("unable to build dead letter for queue [%s] - %w", dl.Stream, err)
+		}
+
+		pipeline.XAdd(proc.Context, &redis.XAddArgs{
+			Stream: dl.Stream,
+			Values: []interface{}{deadLetterFieldMessageID, record.Document.id, deadLetterFieldRecord, string(data)},
+		})
+	}
+
+	if _, err := pipeline.Exec(proc.Context); err != nil {
+		return fmt.Errorf("unable to write dead letters to queue [%s] - %w", dl.Stream, err)
+	}
+
+	return nil
+}
+
+func (dl *QueueDeadLetter) String() string {
+	return config.DefaultBackendElasticDeadLetterQueue
+}
@@ -0,0 +1,64 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/olivere/elastic/v7"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+func TestQueueDeadLetter_Write(t *testing.T) {
+	t.Parallel()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	proc := &processor.Processor{Config: &config.Config{ClusterID: "cluster"}, Context: context.Background()}
+	deadLetter := &QueueDeadLetter{Client: client, Stream: "dead-letter"}
+
+	records := []*DeadLetterRecord{
+		buildDeadLetterRecord(&elastic.BulkResponseItem{Id: "1", Status: http.StatusBadRequest}, &ElasticSearchDocument{id: "1"}),
+		buildDeadLetterRecord(&elastic.BulkResponseItem{Id: "2", Status: http.StatusBadRequest}, &ElasticSearchDocument{id: "2"}),
+	}
+
+	if err := deadLetter.Write(proc, records); err != nil {
+		t.Fatalf("QueueDeadLetter.Write() error = %v", err)
+	}
+
+	entries, err := client.XRange(proc.Context, "dead-letter", "-", "+").Result()
+	if err != nil {
+		t.Fatalf("unable to read dead letter queue - %v", err)
+	}
+
+	if len(entries) != len(records) {
+		t.Fatalf("QueueDeadLetter.Write() entries = %d, want %d", len(entries), len(records))
+	}
+
+	for i, entry := range entries {
+		if entry.Values[deadLetterFieldMessageID] != records[i].Document.id {
+			t.Errorf("QueueDeadLetter.Write() message id = %v, want %v", entry.Values[deadLetterFieldMessageID], records[i].Document.id)
+		}
+
+		record := &DeadLetterRecord{}
+		if err := json.Unmarshal([]byte(entry.Values[deadLetterFieldRecord].(string)), record); err != nil {
+			t.Fatalf("QueueDeadLetter.Write() record is not valid json - %v", err)
+		}
+
+		if record.Status != http.StatusBadRequest {
+			t.Errorf("QueueDeadLetter.Write() status = %v, want %v", record.Status, http.StatusBadRequest)
+		}
+	}
+
+	server.Close()
+
+	if err := deadLetter.Write(proc, records); err == nil {
+		t.Errorf("QueueDeadLetter.Write() with unavailable queue error = nil, want error")
+	}
+}
@@ -1,12 +1,17 @@
 package elasticsearch
 
-import v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+import (
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
 
 // ElasticSearchDocument represents the final document that gets sent
 // to ElasticSearch.  These are the fields that show in in the index
 // as represented by the json tags.
 type ElasticSearchDocument struct {
 	id          string
+	timestamp   time.Time
 	ClusterID   string `json:"cluster_id"`
 	ExternalID  string `json:"external_id"`
 	Username    string `json:"username"`
@@ -21,6 +26,7 @@ type ElasticSearchDocument struct {
 func buildDocument(log *v1.LogEntry) *ElasticSearchDocument {
 	return &ElasticSearchDocument{
 		id:          log.ID(),
+		timestamp:   log.Timestamp(),
 		ClusterID:   log.ClusterID(),
 		ExternalID:  log.ClusterUUID(),
 		Username:    log.Username(),
@@ -28,6 +34,6 @@ func buildDocument(log *v1.LogEntry) *ElasticSearchDocument {
 		EventID:     log.EventStreamID(),
 		ServiceName: log.ServiceName(),
 		Message:     log.Summary(),
-		Timestamp:   log.Timestamp().String(),
+		Timestamp:   log.Timestamp().UTC().Format(time.RFC3339Nano),
 	}
 }
@@ -1,14 +1,19 @@
 package elasticsearch
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/olivere/elastic/v7"
 	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/metrics"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
 )
@@ -17,28 +22,92 @@ const (
 	elasticSearchBatchSize = 100
 )
 
+var ErrElasticSearchRetryable = errors.New("retryable elasticsearch failure")
+
 type ElasticSearch struct {
-	Client          *elastic.Client
-	Documents       []ElasticSearchDocument
-	SentDocumentIDs []string
+	Client           *elastic.Client
+	IndexTemplate    string
+	Pipeline         string
+	RoutingTemplate  string
+	DataStream       bool
+	RetryMaxAttempts int
+	DeadLetter       DeadLetter
+	DedupSeedSize    int
+	DedupLookup      bool
+	Documents        []ElasticSearchDocument
+	SentDocumentIDs  []string
 }
 
 func (es *ElasticSearch) Initialize(proc *processor.Processor) (err error) {
-	var client *elastic.Client
+	var authOptions []elastic.ClientOptionFunc
+
+	// validate the index template so that we do not fail on every request
+	es.IndexTemplate = config.GetElasticSearchIndex()
+	if err := validateIndexTemplate(es.IndexTemplate); err != nil {
+		return fmt.Errorf("unable to configure index - %w", err)
+	}
+
+	// an ingest pipeline and custom routing are applied to each document if configured
+	es.Pipeline = config.GetElasticSearchPipeline()
+	es.RoutingTemplate = config.GetElasticSearchRouting()
+	if err := validateRoutingTemplate(es.RoutingTemplate); err != nil {
+		return fmt.Errorf("unable to configure routing - %w", err)
+	}
 
 	// create the client based on the authentication type
 	switch authType := config.GetElasticSearchAuthType(); {
 	case authType == config.DefaultBackendAuthTypeBasic:
-		client, err = getAuthTypeBasic(proc)
-		if err != nil {
-			return err
-		}
+		authOptions, err = getAuthTypeBasic(proc)
+	case authType == config.DefaultBackendAuthTypeAPIKey:
+		authOptions, err = getAuthTypeAPIKey(proc)
+	case authType == config.DefaultBackendAuthTypeBearer:
+		authOptions, err = getAuthTypeBearer(proc)
+	case authType == config.DefaultBackendAuthTypeNone:
+		// no authentication is sent, which is only useful for unsecured development clusters
 	default:
 		return fmt.Errorf("auth type [%s] - %w", authType, config.ErrBackendAuthUnknown)
 	}
 
+	if err != nil {
+		return err
+	}
+
 	// store the client on the elasticsearch object
-	es.Client = client
+	es.Client, err = getClient(proc, authOptions...)
+	if err != nil {
+		return err
+	}
+
+	// configure how failed documents are retried and where rejected documents are sent
+	es.RetryMaxAttempts, err = config.GetElasticSearchRetryMaxAttempts()
+	if err != nil {
+		return fmt.Errorf("unable to configure retries - %w", err)
+	}
+
+	deadLetterConfig, err := config.GetElasticSearchDeadLetterConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure dead letters - %w", err)
+	}
+
+	es.DeadLetter, err = newDeadLetter(proc, es, deadLetterConfig)
+	if err != nil {
+		return fmt.Errorf("unable to configure dead letters - %w", err)
+	}
+
+	// configure how documents indexed before a restart are found, so that they are not re-sent
+	es.DedupSeedSize, err = config.GetElasticSearchDedupSeedSize()
+	if err != nil {
+		return fmt.Errorf("unable to configure deduplication - %w", err)
+	}
+
+	es.DedupLookup = config.GetElasticSearchDedupLookup()
+
+	// install the index template and lifecycle policy if requested.  data streams also
+	// require a matching index template before the first document is written.
+	es.DataStream = config.GetElasticSearchDataStream()
+	if bootstrap := config.GetElasticSearchBootstrap(); bootstrap || es.DataStream {
+		return es.bootstrap(proc, bootstrap)
+	}
 
 	return nil
 }
@@ -48,6 +117,11 @@ func (es *ElasticSearch) Send(proc *processor.Processor, response *poller.Respon
 
 	documents := es.UnsentDocuments(response.Logs)
 
+	// look up documents which may have been indexed before a restart
+	if es.DedupLookup && len(documents) > 0 {
+		documents = es.unindexedDocuments(proc, documents)
+	}
+
 	documentCount := len(documents)
 
 	// return if there are no unsent documents to send
@@ -69,55 +143,124 @@ func (es *ElasticSearch) Send(proc *processor.Processor, response *poller.Respon
 		// create a request for this batch
 		documentBatch := documents[i:lastDocument]
 
-		response, err := es.BuildRequest(proc, documentBatch).BatchSend(proc)
-		if err != nil {
+		if err := es.sendBatch(proc, documentBatch); err != nil {
 			es.Log(log.Err(err), fmt.Sprintf("batch number [%d] failed to send", batchCount))
 		}
 
-		// append the batch count and handle the response
 		batchCount++
-		es.handleResponse(proc, response)
 	}
 
 	return nil
 }
 
-// BuildRequest builds an ElasticSearchRequest object from a set of documents.
-func (es *ElasticSearch) BuildRequest(proc *processor.Processor, documents []*ElasticSearchDocument) *ElasticSearchRequest {
-	docChan := make(chan *ElasticSearchDocument, len(documents))
+// sendBatch sends a batch of documents, retrying the documents which failed with a retryable
+// error using an exponential backoff.  Documents which are still unsent once the retries are
+// exhausted are attempted again on the next poll.
+func (es *ElasticSearch) sendBatch(proc *processor.Processor, documents []*ElasticSearchDocument) error {
+	pending := documents
 
-	request := &ElasticSearchRequest{
-		Index:     config.GetElasticSearchIndex(),
-		Bulk:      es.Client.Bulk().Index(config.GetElasticSearchIndex()),
-		Documents: make([]*ElasticSearchDocument, len(documents)),
+	send := func() error {
+		request := es.BuildRequest(proc, pending)
+
+		response, err := request.BatchSend(proc)
+		if err != nil {
+			if !retryable(err) {
+				return backoff.Permanent(err)
+			}
+
+			metrics.ElasticSearchRetries.WithLabelValues(proc.Config.ClusterID).Add(float64(len(pending)))
+
+			return err
+		}
+
+		pending = es.handleResponse(proc, request, response)
+		if len(pending) > 0 {
+			metrics.ElasticSearchRetries.WithLabelValues(proc.Config.ClusterID).Add(float64(len(pending)))
+
+			return fmt.Errorf("[%d] documents failed - %w", len(pending), ErrElasticSearchRetryable)
+		}
+
+		return nil
+	}
+
+	retry := backoff.WithContext(
+		backoff.WithMaxRetries(backoff.NewExponentialBackOff(), uint64(es.RetryMaxAttempts)),
+		proc.Context,
+	)
+
+	notify := func(err error, wait time.Duration) {
+		es.Log(log.Warn().Err(err).Str("cluster", proc.Config.ClusterID).Dur("wait", wait), "retrying elasticsearch bulk request")
+	}
+
+	if err := backoff.RetryNotify(send, retry, notify); err != nil {
+		return fmt.Errorf("unable to send [%d] documents - %w", len(pending), err)
 	}
 
-	// build the request for the individual document and add it to
-	// the batch send routine
-	for i := range documents {
-		go func(this *ElasticSearchDocument) {
-			docChan <- this
-		}(documents[i])
+	return nil
+}
+
+// BuildRequest builds an ElasticSearchRequest object from a set of documents.  The index of
+// each document is resolved from the index template, and documents are grouped by their
+// resolved index within the bulk request.
+func (es *ElasticSearch) BuildRequest(proc *processor.Processor, documents []*ElasticSearchDocument) *ElasticSearchRequest {
+	request := &ElasticSearchRequest{
+		Indices:   []string{},
+		Bulk:      es.Client.Bulk(),
+		Documents: make([]*ElasticSearchDocument, 0, len(documents)),
 	}
 
-	defer close(docChan)
+	// group the documents by index, preserving the order in which each index was first seen
+	indexDocuments := map[string][]*ElasticSearchDocument{}
 
-	// if we find one that matches, return
-	for i := 0; i < len(documents); i++ {
-		document := <-docChan
+	for _, document := range documents {
+		index := indexName(es.IndexTemplate, document)
 
-		// add the document to the bulk request
-		es.Log(log.Info().Str(
-			"cluster", proc.Config.ClusterID).Str("id", document.id).Str("index", request.Index),
-			"adding document to elasticsearch bulk request",
-		)
-		es.Log(log.Debug().Str("document", fmt.Sprintf("%+v", document)), "debugging document")
-		request.Bulk.Add(elastic.NewBulkIndexRequest().Id(document.id).Doc(document))
+		if _, ok := indexDocuments[index]; !ok {
+			request.Indices = append(request.Indices, index)
+		}
+
+		indexDocuments[index] = append(indexDocuments[index], document)
+	}
+
+	for _, index := range request.Indices {
+		for _, document := range indexDocuments[index] {
+			// add the document to the bulk request
+			es.Log(log.Info().Str(
+				"cluster", proc.Config.ClusterID).Str("id", document.id).Str("index", index),
+				"adding document to elasticsearch bulk request",
+			)
+			es.Log(log.Debug().Str("document", fmt.Sprintf("%+v", document)), "debugging document")
+			request.Bulk.Add(es.bulkRequest(index, document))
+			request.Documents = append(request.Documents, document)
+		}
 	}
 
 	return request
 }
 
+// bulkRequest returns the bulk request for a single document.  Data streams are append only
+// and only accept the create operation.  The ingest pipeline and routing key are omitted from
+// the request when they are empty.
+func (es *ElasticSearch) bulkRequest(index string, document *ElasticSearchDocument) elastic.BulkableRequest {
+	routing := routingKey(es.RoutingTemplate, document)
+
+	if es.DataStream {
+		return elastic.NewBulkCreateRequest().
+			Index(index).
+			Id(document.id).
+			Pipeline(es.Pipeline).
+			Routing(routing).
+			Doc(document)
+	}
+
+	return elastic.NewBulkIndexRequest().
+		Index(index).
+		Id(document.id).
+		Pipeline(es.Pipeline).
+		Routing(routing).
+		Doc(document)
+}
+
 // UnsentDocuments builds an array of ElasticSearch documents from an array of service log
 // messages.
 func (es *ElasticSearch) UnsentDocuments(logs []*v1.LogEntry) []*ElasticSearchDocument {
@@ -160,11 +303,46 @@ func (es *ElasticSearch) Log(event *zerolog.Event, message string) {
 }
 
 // handleResponse handles the response for an elasticsearch request.  It stores successful
-// items on the object and logs any unsuccessful or updated items.
-func (es *ElasticSearch) handleResponse(proc *processor.Processor, response *elastic.BulkResponse) {
+// items on the object, logs any unsuccessful or updated items, sends permanently rejected
+// documents to the dead letter destination and returns the documents which may be retried.
+func (es *ElasticSearch) handleResponse(
+	proc *processor.Processor,
+	request *ElasticSearchRequest,
+	response *elastic.BulkResponse,
+) (retry []*ElasticSearchDocument) {
+	// a request without any actions is never sent and has no response
+	if response == nil {
+		return nil
+	}
+
+	documents := make(map[string]*ElasticSearchDocument, len(request.Documents))
+	for _, document := range request.Documents {
+		documents[document.id] = document
+	}
+
+	deadLetters := []*DeadLetterRecord{}
+
 	// check for failures in the responses and log
 	if response.Errors {
 		for _, failed := range response.Failed() {
+			switch {
+			// a conflict from a create means the document was already created by a previous
+			// send, which happens when re-sending to a data stream, so we treat it as sent.  a
+			// conflict from an index is a real version conflict.
+			case failed.Status == http.StatusConflict && es.DataStream:
+				es.SentDocumentIDs = append(es.SentDocumentIDs, failed.Id)
+
+				es.Log(log.Debug().Str("message_id", failed.Id), "elasticsearch id already exists")
+
+				continue
+			case retryableStatus(failed.Status):
+				if document, ok := documents[failed.Id]; ok {
+					retry = append(retry, document)
+				}
+			case documents[failed.Id] != nil:
+				deadLetters = append(deadLetters, buildDeadLetterRecord(failed, documents[failed.Id]))
+			}
+
 			es.Log(
 				log.Error().
 					Str("index", failed.Index).
@@ -197,4 +375,54 @@ func (es *ElasticSearch) handleResponse(proc *processor.Processor, response *ela
 			es.Log(log.Debug().Str("message_id", succeeded.Id), "succeeded elasticsearch id")
 		}
 	}
+
+	es.deadLetter(proc, deadLetters)
+
+	return retry
+}
+
+// deadLetter writes permanently rejected documents to the dead letter destination.  Once
+// written, the documents are treated as sent so that they are not rejected again on every
+// poll.  Without a destination, the documents are only logged.
+func (es *ElasticSearch) deadLetter(proc *processor.Processor, records []*DeadLetterRecord) {
+	if es.DeadLetter == nil || len(records) == 0 {
+		return
+	}
+
+	if err := es.DeadLetter.Write(proc, records); err != nil {
+		es.Log(log.Err(err).Str("destination", es.DeadLetter.String()), "unable to write dead letters")
+
+		return
+	}
+
+	for _, record := range records {
+		es.SentDocumentIDs = append(es.SentDocumentIDs, record.Document.id)
+
+		es.Log(
+			log.Warn().Str("message_id", record.Document.id).Str("destination", es.DeadLetter.String()),
+			"wrote rejected elasticsearch document to dead letter destination",
+		)
+	}
+
+	metrics.ElasticSearchDeadLetters.WithLabelValues(
+		proc.Config.ClusterID,
+		es.DeadLetter.String(),
+	).Add(float64(len(records)))
+}
+
+// retryable determines if a failed bulk request may be retried.  Errors returned by elasticsearch
+// are retryable when they are throttled or server side, and any other error is a connection
+// failure which is retryable.
+func retryable(err error) bool {
+	var esErr *elastic.Error
+	if errors.As(err, &esErr) {
+		return retryableStatus(esErr.Status)
+	}
+
+	return true
+}
+
+// retryableStatus determines if a failed bulk item may be retried based on its status code.
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
 }
@@ -1,7 +1,17 @@
 package elasticsearch
 
 import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
 )
 
 func TestElasticSearch_HasSent(t *testing.T) {
@@ -61,3 +71,171 @@ func TestElasticSearch_HasSent(t *testing.T) {
 		})
 	}
 }
+
+func TestElasticSearch_handleResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		dataStream      bool
+		action          string
+		wantSent        []string
+		wantDeadLetters int
+	}{
+		{
+			name:            "ensure a conflict creating in a data stream is treated as sent",
+			dataStream:      true,
+			action:          "create",
+			wantSent:        []string{"2", "1", "3"},
+			wantDeadLetters: 1,
+		},
+		{
+			name:            "ensure a conflict indexing is a rejected document",
+			dataStream:      false,
+			action:          "index",
+			wantSent:        []string{"1", "2", "3"},
+			wantDeadLetters: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			request := &ElasticSearchRequest{
+				Documents: []*ElasticSearchDocument{{id: "1"}, {id: "2"}, {id: "3"}, {id: "4"}},
+			}
+
+			response := &elastic.BulkResponse{
+				Errors: true,
+				Items: []map[string]*elastic.BulkResponseItem{
+					{tt.action: {Id: "1", Status: http.StatusCreated}},
+					{tt.action: {Id: "2", Status: http.StatusConflict}},
+					{tt.action: {Id: "3", Status: http.StatusBadRequest}},
+					{tt.action: {Id: "4", Status: http.StatusTooManyRequests}},
+				},
+			}
+
+			deadLetterPath := filepath.Join(t.TempDir(), "dead-letter.ndjson")
+
+			es := &ElasticSearch{DataStream: tt.dataStream, DeadLetter: &FileDeadLetter{Path: deadLetterPath}}
+
+			if got := es.handleResponse(&processor.Processor{Config: &config.Config{}}, request, nil); got != nil {
+				t.Errorf("ElasticSearch.handleResponse() with nil response retry = %v, want nil", got)
+			}
+
+			retry := es.handleResponse(&processor.Processor{Config: &config.Config{}}, request, response)
+			if len(retry) != 1 || retry[0].id != "4" {
+				t.Errorf("ElasticSearch.handleResponse() retry = %v, want [4]", retry)
+			}
+
+			if strings.Join(es.SentDocumentIDs, ",") != strings.Join(tt.wantSent, ",") {
+				t.Errorf("ElasticSearch.handleResponse() sent = %v, want %v", es.SentDocumentIDs, tt.wantSent)
+			}
+
+			deadLetters, err := os.ReadFile(deadLetterPath)
+			if err != nil {
+				t.Fatalf("unable to read dead letter file - %v", err)
+			}
+
+			if got := strings.Count(string(deadLetters), "\n"); got != tt.wantDeadLetters {
+				t.Errorf("ElasticSearch.handleResponse() dead letters = %s, want %d records", deadLetters, tt.wantDeadLetters)
+			}
+
+			if !strings.Contains(string(deadLetters), `"status":400`) {
+				t.Errorf("ElasticSearch.handleResponse() dead letters = %s, want a record with status 400", deadLetters)
+			}
+		})
+	}
+}
+
+func TestElasticSearch_retryable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "ensure throttled requests are retried",
+			err:  fmt.Errorf("wrapped - %w", &elastic.Error{Status: http.StatusTooManyRequests}),
+			want: true,
+		},
+		{
+			name: "ensure server errors are retried",
+			err:  &elastic.Error{Status: http.StatusServiceUnavailable},
+			want: true,
+		},
+		{
+			name: "ensure client errors are not retried",
+			err:  &elastic.Error{Status: http.StatusBadRequest},
+			want: false,
+		},
+		{
+			name: "ensure connection errors are retried",
+			err:  elastic.ErrNoClient,
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := retryable(tt.err); got != tt.want {
+				t.Errorf("retryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestElasticSearch_bulkRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		dataStream bool
+		pipeline   string
+		routing    string
+		want       string
+	}{
+		{
+			name:       "ensure index uses the index operation",
+			dataStream: false,
+			want:       `{"index":{"_index":"ocm","_id":"1"}}`,
+		},
+		{
+			name:       "ensure data stream uses the create operation",
+			dataStream: true,
+			want:       `{"create":{"_index":"ocm","_id":"1"}}`,
+		},
+		{
+			name:       "ensure pipeline and resolved routing are set",
+			dataStream: false,
+			pipeline:   "geoip",
+			routing:    "{cluster_id}",
+			want:       `{"index":{"_index":"ocm","_id":"1","routing":"cluster","pipeline":"geoip"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			es := &ElasticSearch{DataStream: tt.dataStream, Pipeline: tt.pipeline, RoutingTemplate: tt.routing}
+
+			source, err := es.bulkRequest("ocm", &ElasticSearchDocument{id: "1", ClusterID: "cluster"}).Source()
+			if err != nil {
+				t.Fatalf("unable to build bulk request source - %v", err)
+			}
+
+			if source[0] != tt.want {
+				t.Errorf("ElasticSearch.bulkRequest() = %v, want %v", source[0], tt.want)
+			}
+		})
+	}
+}
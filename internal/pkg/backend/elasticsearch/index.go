@@ -0,0 +1,160 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+var (
+	ErrElasticSearchInvalidIndex   = errors.New("invalid index template")
+	ErrElasticSearchInvalidRouting = errors.New("invalid routing template")
+)
+
+// indexDateSeparators are the characters which may separate the tokens of a date placeholder.
+const indexDateSeparators = ".-_"
+
+// indexDateTokens returns the tokens of a date placeholder, which are the same as the tokens of
+// elasticsearch date math, with longer tokens first.
+func indexDateTokens() []string {
+	return []string{"yyyy", "yy", "MM", "dd", "HH"}
+}
+
+// indexDateValues returns the value of each date placeholder token for a timestamp in utc.
+func indexDateValues(timestamp time.Time) map[string]string {
+	timestamp = timestamp.UTC()
+
+	return map[string]string{
+		"yyyy": fmt.Sprintf("%04d", timestamp.Year()),
+		"yy":   fmt.Sprintf("%02d", timestamp.Year()%100),
+		"MM":   fmt.Sprintf("%02d", int(timestamp.Month())),
+		"dd":   fmt.Sprintf("%02d", timestamp.Day()),
+		"HH":   fmt.Sprintf("%02d", timestamp.Hour()),
+	}
+}
+
+// indexFields returns the document fields which may be used as index template placeholders.
+func indexFields(document *ElasticSearchDocument) map[string]string {
+	return map[string]string{
+		utils.TemplateFieldClusterID:   document.ClusterID,
+		utils.TemplateFieldExternalID:  document.ExternalID,
+		utils.TemplateFieldServiceName: document.ServiceName,
+		utils.TemplateFieldSeverity:    document.Severity,
+	}
+}
+
+// indexName resolves the index template for a document.  The resolved name is lowercased as
+// elasticsearch does not allow uppercase index names.  A document without a timestamp uses the
+// unix epoch, so that the same document always resolves to the same index.
+func indexName(template string, document *ElasticSearchDocument) string {
+	timestamp := document.timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Unix(0, 0)
+	}
+
+	return strings.ToLower(resolveTemplate(template, indexFields(document), indexDateValues(timestamp)))
+}
+
+// routingKey resolves the routing template for a document.  An empty template results in an
+// empty routing key, which uses the default routing by document id.  Routing templates only
+// contain field placeholders, so that the same document is always routed to the same shard.
+func routingKey(template string, document *ElasticSearchDocument) string {
+	return resolveTemplate(template, indexFields(document), nil)
+}
+
+// resolveTemplate resolves a template from the values of its fields and, when provided, the
+// values of its date tokens.  Unknown placeholders are left unchanged.
+func resolveTemplate(template string, fields, dates map[string]string) string {
+	return utils.ResolveTemplateFunc(template, func(placeholder string) (string, bool) {
+		if value, ok := fields[placeholder]; ok {
+			return value, true
+		}
+
+		if dates == nil {
+			return "", false
+		}
+
+		return resolveDate(placeholder, dates)
+	})
+}
+
+// resolveDate resolves a date placeholder, such as 'yyyy.MM.dd', from the values of its tokens.
+// It returns false if the placeholder is not only made up of tokens and separators.
+func resolveDate(pattern string, dates map[string]string) (string, bool) {
+	var (
+		resolved strings.Builder
+		tokens   int
+	)
+
+	for rest := pattern; rest != ""; {
+		token := ""
+
+		for _, candidate := range indexDateTokens() {
+			if strings.HasPrefix(rest, candidate) {
+				token = candidate
+
+				break
+			}
+		}
+
+		switch {
+		case token != "":
+			resolved.WriteString(dates[token])
+			rest = rest[len(token):]
+			tokens++
+		case strings.ContainsRune(indexDateSeparators, rune(rest[0])):
+			resolved.WriteByte(rest[0])
+			rest = rest[1:]
+		default:
+			return "", false
+		}
+	}
+
+	return resolved.String(), tokens > 0
+}
+
+// validateIndexTemplate validates that an index template is not empty and that each of its
+// placeholders is either a known field or a date pattern.
+func validateIndexTemplate(template string) error {
+	if template == "" {
+		return fmt.Errorf("empty index - %w", ErrElasticSearchInvalidIndex)
+	}
+
+	return validateTemplate("index", template, true, ErrElasticSearchInvalidIndex)
+}
+
+// validateRoutingTemplate validates that each placeholder of a routing template is a known field.
+// Date placeholders are not allowed, as they would route the same document to different shards
+// over time.  An empty routing template disables custom routing.
+func validateRoutingTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+
+	return validateTemplate("routing", template, false, ErrElasticSearchInvalidRouting)
+}
+
+// validateTemplate validates that each placeholder of a template is either a known field or, when
+// dates are allowed, a date pattern.
+func validateTemplate(kind, template string, dates bool, invalid error) error {
+	if strings.Count(template, "{") != strings.Count(template, "}") {
+		return fmt.Errorf("unbalanced placeholder in %s [%s] - %w", kind, template, invalid)
+	}
+
+	fields := indexFields(&ElasticSearchDocument{})
+
+	for _, placeholder := range utils.TemplatePlaceholders(template) {
+		if _, ok := fields[placeholder]; ok {
+			continue
+		}
+
+		if _, ok := resolveDate(placeholder, indexDateValues(time.Time{})); !ok || !dates {
+			return fmt.Errorf("unknown placeholder [{%s}] in %s [%s] - %w", placeholder, kind, template, invalid)
+		}
+	}
+
+	return nil
+}
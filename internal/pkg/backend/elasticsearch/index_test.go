@@ -0,0 +1,169 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_indexName(t *testing.T) {
+	t.Parallel()
+
+	document := &ElasticSearchDocument{
+		ClusterID: "cluster",
+		Severity:  "Error",
+		timestamp: time.Date(2023, 4, 5, 23, 44, 53, 0, time.FixedZone("east", 3600)),
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "ensure static index is unchanged",
+			template: "ocm_service_logs",
+			want:     "ocm_service_logs",
+		},
+		{
+			name:     "ensure field and utc date placeholders are replaced",
+			template: "ocm-{cluster_id}-{yyyy.MM.dd}",
+			want:     "ocm-cluster-2023.04.05",
+		},
+		{
+			name:     "ensure resolved index is lowercase",
+			template: "ocm-{severity}-{yy.MM}",
+			want:     "ocm-error-23.04",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := indexName(tt.template, document); got != tt.want {
+				t.Errorf("indexName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_indexName_withoutTimestamp(t *testing.T) {
+	t.Parallel()
+
+	// a document without a timestamp always resolves to the same index
+	document := &ElasticSearchDocument{ClusterID: "cluster"}
+
+	if got, want := indexName("ocm-{cluster_id}-{yyyy.MM.dd}", document), "ocm-cluster-1970.01.01"; got != want {
+		t.Errorf("indexName() = %v, want %v", got, want)
+	}
+}
+
+func Test_validateIndexTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{
+			name:     "ensure valid template returns no error",
+			template: "ocm-{cluster_id}-{yyyy.MM.dd-HH}",
+			wantErr:  false,
+		},
+		{
+			name:     "ensure unknown placeholder returns error",
+			template: "ocm-{cluster_name}",
+			wantErr:  true,
+		},
+		{
+			name:     "ensure unbalanced placeholder returns error",
+			template: "ocm-{cluster_id",
+			wantErr:  true,
+		},
+		{
+			name:     "ensure digits in a date placeholder return error",
+			template: "ocm-{yyyy1}",
+			wantErr:  true,
+		},
+		{
+			name:     "ensure punctuation and digits in a date placeholder return error",
+			template: "ocm-{dd-2}",
+			wantErr:  true,
+		},
+		{
+			name:     "ensure a date placeholder without tokens returns error",
+			template: "ocm-{.-}",
+			wantErr:  true,
+		},
+		{
+			name:     "ensure empty template returns error",
+			template: "",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := validateIndexTemplate(tt.template); (err != nil) != tt.wantErr {
+				t.Errorf("validateIndexTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_validateRoutingTemplate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{
+			name:     "ensure field placeholders return no error",
+			template: "{cluster_id}-{severity}",
+			wantErr:  false,
+		},
+		{
+			name:     "ensure empty template returns no error",
+			template: "",
+			wantErr:  false,
+		},
+		{
+			name:     "ensure date placeholders return error",
+			template: "{cluster_id}-{yyyy.MM.dd}",
+			wantErr:  true,
+		},
+		{
+			name:     "ensure unknown placeholder returns error",
+			template: "{cluster_name}",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := validateRoutingTemplate(tt.template); (err != nil) != tt.wantErr {
+				t.Errorf("validateRoutingTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_routingKey(t *testing.T) {
+	t.Parallel()
+
+	document := &ElasticSearchDocument{ClusterID: "Cluster"}
+
+	if got := routingKey("{cluster_id}-{yyyy}", document); got != "Cluster-{yyyy}" {
+		t.Errorf("routingKey() = %v, want %v", got, "Cluster-{yyyy}")
+	}
+}
@@ -0,0 +1,125 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/olivere/elastic/v7"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+// Recent returns the ids of the most recently indexed documents for the cluster, which are used
+// to seed the sent document ids on startup.
+func (es *ElasticSearch) Recent(proc *processor.Processor) ([]string, error) {
+	if es.DedupSeedSize < 1 {
+		return []string{}, nil
+	}
+
+	// a match phrase query matches the cluster id whether or not it is mapped as a keyword
+	result, err := es.search().
+		Query(elastic.NewMatchPhraseQuery("cluster_id", proc.Config.ClusterID)).
+		SortBy(elastic.NewFieldSort("@timestamp").Desc().UnmappedType("date")).
+		Size(es.DedupSeedSize).
+		Do(proc.Context)
+	if err != nil {
+		return nil, fmt.Errorf("unable to search for recent documents in [%s] - %w", es.searchIndex(), err)
+	}
+
+	return hitIDs(result), nil
+}
+
+// Present returns the ids which are already indexed.  An ids query is used rather than a multi
+// get as the index of a document is not known when writing to data streams.
+func (es *ElasticSearch) Present(proc *processor.Processor, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return []string{}, nil
+	}
+
+	result, err := es.search().
+		Query(elastic.NewIdsQuery().Ids(ids...)).
+		Size(len(ids)).
+		Do(proc.Context)
+	if err != nil {
+		return nil, fmt.Errorf("unable to search for [%d] documents in [%s] - %w", len(ids), es.searchIndex(), err)
+	}
+
+	return hitIDs(result), nil
+}
+
+// MarkSent stores document ids as sent.
+func (es *ElasticSearch) MarkSent(ids ...string) {
+	es.SentDocumentIDs = append(es.SentDocumentIDs, ids...)
+}
+
+// unindexedDocuments looks up documents which were not sent by this process and returns those
+// which are not already indexed.  Indexed documents are marked as sent.  If the lookup fails, all
+// documents are returned so that they are sent as they would be without a lookup.
+func (es *ElasticSearch) unindexedDocuments(proc *processor.Processor, documents []*ElasticSearchDocument) []*ElasticSearchDocument {
+	// look up the documents in batches to keep the size of each query bounded
+	for i := 0; i < len(documents); i += elasticSearchBatchSize {
+		last := i + elasticSearchBatchSize
+		if last > len(documents) {
+			last = len(documents)
+		}
+
+		ids := make([]string, 0, last-i)
+		for _, document := range documents[i:last] {
+			ids = append(ids, document.id)
+		}
+
+		present, err := es.Present(proc, ids)
+		if err != nil {
+			es.Log(log.Warn().Err(err).Str("cluster", proc.Config.ClusterID), "unable to look up existing documents")
+
+			return documents
+		}
+
+		es.MarkSent(present...)
+	}
+
+	unindexed := make([]*ElasticSearchDocument, 0, len(documents))
+
+	for _, document := range documents {
+		if es.HasSent(document) {
+			es.Log(log.Debug().Str("message_id", document.id), "elasticsearch id already exists")
+
+			continue
+		}
+
+		unindexed = append(unindexed, document)
+	}
+
+	return unindexed
+}
+
+// search returns a search service across every index which the index template resolves to.  The
+// source of the documents is not fetched as only their ids are needed.
+func (es *ElasticSearch) search() *elastic.SearchService {
+	return es.Client.Search(es.searchIndex()).
+		FetchSource(false).
+		IgnoreUnavailable(true).
+		AllowNoIndices(true)
+}
+
+// searchIndex returns the index pattern which matches every index resolved from the index
+// template.
+func (es *ElasticSearch) searchIndex() string {
+	return strings.ToLower(indexPattern(es.IndexTemplate))
+}
+
+// hitIDs returns the document ids of the hits of a search result.
+func hitIDs(result *elastic.SearchResult) []string {
+	ids := []string{}
+
+	if result == nil || result.Hits == nil {
+		return ids
+	}
+
+	for _, hit := range result.Hits.Hits {
+		ids = append(ids, hit.Id)
+	}
+
+	return ids
+}
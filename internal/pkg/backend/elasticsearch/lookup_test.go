@@ -0,0 +1,45 @@
+package elasticsearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+func TestElasticSearch_unindexedDocuments(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/ocm-*/_search") {
+			t.Errorf("unexpected request path [%s]", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"hits":{"hits":[{"_index":"ocm-cluster","_id":"2"}]}}`))
+	}))
+	defer server.Close()
+
+	client, err := elastic.NewClient(elastic.SetURL(server.URL), elastic.SetSniff(false), elastic.SetHealthcheck(false))
+	if err != nil {
+		t.Fatalf("unable to create client - %v", err)
+	}
+
+	es := &ElasticSearch{Client: client, IndexTemplate: "ocm-{cluster_id}", SentDocumentIDs: []string{"1"}}
+	proc := &processor.Processor{Config: &config.Config{ClusterID: "cluster"}, Context: context.TODO()}
+
+	got := es.unindexedDocuments(proc, []*ElasticSearchDocument{{id: "2"}, {id: "3"}})
+	if len(got) != 1 || got[0].id != "3" {
+		t.Errorf("ElasticSearch.unindexedDocuments() = %v, want [3]", got)
+	}
+
+	if !es.HasSent(&ElasticSearchDocument{id: "2"}) {
+		t.Errorf("ElasticSearch.unindexedDocuments() sent = %v, want 2 marked as sent", es.SentDocumentIDs)
+	}
+}
@@ -9,7 +9,7 @@ import (
 )
 
 type ElasticSearchRequest struct {
-	Index     string
+	Indices   []string
 	Documents []*ElasticSearchDocument
 	Bulk      *elastic.BulkService
 }
@@ -23,7 +23,7 @@ func (req *ElasticSearchRequest) BatchSend(proc *processor.Processor) (*elastic.
 	// send the bulk request
 	log.Info().
 		Str("cluster", proc.Config.ClusterID).
-		Str("index", req.Index).
+		Strs("indices", req.Indices).
 		Int("document_count", req.Bulk.NumberOfActions()).
 		Msg("sending documents to elasticsearch")
 	bulkResponse, err := req.Bulk.Do(proc.Context)
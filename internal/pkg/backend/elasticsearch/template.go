@@ -0,0 +1,337 @@
+package elasticsearch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/olivere/elastic/v7"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	// elasticSearchTemplateVersion is the version of the index template and lifecycle policy
+	// which are managed by this backend.  It must be incremented whenever either changes so
+	// that existing installations are upgraded.
+	elasticSearchTemplateVersion = 2
+
+	// elasticSearchTemplatePriority is higher than the priority of the built in templates, such
+	// as 'logs-*-*', so that our template takes precedence for matching names.
+	elasticSearchTemplatePriority = 200
+	elasticSearchTemplateManager  = "ocm-log-forwarder"
+
+	// Settings recorded in the metadata of the index template, as the template must be replaced
+	// when any of them changes.
+	elasticSearchTemplateCustomRouting   = "custom_routing"
+	elasticSearchTemplateDataStream      = "data_stream"
+	elasticSearchTemplateLifecyclePolicy = "lifecycle_policy"
+
+	// elasticSearchPolicyHash records in the metadata of the lifecycle policy a hash of its phases,
+	// as the policy must be replaced when its settings change.
+	elasticSearchPolicyHash = "hash"
+
+	elasticSearchKeywordIgnoreAbove = 1024
+)
+
+// indexPattern returns the pattern which matches every index resolved from an index template.
+func indexPattern(template string) string {
+	return utils.ResolveTemplateFunc(template, func(string) (string, bool) { return "*", true })
+}
+
+// templateMeta returns the metadata which identifies the version of a managed object.
+func templateMeta() map[string]interface{} {
+	return map[string]interface{}{
+		"managed_by": elasticSearchTemplateManager,
+		"version":    elasticSearchTemplateVersion,
+	}
+}
+
+// mappings returns the explicit mappings of the document fields.  Identifiers are keywords so
+// that they can be filtered and aggregated, and the message is full text searchable.
+func mappings() map[string]interface{} {
+	keyword := map[string]interface{}{"type": "keyword"}
+
+	return map[string]interface{}{
+		"properties": map[string]interface{}{
+			"cluster_id":      keyword,
+			"external_id":     keyword,
+			"username":        keyword,
+			"severity":        keyword,
+			"service_name":    keyword,
+			"event_stream_id": keyword,
+			"message": map[string]interface{}{
+				"type": "text",
+				"fields": map[string]interface{}{
+					"keyword": map[string]interface{}{"type": "keyword", "ignore_above": elasticSearchKeywordIgnoreAbove},
+				},
+			},
+			"@timestamp": map[string]interface{}{
+				"type":   "date",
+				"format": "strict_date_optional_time||epoch_millis",
+			},
+		},
+	}
+}
+
+// indexTemplate returns the body of the composable index template for names matching the
+// index template.  The lifecycle policy is only applied when one is provided.  Data streams
+// reject routed documents unless custom routing is allowed by the template.
+func indexTemplate(template string, dataStream, customRouting bool, policyName string) map[string]interface{} {
+	templateBody := map[string]interface{}{
+		"mappings": mappings(),
+	}
+
+	if policyName != "" {
+		templateBody["settings"] = map[string]interface{}{
+			"index.lifecycle.name": policyName,
+		}
+	}
+
+	meta := templateMeta()
+	meta[elasticSearchTemplateCustomRouting] = dataStream && customRouting
+	meta[elasticSearchTemplateDataStream] = dataStream
+	meta[elasticSearchTemplateLifecyclePolicy] = policyName
+
+	body := map[string]interface{}{
+		"index_patterns": []string{indexPattern(template)},
+		"priority":       elasticSearchTemplatePriority,
+		"version":        elasticSearchTemplateVersion,
+		"template":       templateBody,
+		"_meta":          meta,
+	}
+
+	if dataStream {
+		dataStreamBody := map[string]interface{}{}
+		if customRouting {
+			dataStreamBody["allow_custom_routing"] = true
+		}
+
+		body["data_stream"] = dataStreamBody
+	}
+
+	return body
+}
+
+// lifecyclePolicy returns the body of the index lifecycle management policy, which records a hash
+// of its phases in its metadata.
+func lifecyclePolicy(ilmConfig *config.ElasticSearchILMConfig, dataStream bool) map[string]interface{} {
+	phases := lifecyclePhases(ilmConfig, dataStream)
+
+	meta := templateMeta()
+	meta[elasticSearchPolicyHash] = policyHash(phases)
+
+	return map[string]interface{}{
+		"policy": map[string]interface{}{
+			"_meta":  meta,
+			"phases": phases,
+		},
+	}
+}
+
+// lifecyclePhases returns the phases of the index lifecycle management policy.  Rollover only
+// applies to data streams, as indices resolved from the index template are not written through
+// a rollover alias; time based index names should be used instead.
+func lifecyclePhases(ilmConfig *config.ElasticSearchILMConfig, dataStream bool) map[string]interface{} {
+	phases := map[string]interface{}{}
+
+	if dataStream {
+		phases["hot"] = map[string]interface{}{
+			"actions": map[string]interface{}{
+				"rollover": map[string]interface{}{
+					"max_age":  ilmConfig.RolloverMaxAge,
+					"max_size": ilmConfig.RolloverMaxSize,
+				},
+			},
+		}
+	}
+
+	if ilmConfig.DeleteAfter != config.DefaultBackendElasticILMDeleteNever {
+		phases["delete"] = map[string]interface{}{
+			"min_age": ilmConfig.DeleteAfter,
+			"actions": map[string]interface{}{
+				"delete": map[string]interface{}{},
+			},
+		}
+	}
+
+	return phases
+}
+
+// policyHash returns a hash of the phases of a lifecycle policy.
+func policyHash(phases map[string]interface{}) string {
+	// NOTE: maps are marshaled with sorted keys and the phases only contain strings and maps, so
+	// the result is stable and marshaling cannot fail.
+	rendered, _ := json.Marshal(phases)
+	hash := sha256.Sum256(rendered)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// bootstrap installs the index template and, when requested, the lifecycle policy.  When
+// upgrade is false, an existing index template is never modified.
+func (es *ElasticSearch) bootstrap(proc *processor.Processor, upgrade bool) error {
+	var policyName string
+
+	if upgrade {
+		ilmConfig := config.GetElasticSearchILMConfig()
+
+		if err := es.ensureLifecyclePolicy(proc, ilmConfig); err != nil {
+			return err
+		}
+
+		policyName = ilmConfig.PolicyName
+	}
+
+	return es.ensureIndexTemplate(proc, policyName, upgrade)
+}
+
+// ensureIndexTemplate creates the index template if it does not exist.  An existing template is
+// only replaced when it is managed by us and its version is not newer than ours, so that newer
+// or user managed templates are not clobbered.  It is then replaced when upgrade is requested
+// and either its version is older than ours or its settings differ from ours.  Without upgrade,
+// it is only replaced when it does not match our data stream settings, as writes would otherwise
+// be rejected, and its lifecycle policy is kept.
+func (es *ElasticSearch) ensureIndexTemplate(proc *processor.Processor, policyName string, upgrade bool) error {
+	name := config.GetElasticSearchIndexTemplateName()
+	logger := log.Info().Str("cluster", proc.Config.ClusterID).Str("template", name)
+
+	response, err := es.Client.IndexGetIndexTemplate(name).Do(proc.Context)
+	if err != nil && !elastic.IsNotFound(err) {
+		return fmt.Errorf("unable to retrieve index template [%s] - %w", name, err)
+	}
+
+	exists := err == nil && len(response.IndexTemplates) > 0
+	if exists {
+		var meta map[string]interface{}
+		if response.IndexTemplates[0].IndexTemplate != nil {
+			meta = response.IndexTemplates[0].IndexTemplate.Meta
+		}
+
+		version, managed := managedVersion(meta)
+		if !managed {
+			es.Log(logger, "skipping upgrade of elasticsearch index template not managed by this backend")
+
+			return nil
+		}
+
+		writable := metaBool(meta, elasticSearchTemplateDataStream) == es.DataStream &&
+			metaBool(meta, elasticSearchTemplateCustomRouting) == (es.DataStream && es.RoutingTemplate != "")
+
+		if !upgrade {
+			policyName = metaString(meta, elasticSearchTemplateLifecyclePolicy)
+		}
+
+		current := version > elasticSearchTemplateVersion || (version == elasticSearchTemplateVersion &&
+			writable && metaString(meta, elasticSearchTemplateLifecyclePolicy) == policyName)
+
+		if current || (!upgrade && writable) {
+			es.Log(logger.Int("version", version), "using existing elasticsearch index template")
+
+			return nil
+		}
+	}
+
+	body := indexTemplate(es.IndexTemplate, es.DataStream, es.RoutingTemplate != "", policyName)
+
+	_, err = es.Client.IndexPutIndexTemplate(name).Create(!exists).BodyJson(body).Do(proc.Context)
+	if err != nil {
+		return fmt.Errorf("unable to put index template [%s] - %w", name, err)
+	}
+
+	es.Log(logger.Int("version", elasticSearchTemplateVersion), "installed elasticsearch index template")
+
+	return nil
+}
+
+// ensureLifecyclePolicy creates the lifecycle policy if it does not exist, or replaces it if it
+// is managed by us and either its version is older than ours or it is the same as ours but its
+// phases differ from ours.
+func (es *ElasticSearch) ensureLifecyclePolicy(proc *processor.Processor, ilmConfig *config.ElasticSearchILMConfig) error {
+	name := ilmConfig.PolicyName
+	logger := log.Info().Str("cluster", proc.Config.ClusterID).Str("policy", name)
+
+	response, err := es.Client.XPackIlmGetLifecycle().Policy(name).Do(proc.Context)
+	if err != nil && !elastic.IsNotFound(err) {
+		return fmt.Errorf("unable to retrieve lifecycle policy [%s] - %w", name, err)
+	}
+
+	phases := lifecyclePhases(ilmConfig, es.DataStream)
+
+	if existing, ok := response[name]; err == nil && ok {
+		version, managed := policyVersion(existing)
+		if !managed {
+			es.Log(logger, "skipping upgrade of elasticsearch lifecycle policy not managed by this backend")
+
+			return nil
+		}
+
+		if version > elasticSearchTemplateVersion ||
+			(version == elasticSearchTemplateVersion && metaString(policyMeta(existing), elasticSearchPolicyHash) == policyHash(phases)) {
+			es.Log(logger.Int("version", version), "using existing elasticsearch lifecycle policy")
+
+			return nil
+		}
+	}
+
+	_, err = es.Client.XPackIlmPutLifecycle().Policy(name).BodyJson(lifecyclePolicy(ilmConfig, es.DataStream)).Do(proc.Context)
+	if err != nil {
+		return fmt.Errorf("unable to put lifecycle policy [%s] - %w", name, err)
+	}
+
+	es.Log(logger.Int("version", elasticSearchTemplateVersion), "installed elasticsearch lifecycle policy")
+
+	return nil
+}
+
+// policyVersion returns the version of a lifecycle policy from its metadata and whether the
+// policy is managed by us.
+func policyVersion(response *elastic.XPackIlmGetLifecycleResponse) (int, bool) {
+	return managedVersion(policyMeta(response))
+}
+
+// policyMeta returns the metadata of a lifecycle policy.
+//
+// NOTE: the version of the lifecycle policy response is incremented by elasticsearch on every
+// update, so we cannot use it to determine the version of our policy.
+func policyMeta(response *elastic.XPackIlmGetLifecycleResponse) map[string]interface{} {
+	meta, _ := response.Policy["_meta"].(map[string]interface{})
+
+	return meta
+}
+
+// managedVersion returns the version from the metadata of a managed object and whether the
+// object is managed by us.  Objects which were not created by us are version zero.
+func managedVersion(meta map[string]interface{}) (int, bool) {
+	if manager, _ := meta["managed_by"].(string); manager != elasticSearchTemplateManager {
+		return 0, false
+	}
+
+	// json numbers are decoded as floats
+	version, ok := meta["version"].(float64)
+	if !ok {
+		return 0, true
+	}
+
+	return int(version), true
+}
+
+// metaBool returns a boolean setting from the metadata of a managed object.  Settings which were
+// not recorded, such as by older versions of this backend, are false.
+func metaBool(meta map[string]interface{}, key string) bool {
+	value, _ := meta[key].(bool)
+
+	return value
+}
+
+// metaString returns a string setting from the metadata of a managed object.  Settings which were
+// not recorded, such as by older versions of this backend, are empty.
+func metaString(meta map[string]interface{}, key string) string {
+	value, _ := meta[key].(string)
+
+	return value
+}
@@ -0,0 +1,326 @@
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/olivere/elastic/v7"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+func Test_indexPattern(t *testing.T) {
+	t.Parallel()
+
+	if got := indexPattern("ocm-{cluster_id}-{yyyy.MM.dd}"); got != "ocm-*-*" {
+		t.Errorf("indexPattern() = %v, want %v", got, "ocm-*-*")
+	}
+}
+
+func Test_lifecyclePolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		dataStream bool
+		delete     string
+		wantPhases []string
+	}{
+		{
+			name:       "ensure data stream policy rolls over and deletes",
+			dataStream: true,
+			delete:     "30d",
+			wantPhases: []string{"hot", "delete"},
+		},
+		{
+			name:       "ensure index policy only deletes",
+			dataStream: false,
+			delete:     "30d",
+			wantPhases: []string{"delete"},
+		},
+		{
+			name:       "ensure delete can be disabled",
+			dataStream: true,
+			delete:     config.DefaultBackendElasticILMDeleteNever,
+			wantPhases: []string{"hot"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ilmConfig := &config.ElasticSearchILMConfig{RolloverMaxAge: "1d", RolloverMaxSize: "50gb", DeleteAfter: tt.delete}
+
+			policy, ok := lifecyclePolicy(ilmConfig, tt.dataStream)["policy"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("lifecyclePolicy() missing policy")
+			}
+
+			phases, ok := policy["phases"].(map[string]interface{})
+			if !ok || len(phases) != len(tt.wantPhases) {
+				t.Fatalf("lifecyclePolicy() phases = %v, want %v", policy["phases"], tt.wantPhases)
+			}
+
+			for _, phase := range tt.wantPhases {
+				if _, ok := phases[phase]; !ok {
+					t.Errorf("lifecyclePolicy() missing phase %v", phase)
+				}
+			}
+		})
+	}
+}
+
+func Test_policyVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		response    *elastic.XPackIlmGetLifecycleResponse
+		want        int
+		wantManaged bool
+	}{
+		{
+			name: "ensure version is read from metadata",
+			response: &elastic.XPackIlmGetLifecycleResponse{
+				Version: 7,
+				Policy: map[string]interface{}{
+					"_meta": map[string]interface{}{"managed_by": elasticSearchTemplateManager, "version": float64(2)},
+				},
+			},
+			want:        2,
+			wantManaged: true,
+		},
+		{
+			name: "ensure policy managed by another tool is not managed",
+			response: &elastic.XPackIlmGetLifecycleResponse{
+				Version: 7,
+				Policy:  map[string]interface{}{"_meta": map[string]interface{}{"managed_by": "other", "version": float64(2)}},
+			},
+			want:        0,
+			wantManaged: false,
+		},
+		{
+			name: "ensure policy without metadata is not managed",
+			response: &elastic.XPackIlmGetLifecycleResponse{
+				Version: 7,
+				Policy:  map[string]interface{}{},
+			},
+			want:        0,
+			wantManaged: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, managed := policyVersion(tt.response)
+			if got != tt.want || managed != tt.wantManaged {
+				t.Errorf("policyVersion() = %v, %v, want %v, %v", got, managed, tt.want, tt.wantManaged)
+			}
+		})
+	}
+}
+
+// elasticSearchStandIn is a local stand-in for the elasticsearch index template and lifecycle
+// policy apis, which responds with fixed existing objects and records the objects that are put.
+type elasticSearchStandIn struct {
+	template string
+	policy   string
+
+	mutex  sync.Mutex
+	puts   []string
+	bodies []string
+}
+
+func (standIn *elasticSearchStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method == http.MethodPut {
+		body, _ := io.ReadAll(r.Body)
+
+		standIn.puts = append(standIn.puts, r.URL.Path)
+		standIn.bodies = append(standIn.bodies, string(body))
+		_, _ = w.Write([]byte(`{"acknowledged":true}`))
+
+		return
+	}
+
+	var existing string
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/_index_template/"):
+		existing = standIn.template
+	case strings.HasPrefix(r.URL.Path, "/_ilm/policy/"):
+		existing = standIn.policy
+	}
+
+	if existing == "" {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{}`))
+
+		return
+	}
+
+	_, _ = w.Write([]byte(existing))
+}
+
+func TestElasticSearch_bootstrap(t *testing.T) {
+	t.Parallel()
+
+	templateName := config.GetElasticSearchIndexTemplateName()
+	policyName := config.GetElasticSearchILMConfig().PolicyName
+
+	existingTemplate := func(meta string) string {
+		return fmt.Sprintf(`{"index_templates":[{"name":%q,"index_template":{"index_patterns":["ocm-*"],"_meta":%s}}]}`,
+			templateName, meta)
+	}
+
+	existingPolicy := func(meta string) string {
+		return fmt.Sprintf(`{%q:{"version":9,"policy":{"_meta":%s,"phases":{}}}}`, policyName, meta)
+	}
+
+	templateMeta := func(dataStream bool, policy string) string {
+		return fmt.Sprintf(`{"managed_by":%q,"version":%d,"custom_routing":false,"data_stream":%t,"lifecycle_policy":%q}`,
+			elasticSearchTemplateManager, elasticSearchTemplateVersion, dataStream, policy)
+	}
+
+	policyMeta := func(dataStream bool) string {
+		return fmt.Sprintf(`{"managed_by":%q,"version":%d,"hash":%q}`, elasticSearchTemplateManager, elasticSearchTemplateVersion,
+			policyHash(lifecyclePhases(config.GetElasticSearchILMConfig(), dataStream)))
+	}
+
+	oldMeta := fmt.Sprintf(`{"managed_by":%q,"version":%d}`, elasticSearchTemplateManager, elasticSearchTemplateVersion-1)
+	userMeta := `{"managed_by":"platform-team","version":1}`
+
+	tests := []struct {
+		name       string
+		template   string
+		policy     string
+		dataStream bool
+		routing    string
+		upgrade    bool
+		wantPuts   []string
+		wantBody   string
+	}{
+		{
+			name:     "ensure missing objects are installed",
+			upgrade:  true,
+			wantPuts: []string{"/_ilm/policy/" + policyName, "/_index_template/" + templateName},
+		},
+		{
+			name:     "ensure older managed objects are upgraded",
+			template: existingTemplate(oldMeta),
+			policy:   existingPolicy(oldMeta),
+			upgrade:  true,
+			wantPuts: []string{"/_ilm/policy/" + policyName, "/_index_template/" + templateName},
+		},
+		{
+			name:     "ensure current managed objects are left untouched",
+			template: existingTemplate(templateMeta(false, policyName)),
+			policy:   existingPolicy(policyMeta(false)),
+			upgrade:  true,
+			wantPuts: []string{},
+		},
+		{
+			name:       "ensure current managed templates are upgraded when custom routing is enabled",
+			template:   existingTemplate(templateMeta(true, policyName)),
+			policy:     existingPolicy(policyMeta(true)),
+			dataStream: true,
+			routing:    "{cluster_id}",
+			upgrade:    true,
+			wantPuts:   []string{"/_index_template/" + templateName},
+		},
+		{
+			name:       "ensure current managed templates are upgraded to attach the lifecycle policy",
+			template:   existingTemplate(templateMeta(true, "")),
+			policy:     existingPolicy(policyMeta(true)),
+			dataStream: true,
+			upgrade:    true,
+			wantPuts:   []string{"/_index_template/" + templateName},
+			wantBody:   fmt.Sprintf(`"index.lifecycle.name":%q`, policyName),
+		},
+		{
+			name:       "ensure current managed policies are upgraded when their phases change",
+			template:   existingTemplate(templateMeta(true, policyName)),
+			policy:     existingPolicy(policyMeta(false)),
+			dataStream: true,
+			upgrade:    true,
+			wantPuts:   []string{"/_ilm/policy/" + policyName},
+			wantBody:   `"rollover"`,
+		},
+		{
+			name:       "ensure managed templates are replaced without upgrade when data streams are toggled",
+			template:   existingTemplate(templateMeta(false, policyName)),
+			dataStream: true,
+			upgrade:    false,
+			wantPuts:   []string{"/_index_template/" + templateName},
+			wantBody:   fmt.Sprintf(`"index.lifecycle.name":%q`, policyName),
+		},
+		{
+			name:     "ensure objects managed by others are left untouched",
+			template: existingTemplate(userMeta),
+			policy:   existingPolicy(userMeta),
+			upgrade:  true,
+			wantPuts: []string{},
+		},
+		{
+			name:     "ensure objects without metadata are left untouched",
+			template: existingTemplate(`{}`),
+			policy:   existingPolicy(`{}`),
+			upgrade:  true,
+			wantPuts: []string{},
+		},
+		{
+			name:     "ensure older managed templates are not upgraded unless requested",
+			template: existingTemplate(oldMeta),
+			upgrade:  false,
+			wantPuts: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			standIn := &elasticSearchStandIn{template: tt.template, policy: tt.policy}
+
+			server := httptest.NewServer(standIn)
+			t.Cleanup(server.Close)
+
+			client, err := elastic.NewClient(elastic.SetURL(server.URL), elastic.SetSniff(false), elastic.SetHealthcheck(false))
+			if err != nil {
+				t.Fatalf("unable to create client - %v", err)
+			}
+
+			es := &ElasticSearch{Client: client, IndexTemplate: "ocm-{cluster_id}", DataStream: tt.dataStream, RoutingTemplate: tt.routing}
+			proc := &processor.Processor{Config: &config.Config{ClusterID: "cluster"}, Context: context.TODO()}
+
+			if err := es.bootstrap(proc, tt.upgrade); err != nil {
+				t.Fatalf("ElasticSearch.bootstrap() error = %v", err)
+			}
+
+			if strings.Join(standIn.puts, ",") != strings.Join(tt.wantPuts, ",") {
+				t.Errorf("ElasticSearch.bootstrap() puts = %v, want %v", standIn.puts, tt.wantPuts)
+			}
+
+			if tt.wantBody != "" && !strings.Contains(strings.Join(standIn.bodies, ","), tt.wantBody) {
+				t.Errorf("ElasticSearch.bootstrap() bodies = %v, want %s", standIn.bodies, tt.wantBody)
+			}
+		})
+	}
+}
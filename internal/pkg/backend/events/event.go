@@ -0,0 +1,177 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	eventNamePrefix     = "ocm-service-log"
+	eventNameHashLength = 16
+	eventDefaultReason  = "ServiceLog"
+	eventMaxMessage     = 1024
+	eventLabelCluster   = "ocm-log-forwarder/cluster-id"
+
+	// eventAnnotationIDs records the ids of the service logs aggregated into an event, so that a
+	// service log which is sent again after a restart does not increment its count.  Only the most
+	// recent ids are kept to bound the size of the annotation.
+	eventAnnotationIDs    = "ocm-log-forwarder/service-log-ids"
+	eventAnnotationMaxIDs = 1000
+	eventIDSeparator      = ","
+)
+
+// Event is a kubernetes event built from a service log.
+type Event struct {
+	ID        string
+	Name      string
+	Namespace string
+	Cluster   string
+	Component string
+	Type      string
+	Reason    string
+	Message   string
+	Timestamp time.Time
+}
+
+// buildEvent builds an event from a service log.  Service logs with the same type, reason and
+// message produce an event with the same name, so that repeats are aggregated into one event.
+func buildEvent(proc *processor.Processor, eventsConfig *config.EventsConfig, logEntry *v1.LogEntry) *Event {
+	event := &Event{
+		ID:        logEntry.ID(),
+		Namespace: eventsConfig.Namespace,
+		Cluster:   proc.Config.ClusterID,
+		Component: eventsConfig.Component,
+		Type:      eventType(logEntry.Severity()),
+		Reason:    eventReason(logEntry.ServiceName()),
+		Message:   eventMessage(logEntry.Summary()),
+		Timestamp: logEntry.Timestamp(),
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join([]string{event.Cluster, event.Type, event.Reason, event.Message}, "\n")))
+	event.Name = fmt.Sprintf("%s.%s", eventNamePrefix, hex.EncodeToString(hash[:])[:eventNameHashLength])
+
+	return event
+}
+
+// Object returns the kubernetes object of a new event.  The event involves the namespace it is
+// recorded in, as a service log relates to the cluster rather than to any object within it.
+func (event *Event) Object() *corev1.Event {
+	timestamp := metav1.NewTime(event.Timestamp)
+
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        event.Name,
+			Namespace:   event.Namespace,
+			Labels:      map[string]string{eventLabelCluster: event.Cluster},
+			Annotations: map[string]string{eventAnnotationIDs: event.ID},
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Namespace",
+			Name:       event.Namespace,
+		},
+		Reason:         event.Reason,
+		Message:        event.Message,
+		Type:           event.Type,
+		Count:          1,
+		FirstTimestamp: timestamp,
+		LastTimestamp:  timestamp,
+		Source:         corev1.EventSource{Component: event.Component},
+	}
+}
+
+// aggregate aggregates the event into an existing event by incrementing its count and recording
+// its id.  It returns false if the existing event already aggregates the event.
+func (event *Event) aggregate(existing *corev1.Event) bool {
+	var ids []string
+	if recorded := existing.Annotations[eventAnnotationIDs]; recorded != "" {
+		ids = strings.Split(recorded, eventIDSeparator)
+	}
+
+	for _, id := range ids {
+		if id == event.ID {
+			return false
+		}
+	}
+
+	if ids = append(ids, event.ID); len(ids) > eventAnnotationMaxIDs {
+		ids = ids[len(ids)-eventAnnotationMaxIDs:]
+	}
+
+	if existing.Annotations == nil {
+		existing.Annotations = map[string]string{}
+	}
+
+	existing.Annotations[eventAnnotationIDs] = strings.Join(ids, eventIDSeparator)
+
+	existing.Count++
+	if event.Timestamp.After(existing.LastTimestamp.Time) {
+		existing.LastTimestamp = metav1.NewTime(event.Timestamp)
+	}
+
+	return true
+}
+
+// eventType returns a warning event for error and critical service logs, and a normal event
+// otherwise.
+func eventType(severity v1.Severity) string {
+	if utils.SeverityAtLeast(severity, v1.SeverityError) {
+		return corev1.EventTypeWarning
+	}
+
+	return corev1.EventTypeNormal
+}
+
+// eventReason converts a service name into an upper camel case reason, which is the convention
+// for event reasons, such as 'SREManualAction' or 'ClusterUpgrade'.
+func eventReason(serviceName string) string {
+	var reason strings.Builder
+
+	upper := true
+
+	for _, character := range serviceName {
+		if !unicode.IsLetter(character) && !unicode.IsDigit(character) {
+			upper = true
+
+			continue
+		}
+
+		if upper {
+			character = unicode.ToUpper(character)
+			upper = false
+		}
+
+		reason.WriteRune(character)
+	}
+
+	if reason.Len() == 0 {
+		return eventDefaultReason
+	}
+
+	return reason.String()
+}
+
+// eventMessage truncates a summary to the maximum length of an event message.
+func eventMessage(summary string) string {
+	if len(summary) <= eventMaxMessage {
+		return summary
+	}
+
+	return strings.ToValidUTF8(summary[:eventMaxMessage], "")
+}
@@ -0,0 +1,110 @@
+package events
+
+import (
+	"fmt"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+// Events is a backend which records service logs as kubernetes events, so that they are visible
+// with standard tooling such as 'oc get events'.
+type Events struct {
+	Client       typedcorev1.EventInterface
+	Config       *config.EventsConfig
+	SentMessages []string
+}
+
+func (events *Events) Initialize(proc *processor.Processor) error {
+	events.Config = config.GetEventsConfig()
+	events.Client = proc.KubeClient.CoreV1().Events(events.Config.Namespace)
+
+	return nil
+}
+
+func (events *Events) Send(proc *processor.Processor, response *poller.Response) error {
+	var recorded int
+
+	for _, logEntry := range response.Logs {
+		if events.HasSent(logEntry) {
+			continue
+		}
+
+		if err := events.record(proc, buildEvent(proc, events.Config, logEntry)); err != nil {
+			events.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to record event")
+
+			continue
+		}
+
+		events.SentMessages = append(events.SentMessages, logEntry.ID())
+		recorded++
+	}
+
+	if recorded > 0 {
+		events.Log(
+			log.Info().Str("cluster", proc.Config.ClusterID).Str("namespace", events.Config.Namespace).Int("event_count", recorded),
+			"recorded service logs as events",
+		)
+	}
+
+	return nil
+}
+
+func (events *Events) String() string {
+	return config.DefaultBackendEvents
+}
+
+func (events *Events) HasSent(message *v1.LogEntry) bool {
+	for i := range events.SentMessages {
+		if message.ID() == events.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (events *Events) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", events.String())).Msg(message)
+}
+
+// record creates an event, or increments the count of an existing event with the same name, which
+// is the same aggregation that the kubernetes event recorder performs for repeated events.  An
+// existing event which already aggregates the service log is left unchanged.
+func (events *Events) record(proc *processor.Processor, event *Event) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing, err := events.Client.Get(proc.Context, event.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			_, err = events.Client.Create(proc.Context, event.Object(), metav1.CreateOptions{})
+
+			return err
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// the service log was already recorded, for example before a restart
+		if !event.aggregate(existing) {
+			return nil
+		}
+
+		_, err = events.Client.Update(proc.Context, existing, metav1.UpdateOptions{})
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to record event [%s/%s] - %w", events.Config.Namespace, event.Name, err)
+	}
+
+	return nil
+}
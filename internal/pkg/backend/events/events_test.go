@@ -0,0 +1,157 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+func TestEvents_Send(t *testing.T) {
+	t.Parallel()
+
+	eventsConfig := &config.EventsConfig{Namespace: "test", Component: "ocm-log-forwarder"}
+	events := &Events{
+		Client: fake.NewSimpleClientset().CoreV1().Events(eventsConfig.Namespace),
+		Config: eventsConfig,
+	}
+
+	proc := &processor.Processor{
+		Config:  &config.Config{ClusterID: "test"},
+		Context: context.Background(),
+	}
+
+	first := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	logs := []*v1.LogEntry{}
+
+	// the first two service logs are repeats which aggregate into one event
+	for i, entry := range []struct {
+		id       string
+		severity v1.Severity
+		service  string
+		summary  string
+	}{
+		{id: "1", severity: v1.SeverityError, service: "sre-manual-action", summary: "node failure"},
+		{id: "2", severity: v1.SeverityError, service: "sre-manual-action", summary: "node failure"},
+		{id: "3", severity: v1.SeverityInfo, service: "ClusterUpgrade", summary: "upgrade scheduled"},
+	} {
+		logEntry, err := v1.NewLogEntry().
+			ID(entry.id).
+			Severity(entry.severity).
+			ServiceName(entry.service).
+			Summary(entry.summary).
+			Timestamp(first.Add(time.Duration(i) * time.Minute)).
+			Build()
+		if err != nil {
+			t.Fatalf("unable to build log entry - %v", err)
+		}
+
+		logs = append(logs, logEntry)
+	}
+
+	if err := events.Send(proc, &poller.Response{Logs: logs}); err != nil {
+		t.Fatalf("Events.Send() error = %v", err)
+	}
+
+	list, err := events.Client.List(proc.Context, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unable to list events - %v", err)
+	}
+
+	if len(list.Items) != 2 {
+		t.Fatalf("Events.Send() events = %d, want 2", len(list.Items))
+	}
+
+	got := map[string]corev1.Event{}
+	for _, event := range list.Items {
+		got[event.Reason] = event
+	}
+
+	warning := got["SreManualAction"]
+	if warning.Type != corev1.EventTypeWarning || warning.Count != 2 || warning.Message != "node failure" {
+		t.Errorf("Events.Send() warning event = %+v, want aggregated warning event", warning)
+	}
+
+	if !warning.FirstTimestamp.Time.Equal(first) || !warning.LastTimestamp.Time.Equal(first.Add(time.Minute)) {
+		t.Errorf("Events.Send() warning event timestamps = [%v, %v], want [%v, %v]",
+			warning.FirstTimestamp, warning.LastTimestamp, first, first.Add(time.Minute))
+	}
+
+	if normal := got["ClusterUpgrade"]; normal.Type != corev1.EventTypeNormal || normal.Count != 1 {
+		t.Errorf("Events.Send() normal event = %+v, want single normal event", normal)
+	}
+
+	if len(events.SentMessages) != 3 {
+		t.Errorf("Events.Send() sent = %v, want 3 service logs", events.SentMessages)
+	}
+
+	// a restart forgets the sent service logs, which must not increment the count again
+	restarted := &Events{Client: events.Client, Config: eventsConfig}
+	if err := restarted.Send(proc, &poller.Response{Logs: logs}); err != nil {
+		t.Fatalf("Events.Send() after restart error = %v", err)
+	}
+
+	list, err = events.Client.List(proc.Context, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unable to list events - %v", err)
+	}
+
+	for _, event := range list.Items {
+		if event.Reason == "SreManualAction" && event.Count != 2 {
+			t.Errorf("Events.Send() after restart warning event count = %d, want 2", event.Count)
+		}
+
+		if event.Reason == "ClusterUpgrade" && event.Count != 1 {
+			t.Errorf("Events.Send() after restart normal event count = %d, want 1", event.Count)
+		}
+	}
+
+	if len(restarted.SentMessages) != 3 {
+		t.Errorf("Events.Send() after restart sent = %v, want 3 service logs", restarted.SentMessages)
+	}
+}
+
+func Test_eventReason(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		serviceName string
+		want        string
+	}{
+		{
+			name:        "ensure camel case service names are unchanged",
+			serviceName: "SREManualAction",
+			want:        "SREManualAction",
+		},
+		{
+			name:        "ensure separated service names are converted to camel case",
+			serviceName: "cluster_upgrade-service",
+			want:        "ClusterUpgradeService",
+		},
+		{
+			name:        "ensure empty service names use the default reason",
+			serviceName: "",
+			want:        eventDefaultReason,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := eventReason(tt.serviceName); got != tt.want {
+				t.Errorf("eventReason() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package file
+
+import (
+	"fmt"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/format"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+// File is a backend which appends service logs as newline delimited json, or as CEF or LEEF
+// events, to a local file, for example on a mounted volume of an air-gapped cluster.  The ids of
+// the service logs which were written are recorded in an index so that a restart does not write
+// them again.
+type File struct {
+	Writer       *RotatingFile
+	Formatter    format.Formatter
+	Index        *SentIndex
+	SentMessages []string
+}
+
+func (file *File) Initialize(proc *processor.Processor) error {
+	fileConfig, err := config.GetFileConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure file backend - %w", err)
+	}
+
+	file.Formatter, err = format.NewFormatter(fileConfig.Format)
+	if err != nil {
+		return fmt.Errorf("unable to configure file backend - %w", err)
+	}
+
+	// open the file now so that an unwritable path fails fast
+	file.Writer = &RotatingFile{Config: fileConfig}
+	if err := file.Writer.Open(); err != nil {
+		return fmt.Errorf("unable to open file - %w", err)
+	}
+
+	// seed the sent messages from the service logs written before a restart
+	file.Index = NewSentIndex(fileConfig.Path)
+
+	file.SentMessages, err = file.Index.Load()
+	if err != nil {
+		return fmt.Errorf("unable to load sent service logs - %w", err)
+	}
+
+	return nil
+}
+
+func (file *File) Send(proc *processor.Processor, response *poller.Response) error {
+	lines := [][]byte{}
+	ids := []string{}
+
+	for _, logEntry := range response.Logs {
+		if file.HasSent(logEntry) {
+			continue
+		}
+
+		line, err := file.Formatter.Format(logEntry)
+		if err != nil {
+			file.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to build file line")
+
+			continue
+		}
+
+		lines = append(lines, line)
+		ids = append(ids, logEntry.ID())
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	// the batch is synced to disk before the service logs are considered sent.  lines which were
+	// written before a failure are also considered sent, so that they are not written again.
+	written, err := file.Writer.Write(lines)
+	if markErr := file.markSent(ids[:written]); markErr != nil {
+		file.Log(log.Err(markErr).Str("cluster", proc.Config.ClusterID), "failed to record written service logs")
+	}
+
+	if err != nil {
+		file.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Int("document_count", written), "failed to write service logs to file")
+
+		return nil
+	}
+
+	file.Log(
+		log.Info().Str("cluster", proc.Config.ClusterID).Str("path", file.Writer.Config.Path).Int("document_count", len(lines)),
+		"wrote service logs to file",
+	)
+
+	return nil
+}
+
+func (file *File) String() string {
+	return config.DefaultBackendFile
+}
+
+func (file *File) HasSent(message *v1.LogEntry) bool {
+	for i := range file.SentMessages {
+		if message.ID() == file.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (file *File) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", file.String())).Msg(message)
+}
+
+// markSent records the service logs which were written as sent, both in memory and in the index.
+// They are considered sent in memory even if the index cannot be written, so that they are only
+// written again after a restart.
+func (file *File) markSent(ids []string) error {
+	file.SentMessages = append(file.SentMessages, ids...)
+
+	if file.Index == nil {
+		return nil
+	}
+
+	return file.Index.Append(ids)
+}
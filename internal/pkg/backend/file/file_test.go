@@ -0,0 +1,222 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/format"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+func TestFile_Send(t *testing.T) {
+	t.Parallel()
+
+	proc := &processor.Processor{Config: &config.Config{ClusterID: "cluster"}, Context: context.TODO()}
+
+	t.Run("ensure service logs written before a restart are not written again", func(t *testing.T) {
+		t.Parallel()
+
+		fileConfig := &config.FileConfig{Path: filepath.Join(t.TempDir(), "service-logs.ndjson"), MaxSize: 1024}
+
+		// a new backend is a restart, which is given every service log by the poller
+		for _, ids := range [][]string{{"1", "2"}, {"1", "2", "3"}} {
+			file := newTestFile(t, fileConfig)
+
+			if err := file.Send(proc, testResponse(t, ids...)); err != nil {
+				t.Fatalf("File.Send() error = %v", err)
+			}
+
+			if err := file.Writer.Close(); err != nil {
+				t.Fatalf("RotatingFile.Close() error = %v", err)
+			}
+		}
+
+		if got := writtenIDs(t, fileConfig.Path); strings.Join(got, ",") != "1,2,3" {
+			t.Errorf("File.Send() written = %v, want %v", got, "1,2,3")
+		}
+	})
+
+	t.Run("ensure lines written before a failure are not written again", func(t *testing.T) {
+		t.Parallel()
+
+		fileConfig := &config.FileConfig{Path: filepath.Join(t.TempDir(), "service-logs.ndjson")}
+
+		line, err := (&format.JSON{}).Format(testResponse(t, "1").Logs[0])
+		if err != nil {
+			t.Fatalf("unable to format service log - %v", err)
+		}
+
+		// the first service log fits in the active file but the second rotates it, and the new
+		// active file cannot record its start time
+		fileConfig.MaxSize = int64(len(line)+1) * 2
+		if _, err := (&RotatingFile{Config: fileConfig}).Write([][]byte{line}); err != nil {
+			t.Fatalf("RotatingFile.Write() error = %v", err)
+		}
+
+		blocked := (&RotatingFile{Config: fileConfig}).startedPath() + fileTemporarySuffix
+		if err := os.Mkdir(blocked, fileDirectoryMode); err != nil {
+			t.Fatalf("unable to block start time - %v", err)
+		}
+
+		file := newTestFile(t, fileConfig)
+		file.SentMessages = []string{}
+
+		if err := file.Send(proc, testResponse(t, "2", "3")); err != nil {
+			t.Fatalf("File.Send() error = %v", err)
+		}
+
+		if strings.Join(file.SentMessages, ",") != "2" {
+			t.Fatalf("File.Send() sent = %v, want %v", file.SentMessages, "2")
+		}
+
+		if err := os.Remove(blocked); err != nil {
+			t.Fatalf("unable to unblock start time - %v", err)
+		}
+
+		if err := file.Send(proc, testResponse(t, "2", "3")); err != nil {
+			t.Fatalf("File.Send() error = %v", err)
+		}
+
+		if err := file.Writer.Close(); err != nil {
+			t.Fatalf("RotatingFile.Close() error = %v", err)
+		}
+
+		if got := writtenIDs(t, fileConfig.Path); strings.Join(got, ",") != "1,2,3" {
+			t.Errorf("File.Send() written = %v, want %v", got, "1,2,3")
+		}
+
+		indexed, err := file.Index.Load()
+		if err != nil {
+			t.Fatalf("SentIndex.Load() error = %v", err)
+		}
+
+		if strings.Join(indexed, ",") != "2,3" {
+			t.Errorf("SentIndex.Load() = %v, want %v", indexed, "2,3")
+		}
+	})
+}
+
+func TestSentIndex_Load(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{
+			name:     "ensure complete lines are loaded",
+			contents: "1\n2\n",
+			want:     []string{"1", "2"},
+		},
+		{
+			name:     "ensure a partially written last line is ignored",
+			contents: "1\n2",
+			want:     []string{"1"},
+		},
+		{
+			name: "ensure a missing index has no ids",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			index := NewSentIndex(filepath.Join(t.TempDir(), "service-logs.ndjson"))
+
+			if tt.contents != "" {
+				if err := os.WriteFile(index.Path, []byte(tt.contents), fileMode); err != nil {
+					t.Fatalf("unable to write index - %v", err)
+				}
+			}
+
+			got, err := index.Load()
+			if err != nil {
+				t.Fatalf("SentIndex.Load() error = %v", err)
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("SentIndex.Load() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newTestFile returns a file backend which writes json lines, seeded from its index as it is on
+// initialization.
+func newTestFile(t *testing.T, fileConfig *config.FileConfig) *File {
+	t.Helper()
+
+	file := &File{
+		Writer:    &RotatingFile{Config: fileConfig},
+		Formatter: &format.JSON{},
+		Index:     NewSentIndex(fileConfig.Path),
+	}
+
+	sent, err := file.Index.Load()
+	if err != nil {
+		t.Fatalf("SentIndex.Load() error = %v", err)
+	}
+
+	file.SentMessages = sent
+
+	return file
+}
+
+func testResponse(t *testing.T, ids ...string) *poller.Response {
+	t.Helper()
+
+	response := &poller.Response{}
+
+	for _, id := range ids {
+		logEntry, err := v1.NewLogEntry().ID(id).Build()
+		if err != nil {
+			t.Fatalf("unable to build log entry - %v", err)
+		}
+
+		response.Logs = append(response.Logs, logEntry)
+	}
+
+	return response
+}
+
+// writtenIDs returns the ids of the service logs written to the rotated files and then the active
+// file, in the order they were written.
+func writtenIDs(t *testing.T, path string) []string {
+	t.Helper()
+
+	rotated, err := filepath.Glob(strings.TrimSuffix(path, filepath.Ext(path)) + "-*")
+	if err != nil {
+		t.Fatalf("unable to list rotated files - %v", err)
+	}
+
+	ids := []string{}
+
+	for _, file := range append(rotated, path) {
+		contents, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("unable to read file - %v", err)
+		}
+
+		for _, line := range strings.Split(strings.TrimSpace(string(contents)), "\n") {
+			logEntry, err := v1.UnmarshalLogEntry(line)
+			if err != nil {
+				t.Fatalf("unable to read service log - %v", err)
+			}
+
+			ids = append(ids, logEntry.ID())
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,80 @@
+package file
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// the ids of the service logs which were written are recorded in a hidden index file next to
+	// the active file, so that they are not written again after a restart.
+	fileSentPrefix = "."
+	fileSentSuffix = ".sent"
+)
+
+// SentIndex is a file which records the id of each service log which was written, one per line.
+// It is kept across rotations, as the poller returns every service log on each poll.
+type SentIndex struct {
+	Path string
+}
+
+// NewSentIndex returns the index of the service logs written to the active file at a path.  It is
+// hidden so that it is not mistaken for a rotated file.
+func NewSentIndex(path string) *SentIndex {
+	return &SentIndex{Path: filepath.Join(filepath.Dir(path), fileSentPrefix+filepath.Base(path)+fileSentSuffix)}
+}
+
+// Load returns the ids recorded in the index.  A missing index has no ids, and a partially
+// written last line, which is left behind if the previous process stopped while appending, is
+// ignored.
+func (index *SentIndex) Load() ([]string, error) {
+	contents, err := os.ReadFile(index.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+
+		return nil, fmt.Errorf("unable to read sent index [%s] - %w", index.Path, err)
+	}
+
+	ids := []string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(contents)))
+	for scanner.Scan() {
+		if scanner.Text() != "" {
+			ids = append(ids, scanner.Text())
+		}
+	}
+
+	if len(ids) > 0 && !strings.HasSuffix(string(contents), "\n") {
+		ids = ids[:len(ids)-1]
+	}
+
+	return ids, nil
+}
+
+// Append records ids in the index and syncs it so that they are on disk once it returns.
+func (index *SentIndex) Append(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	file, err := os.OpenFile(index.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, fileMode)
+	if err != nil {
+		return fmt.Errorf("unable to open sent index [%s] - %w", index.Path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(strings.Join(ids, "\n") + "\n"); err != nil {
+		return fmt.Errorf("unable to write sent index [%s] - %w", index.Path, err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("unable to sync sent index [%s] - %w", index.Path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,313 @@
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+)
+
+const (
+	fileDirectoryMode = 0o750
+	fileMode          = 0o640
+
+	// rotated files are named after the active file with the time of rotation, which sorts the
+	// rotated files from oldest to newest.
+	fileRotatedTimeFormat = "20060102T150405.000000000Z"
+	fileCompressedSuffix  = ".gz"
+	fileTemporarySuffix   = ".tmp"
+
+	// the time that the first line was written to the active file is persisted in a hidden marker
+	// file next to it, so that its age survives a restart.
+	fileStartedPrefix = "."
+	fileStartedSuffix = ".started"
+)
+
+// RotatingFile is a file which is appended to and rotated once it exceeds a maximum size or
+// age.  Rotated files are optionally compressed and removed once they exceed the retention
+// limits.
+type RotatingFile struct {
+	Config    *config.FileConfig
+	file      *os.File
+	size      int64
+	startedAt time.Time
+}
+
+// Open opens the active file for appending, creating it if it does not exist.  An existing file
+// is never truncated so that a restart continues where the previous process stopped.  As the
+// creation time of a file is not portable, the age of an existing file is measured from the time
+// its first line was written, which is read from its marker file.
+func (rf *RotatingFile) Open() error {
+	if err := os.MkdirAll(filepath.Dir(rf.Config.Path), fileDirectoryMode); err != nil {
+		return fmt.Errorf("unable to create directory for file [%s] - %w", rf.Config.Path, err)
+	}
+
+	file, err := os.OpenFile(rf.Config.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, fileMode)
+	if err != nil {
+		return fmt.Errorf("unable to open file [%s] - %w", rf.Config.Path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+
+		return fmt.Errorf("unable to stat file [%s] - %w", rf.Config.Path, err)
+	}
+
+	rf.file, rf.size = file, info.Size()
+
+	// the modification time of a file without a marker is the closest portable approximation of
+	// when its first line was written
+	if rf.size > 0 {
+		if rf.startedAt, err = rf.started(info.ModTime()); err != nil {
+			file.Close()
+			rf.file = nil
+
+			return err
+		}
+	}
+
+	// apply compression and retention to the rotated files, which also finishes the work of a
+	// previous process which stopped while rotating
+	return rf.retain()
+}
+
+// Write writes a batch of lines and syncs the file so that the batch is on disk once it returns.
+// The file is rotated before the batch if it is too old, and before any line which would exceed
+// the maximum size.  It returns the number of lines which were completely written, which is
+// less than the number of lines if an error stopped the batch part way.
+func (rf *RotatingFile) Write(lines [][]byte) (int, error) {
+	if rf.file == nil {
+		if err := rf.Open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if rf.Config.MaxAge > 0 && rf.size > 0 && time.Since(rf.startedAt) >= rf.Config.MaxAge {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	for count, line := range lines {
+		line = append(line[:len(line):len(line)], '\n')
+
+		if rf.size > 0 && rf.size+int64(len(line)) > rf.Config.MaxSize {
+			if err := rf.rotate(); err != nil {
+				return count, err
+			}
+		}
+
+		if rf.size == 0 {
+			if err := rf.start(time.Now()); err != nil {
+				return count, err
+			}
+		}
+
+		written, err := rf.file.Write(line)
+		rf.size += int64(written)
+
+		if err != nil {
+			return count, fmt.Errorf("unable to write to file [%s] - %w", rf.Config.Path, err)
+		}
+	}
+
+	if err := rf.file.Sync(); err != nil {
+		return len(lines), fmt.Errorf("unable to sync file [%s] - %w", rf.Config.Path, err)
+	}
+
+	return len(lines), nil
+}
+
+// Close closes the active file.
+func (rf *RotatingFile) Close() error {
+	if rf.file == nil {
+		return nil
+	}
+
+	err := rf.file.Close()
+	rf.file = nil
+
+	if err != nil {
+		return fmt.Errorf("unable to close file [%s] - %w", rf.Config.Path, err)
+	}
+
+	return nil
+}
+
+// rotate moves the active file aside and opens a new active file.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.file.Sync(); err != nil {
+		return fmt.Errorf("unable to sync file [%s] - %w", rf.Config.Path, err)
+	}
+
+	if err := rf.Close(); err != nil {
+		return err
+	}
+
+	rotated := rf.rotatedPrefix() + time.Now().UTC().Format(fileRotatedTimeFormat) + filepath.Ext(rf.Config.Path)
+	if err := os.Rename(rf.Config.Path, rotated); err != nil {
+		return fmt.Errorf("unable to rotate file [%s] to [%s] - %w", rf.Config.Path, rotated, err)
+	}
+
+	return rf.Open()
+}
+
+// retain compresses rotated files if requested and removes the oldest rotated files which exceed
+// the maximum number of files or total size.
+func (rf *RotatingFile) retain() error {
+	matches, err := filepath.Glob(rf.rotatedPrefix() + "[0-9]*")
+	if err != nil {
+		return fmt.Errorf("unable to list rotated files of [%s] - %w", rf.Config.Path, err)
+	}
+
+	rotated := make([]string, 0, len(matches))
+
+	for _, path := range matches {
+		// a temporary file is left behind if the previous process stopped while compressing, in
+		// which case the rotated file it was compressed from still exists
+		if strings.HasSuffix(path, fileTemporarySuffix) {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("unable to remove temporary file [%s] - %w", path, err)
+			}
+
+			continue
+		}
+
+		rotated = append(rotated, path)
+	}
+
+	if rf.Config.Compress {
+		for i, path := range rotated {
+			if strings.HasSuffix(path, fileCompressedSuffix) {
+				continue
+			}
+
+			if rotated[i], err = compress(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	// remove the oldest files first; rotated files sort by their time of rotation
+	sort.Sort(sort.Reverse(sort.StringSlice(rotated)))
+
+	var total int64
+
+	for count, path := range rotated {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("unable to stat rotated file [%s] - %w", path, err)
+		}
+
+		total += info.Size()
+
+		if (rf.Config.MaxFiles > 0 && count >= rf.Config.MaxFiles) || (rf.Config.MaxTotalBytes > 0 && total > rf.Config.MaxTotalBytes) {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("unable to remove rotated file [%s] - %w", path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// start records the time that the first line is written to the active file in its marker file.
+// The marker is replaced atomically so that a partially written time is never read.
+func (rf *RotatingFile) start(startedAt time.Time) error {
+	marker := rf.startedPath()
+
+	if err := os.WriteFile(marker+fileTemporarySuffix, []byte(startedAt.UTC().Format(time.RFC3339Nano)), fileMode); err != nil {
+		return fmt.Errorf("unable to write start time of file [%s] - %w", rf.Config.Path, err)
+	}
+
+	if err := os.Rename(marker+fileTemporarySuffix, marker); err != nil {
+		return fmt.Errorf("unable to write start time of file [%s] - %w", rf.Config.Path, err)
+	}
+
+	rf.startedAt = startedAt
+
+	return nil
+}
+
+// started returns the time that the first line was written to the active file.  A file without
+// a valid marker, such as one written by a previous version, is given the fallback time, which
+// is then recorded so that it does not change on the next restart.
+func (rf *RotatingFile) started(fallback time.Time) (time.Time, error) {
+	contents, err := os.ReadFile(rf.startedPath())
+	if err != nil && !os.IsNotExist(err) {
+		return time.Time{}, fmt.Errorf("unable to read start time of file [%s] - %w", rf.Config.Path, err)
+	}
+
+	if startedAt, err := time.Parse(time.RFC3339Nano, string(contents)); err == nil {
+		return startedAt, nil
+	}
+
+	if err := rf.start(fallback); err != nil {
+		return time.Time{}, err
+	}
+
+	return fallback, nil
+}
+
+// startedPath returns the path of the marker file which records the start time of the active
+// file.  It is hidden so that it is not mistaken for a rotated file.
+func (rf *RotatingFile) startedPath() string {
+	return filepath.Join(filepath.Dir(rf.Config.Path), fileStartedPrefix+filepath.Base(rf.Config.Path)+fileStartedSuffix)
+}
+
+// rotatedPrefix returns the prefix of the rotated files, which is the active file name without
+// its extension.
+func (rf *RotatingFile) rotatedPrefix() string {
+	return strings.TrimSuffix(rf.Config.Path, filepath.Ext(rf.Config.Path)) + "-"
+}
+
+// compress gzips a file and removes the original once the compressed file is on disk.  It returns
+// the path of the compressed file.
+func compress(path string) (string, error) {
+	compressed := path + fileCompressedSuffix
+
+	source, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to open rotated file [%s] - %w", path, err)
+	}
+	defer source.Close()
+
+	// write to a temporary file so that a partially compressed file is never mistaken for a
+	// complete one
+	destination, err := os.OpenFile(compressed+fileTemporarySuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fileMode)
+	if err != nil {
+		return "", fmt.Errorf("unable to create compressed file [%s] - %w", compressed, err)
+	}
+	defer destination.Close()
+
+	writer := gzip.NewWriter(destination)
+
+	if _, err := io.Copy(writer, source); err != nil {
+		return "", fmt.Errorf("unable to compress rotated file [%s] - %w", path, err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("unable to compress rotated file [%s] - %w", path, err)
+	}
+
+	if err := destination.Sync(); err != nil {
+		return "", fmt.Errorf("unable to sync compressed file [%s] - %w", compressed, err)
+	}
+
+	if err := os.Rename(compressed+fileTemporarySuffix, compressed); err != nil {
+		return "", fmt.Errorf("unable to rename compressed file [%s] - %w", compressed, err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("unable to remove rotated file [%s] - %w", path, err)
+	}
+
+	return compressed, nil
+}
@@ -0,0 +1,222 @@
+package file
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+)
+
+func TestRotatingFile_Write(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		maxFiles    int
+		compress    bool
+		batches     int
+		wantRotated int
+		wantSuffix  string
+	}{
+		{
+			name:        "ensure rotated files are compressed",
+			compress:    true,
+			batches:     3,
+			wantRotated: 2,
+			wantSuffix:  ".ndjson.gz",
+		},
+		{
+			name:        "ensure oldest rotated files are removed over the maximum number of files",
+			maxFiles:    1,
+			compress:    false,
+			batches:     4,
+			wantRotated: 1,
+			wantSuffix:  ".ndjson",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fileConfig := &config.FileConfig{
+				Path:     filepath.Join(t.TempDir(), "service-logs.ndjson"),
+				MaxSize:  10,
+				Compress: tt.compress,
+				MaxFiles: tt.maxFiles,
+			}
+
+			// each batch exceeds the maximum size so the next batch rotates the file
+			for i := 0; i < tt.batches; i++ {
+				rf := &RotatingFile{Config: fileConfig}
+				if _, err := rf.Write([][]byte{[]byte(`{"id":"1"}`)}); err != nil {
+					t.Fatalf("RotatingFile.Write() error = %v", err)
+				}
+
+				if err := rf.Close(); err != nil {
+					t.Fatalf("RotatingFile.Close() error = %v", err)
+				}
+			}
+
+			rotated, err := filepath.Glob(filepath.Join(filepath.Dir(fileConfig.Path), "service-logs-*"))
+			if err != nil {
+				t.Fatalf("unable to list rotated files - %v", err)
+			}
+
+			if len(rotated) != tt.wantRotated {
+				t.Fatalf("RotatingFile.Write() rotated = %v, want %d files", rotated, tt.wantRotated)
+			}
+
+			for _, path := range rotated {
+				if !strings.HasSuffix(path, tt.wantSuffix) {
+					t.Errorf("RotatingFile.Write() rotated = %v, want suffix %s", path, tt.wantSuffix)
+				}
+			}
+
+			if tt.compress {
+				if got := readGzip(t, rotated[0]); got != "{\"id\":\"1\"}\n" {
+					t.Errorf("RotatingFile.Write() compressed = %q, want one line", got)
+				}
+			}
+		})
+	}
+}
+
+func TestRotatingFile_Open(t *testing.T) {
+	t.Parallel()
+
+	fileConfig := &config.FileConfig{
+		Path:    filepath.Join(t.TempDir(), "service-logs.ndjson"),
+		MaxSize: 1024,
+	}
+
+	// a restart appends to the existing file rather than truncating it
+	for _, line := range []string{`{"id":"1"}`, `{"id":"2"}`} {
+		rf := &RotatingFile{Config: fileConfig}
+		if _, err := rf.Write([][]byte{[]byte(line)}); err != nil {
+			t.Fatalf("RotatingFile.Write() error = %v", err)
+		}
+
+		if err := rf.Close(); err != nil {
+			t.Fatalf("RotatingFile.Close() error = %v", err)
+		}
+	}
+
+	contents, err := os.ReadFile(fileConfig.Path)
+	if err != nil {
+		t.Fatalf("unable to read file - %v", err)
+	}
+
+	if want := "{\"id\":\"1\"}\n{\"id\":\"2\"}\n"; string(contents) != want {
+		t.Errorf("RotatingFile.Open() contents = %q, want %q", contents, want)
+	}
+}
+
+func TestRotatingFile_Write_maxAge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		age         func(rf *RotatingFile) error
+		wantRotated int
+	}{
+		{
+			name:        "ensure a young file is not rotated after a restart",
+			age:         func(rf *RotatingFile) error { return nil },
+			wantRotated: 0,
+		},
+		{
+			name: "ensure an old file is rotated after a restart",
+			age: func(rf *RotatingFile) error {
+				return rf.start(time.Now().Add(-2 * time.Hour))
+			},
+			wantRotated: 1,
+		},
+		{
+			name: "ensure an old file without a start time is rotated by its modification time",
+			age: func(rf *RotatingFile) error {
+				if err := os.Remove(rf.startedPath()); err != nil {
+					return err
+				}
+
+				old := time.Now().Add(-2 * time.Hour)
+
+				return os.Chtimes(rf.Config.Path, old, old)
+			},
+			wantRotated: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fileConfig := &config.FileConfig{
+				Path:    filepath.Join(t.TempDir(), "service-logs.ndjson"),
+				MaxSize: 1024,
+				MaxAge:  time.Hour,
+			}
+
+			rf := &RotatingFile{Config: fileConfig}
+			if _, err := rf.Write([][]byte{[]byte(`{"id":"1"}`)}); err != nil {
+				t.Fatalf("RotatingFile.Write() error = %v", err)
+			}
+
+			if err := rf.Close(); err != nil {
+				t.Fatalf("RotatingFile.Close() error = %v", err)
+			}
+
+			if err := tt.age(rf); err != nil {
+				t.Fatalf("unable to age file - %v", err)
+			}
+
+			// a new writer is a restart, which must measure the age from the first line
+			rf = &RotatingFile{Config: fileConfig}
+			if _, err := rf.Write([][]byte{[]byte(`{"id":"2"}`)}); err != nil {
+				t.Fatalf("RotatingFile.Write() error = %v", err)
+			}
+
+			if err := rf.Close(); err != nil {
+				t.Fatalf("RotatingFile.Close() error = %v", err)
+			}
+
+			rotated, err := filepath.Glob(filepath.Join(filepath.Dir(fileConfig.Path), "service-logs-*"))
+			if err != nil {
+				t.Fatalf("unable to list rotated files - %v", err)
+			}
+
+			if len(rotated) != tt.wantRotated {
+				t.Errorf("RotatingFile.Write() rotated = %v, want %d files", rotated, tt.wantRotated)
+			}
+		})
+	}
+}
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unable to open compressed file - %v", err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("unable to read compressed file - %v", err)
+	}
+
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("unable to read compressed file - %v", err)
+	}
+
+	return string(contents)
+}
@@ -0,0 +1,294 @@
+package forward
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	forwardDialTimeout      = 10 * time.Second
+	forwardWriteTimeout     = 10 * time.Second
+	forwardHandshakeTimeout = 10 * time.Second
+	forwardBatchSize        = 1000
+)
+
+var (
+	ErrForwardAck = errors.New("unexpected ack from forward server")
+)
+
+// Forward is a backend which sends service logs to a fluentd or fluent bit aggregator using
+// the forward mode of the fluent forward protocol.  Each message requests an ack from the
+// server, and service logs are only marked as sent once their message has been acked, which
+// gives at least once delivery.
+type Forward struct {
+	Config       *config.ForwardConfig
+	Auth         *config.ForwardAuth
+	TLSConfig    *tls.Config
+	Connection   net.Conn
+	Decoder      *msgpack.Decoder
+	SentMessages []string
+}
+
+func (forward *Forward) Initialize(proc *processor.Processor) (err error) {
+	forward.Config, err = config.GetForwardConfig(proc.Config.ClusterID)
+	if err != nil {
+		return fmt.Errorf("unable to configure forward backend - %w", err)
+	}
+
+	forward.Auth, err = config.GetForwardAuth(proc.KubeClient, proc.Context)
+	if err != nil {
+		return fmt.Errorf("unable to configure forward auth - %w", err)
+	}
+
+	if forward.Config.TLS {
+		forward.TLSConfig, err = getTLSConfig(forward.Config)
+		if err != nil {
+			return fmt.Errorf("unable to set tls config - %w", err)
+		}
+	}
+
+	// connect immediately so that we fail fast on a bad configuration
+	return forward.connect()
+}
+
+func (forward *Forward) Send(proc *processor.Processor, response *poller.Response) error {
+	logs := []*v1.LogEntry{}
+
+	for _, logEntry := range response.Logs {
+		if forward.HasSent(logEntry) {
+			continue
+		}
+
+		logs = append(logs, logEntry)
+	}
+
+	for start := 0; start < len(logs); start += forwardBatchSize {
+		end := start + forwardBatchSize
+		if end > len(logs) {
+			end = len(logs)
+		}
+
+		batch := logs[start:end]
+
+		if err := forward.send(batch); err != nil {
+			forward.Log(log.Err(err).Str("cluster", proc.Config.ClusterID), fmt.Sprintf("batch number [%d] failed to send", start/forwardBatchSize))
+
+			continue
+		}
+
+		forward.Log(
+			log.Info().Str("cluster", proc.Config.ClusterID).Str("tag", forward.Config.Tag).Int("document_count", len(batch)),
+			"sent service logs to forward server",
+		)
+
+		// add the messages to the list of sent messages
+		for _, logEntry := range batch {
+			forward.SentMessages = append(forward.SentMessages, logEntry.ID())
+		}
+	}
+
+	return nil
+}
+
+func (forward *Forward) String() string {
+	return config.DefaultBackendForward
+}
+
+func (forward *Forward) HasSent(message *v1.LogEntry) bool {
+	for i := range forward.SentMessages {
+		if message.ID() == forward.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (forward *Forward) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", forward.String())).Msg(message)
+}
+
+// connect establishes a connection to the forward server, closing any existing connection, and
+// performs the shared key handshake when credentials are configured.
+func (forward *Forward) connect() (err error) {
+	forward.close()
+
+	var connection net.Conn
+
+	if forward.TLSConfig != nil {
+		connection, err = tls.DialWithDialer(&net.Dialer{Timeout: forwardDialTimeout}, "tcp", forward.Config.Address, forward.TLSConfig)
+	} else {
+		connection, err = net.DialTimeout("tcp", forward.Config.Address, forwardDialTimeout)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to connect to forward server [%s] - %w", forward.Config.Address, err)
+	}
+
+	forward.Connection = connection
+	forward.Decoder = msgpack.NewDecoder(connection)
+
+	if forward.Auth == nil {
+		return nil
+	}
+
+	if err := forward.handshake(); err != nil {
+		forward.close()
+
+		return err
+	}
+
+	return nil
+}
+
+// close closes the connection to the forward server.
+func (forward *Forward) close() {
+	if forward.Connection != nil {
+		forward.Connection.Close()
+	}
+
+	forward.Connection = nil
+	forward.Decoder = nil
+}
+
+// handshake performs the shared key handshake, in which the server sends a HELO message, the
+// client responds with a PING message and the server replies with a PONG message.
+func (forward *Forward) handshake() error {
+	if err := forward.Connection.SetDeadline(time.Now().Add(forwardHandshakeTimeout)); err != nil {
+		return fmt.Errorf("unable to set handshake deadline - %w", err)
+	}
+
+	helo := &heloMessage{}
+	if err := forward.Decoder.Decode(helo); err != nil {
+		return fmt.Errorf("unable to read helo message - %w", err)
+	}
+
+	if helo.Type != forwardMessageHelo {
+		return fmt.Errorf("unexpected message type [%s] - %w", helo.Type, ErrForwardHandshake)
+	}
+
+	salt, err := randomString()
+	if err != nil {
+		return fmt.Errorf("unable to generate shared key salt - %w", err)
+	}
+
+	ping := buildPing(helo, forward.Config.Hostname, salt, forward.Auth.SharedKey, forward.Auth.Username, forward.Auth.Password)
+
+	if err := msgpack.NewEncoder(forward.Connection).Encode(ping); err != nil {
+		return fmt.Errorf("unable to write ping message - %w", err)
+	}
+
+	pong := &pongMessage{}
+	if err := forward.Decoder.Decode(pong); err != nil {
+		return fmt.Errorf("unable to read pong message - %w", err)
+	}
+
+	if err := pong.verify(helo, salt, forward.Auth.SharedKey); err != nil {
+		return err
+	}
+
+	return forward.Connection.SetDeadline(time.Time{})
+}
+
+// send writes a batch of service logs to the forward server and waits for the ack.  The
+// connection is re-established once if the send fails, as the server may have closed an idle
+// connection.
+func (forward *Forward) send(logs []*v1.LogEntry) error {
+	message, err := buildMessage(forward.Config.Tag, logs)
+	if err != nil {
+		return err
+	}
+
+	payload, err := msgpack.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("unable to marshal forward message - %w", err)
+	}
+
+	err = forward.write(payload, message.Option.Chunk)
+	if err == nil {
+		return nil
+	}
+
+	forward.Log(log.Warn().Err(err), "forward send failed; reconnecting")
+
+	if connErr := forward.connect(); connErr != nil {
+		return connErr
+	}
+
+	return forward.write(payload, message.Option.Chunk)
+}
+
+// write writes a message to the connection and waits for the ack of its chunk.
+func (forward *Forward) write(payload []byte, chunk string) error {
+	if forward.Connection == nil {
+		return fmt.Errorf("missing connection to forward server [%s] - %w", forward.Config.Address, net.ErrClosed)
+	}
+
+	if err := forward.Connection.SetWriteDeadline(time.Now().Add(forwardWriteTimeout)); err != nil {
+		return fmt.Errorf("unable to set write deadline - %w", err)
+	}
+
+	if _, err := forward.Connection.Write(payload); err != nil {
+		return fmt.Errorf("unable to write to forward server - %w", err)
+	}
+
+	if err := forward.Connection.SetReadDeadline(time.Now().Add(forward.Config.AckTimeout)); err != nil {
+		return fmt.Errorf("unable to set read deadline - %w", err)
+	}
+
+	ack := &forwardAck{}
+	if err := forward.Decoder.Decode(ack); err != nil {
+		// we no longer know where the next ack begins, so the connection is discarded
+		forward.close()
+
+		return fmt.Errorf("unable to read ack for chunk [%s] - %w", chunk, err)
+	}
+
+	if ack.Ack != chunk {
+		forward.close()
+
+		return fmt.Errorf("received ack [%s] for chunk [%s] - %w", ack.Ack, chunk, ErrForwardAck)
+	}
+
+	return nil
+}
+
+// getTLSConfig returns the tls configuration for a forward connection, including the
+// optional certificate authority.
+func getTLSConfig(forwardConfig *config.ForwardConfig) (*tls.Config, error) {
+	host, _, err := net.SplitHostPort(forwardConfig.Address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse address [%s] - %w", forwardConfig.Address, err)
+	}
+
+	//nolint: gosec
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: !forwardConfig.TLSVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if forwardConfig.TLSCA != "" {
+		pool, err := utils.CertPoolFromFile(forwardConfig.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
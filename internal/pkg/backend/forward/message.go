@@ -0,0 +1,255 @@
+package forward
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// NOTE: see https://github.com/fluent/fluentd/wiki/Forward-Protocol-Specification-v1 for the
+// format of the forward protocol messages.
+const (
+	forwardEventTimeExtID  = 0
+	forwardEventTimeLength = 8
+
+	forwardMessageHelo = "HELO"
+	forwardMessagePing = "PING"
+	forwardMessagePong = "PONG"
+)
+
+var (
+	ErrForwardInvalidEventTime = errors.New("invalid event time")
+	ErrForwardHandshake        = errors.New("forward handshake failed")
+)
+
+//nolint:gochecknoinits
+func init() {
+	msgpack.RegisterExt(forwardEventTimeExtID, (*EventTime)(nil))
+}
+
+// EventTime represents the forward protocol event time extension, which carries the
+// timestamp of an event with nanosecond precision.
+type EventTime struct {
+	time.Time
+}
+
+// MarshalMsgpack encodes the event time as seconds and nanoseconds, each as a big endian
+// 32 bit unsigned integer.
+func (eventTime *EventTime) MarshalMsgpack() ([]byte, error) {
+	data := make([]byte, forwardEventTimeLength)
+
+	binary.BigEndian.PutUint32(data[:4], uint32(eventTime.Unix()))
+	binary.BigEndian.PutUint32(data[4:], uint32(eventTime.Nanosecond()))
+
+	return data, nil
+}
+
+// UnmarshalMsgpack decodes the event time from seconds and nanoseconds.
+func (eventTime *EventTime) UnmarshalMsgpack(data []byte) error {
+	if len(data) != forwardEventTimeLength {
+		return fmt.Errorf("length [%d] - %w", len(data), ErrForwardInvalidEventTime)
+	}
+
+	eventTime.Time = time.Unix(
+		int64(binary.BigEndian.Uint32(data[:4])),
+		int64(binary.BigEndian.Uint32(data[4:])),
+	).UTC()
+
+	return nil
+}
+
+// ForwardRecord represents the record of a single event built from a service log message.
+type ForwardRecord struct {
+	ID             string `msgpack:"id"`
+	ClusterID      string `msgpack:"cluster_id"`
+	ExternalID     string `msgpack:"external_id"`
+	SubscriptionID string `msgpack:"subscription_id,omitempty"`
+	Username       string `msgpack:"username,omitempty"`
+	Severity       string `msgpack:"severity"`
+	ServiceName    string `msgpack:"service_name"`
+	EventStreamID  string `msgpack:"event_stream_id"`
+	LogType        string `msgpack:"log_type,omitempty"`
+	InternalOnly   bool   `msgpack:"internal_only"`
+	Summary        string `msgpack:"summary"`
+	Description    string `msgpack:"description,omitempty"`
+}
+
+// forwardEntry represents a single event of a forward mode message.
+type forwardEntry struct {
+	_msgpack struct{} `msgpack:",as_array"` //nolint:unused
+
+	Time   *EventTime
+	Record *ForwardRecord
+}
+
+// forwardOption represents the options of a forward mode message.  The chunk is echoed back
+// by the server in an ack response once the message has been received.
+type forwardOption struct {
+	Size  int    `msgpack:"size"`
+	Chunk string `msgpack:"chunk"`
+}
+
+// forwardMessage represents a forward mode message, which sends multiple events with the
+// same tag in a single message.
+type forwardMessage struct {
+	_msgpack struct{} `msgpack:",as_array"` //nolint:unused
+
+	Tag     string
+	Entries []*forwardEntry
+	Option  *forwardOption
+}
+
+// forwardAck represents the response of the server to a message which requested an ack.
+type forwardAck struct {
+	Ack string `msgpack:"ack"`
+}
+
+// buildRecord builds a forward record from a service log message.
+func buildRecord(logEntry *v1.LogEntry) *ForwardRecord {
+	return &ForwardRecord{
+		ID:             logEntry.ID(),
+		ClusterID:      logEntry.ClusterID(),
+		ExternalID:     logEntry.ClusterUUID(),
+		SubscriptionID: logEntry.SubscriptionID(),
+		Username:       logEntry.Username(),
+		Severity:       string(logEntry.Severity()),
+		ServiceName:    logEntry.ServiceName(),
+		EventStreamID:  logEntry.EventStreamID(),
+		LogType:        string(logEntry.LogType()),
+		InternalOnly:   logEntry.InternalOnly(),
+		Summary:        logEntry.Summary(),
+		Description:    logEntry.Description(),
+	}
+}
+
+// buildMessage builds a forward mode message for a set of service logs.  Each message is
+// given a unique chunk id so that its ack can be matched to it.
+func buildMessage(tag string, logs []*v1.LogEntry) (*forwardMessage, error) {
+	chunk, err := randomString()
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate chunk id - %w", err)
+	}
+
+	message := &forwardMessage{
+		Tag:     tag,
+		Entries: make([]*forwardEntry, len(logs)),
+		Option:  &forwardOption{Size: len(logs), Chunk: chunk},
+	}
+
+	for i, logEntry := range logs {
+		timestamp := logEntry.Timestamp()
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+
+		message.Entries[i] = &forwardEntry{
+			Time:   &EventTime{Time: timestamp},
+			Record: buildRecord(logEntry),
+		}
+	}
+
+	return message, nil
+}
+
+// heloMessage represents the HELO message sent by the server when a shared key handshake
+// is required.
+type heloMessage struct {
+	_msgpack struct{} `msgpack:",as_array"` //nolint:unused
+
+	Type    string
+	Options heloOptions
+}
+
+type heloOptions struct {
+	Nonce     []byte `msgpack:"nonce"`
+	Auth      []byte `msgpack:"auth"`
+	Keepalive bool   `msgpack:"keepalive"`
+}
+
+// pingMessage represents the PING message sent by the client in response to a HELO message.
+type pingMessage struct {
+	_msgpack struct{} `msgpack:",as_array"` //nolint:unused
+
+	Type           string
+	Hostname       string
+	SharedKeySalt  string
+	SharedKeyHash  string
+	Username       string
+	PasswordDigest string
+}
+
+// pongMessage represents the PONG message sent by the server with the result of the handshake.
+type pongMessage struct {
+	_msgpack struct{} `msgpack:",as_array"` //nolint:unused
+
+	Type          string
+	AuthResult    bool
+	Reason        string
+	Hostname      string
+	SharedKeyHash string
+}
+
+// buildPing builds the PING message for a HELO message.  The password digest is only
+// included when the server requests user authentication.
+func buildPing(helo *heloMessage, hostname, salt, sharedKey, username, password string) *pingMessage {
+	ping := &pingMessage{
+		Type:          forwardMessagePing,
+		Hostname:      hostname,
+		SharedKeySalt: salt,
+		SharedKeyHash: digest(salt, hostname, string(helo.Options.Nonce), sharedKey),
+	}
+
+	if len(helo.Options.Auth) > 0 {
+		ping.Username = username
+		ping.PasswordDigest = digest(string(helo.Options.Auth), username, password)
+	}
+
+	return ping
+}
+
+// verify verifies that the PONG message is a successful response to the handshake and that
+// the server knows the shared key.
+func (pong *pongMessage) verify(helo *heloMessage, salt, sharedKey string) error {
+	if pong.Type != forwardMessagePong {
+		return fmt.Errorf("unexpected message type [%s] - %w", pong.Type, ErrForwardHandshake)
+	}
+
+	if !pong.AuthResult {
+		return fmt.Errorf("authentication rejected with reason [%s] - %w", pong.Reason, ErrForwardHandshake)
+	}
+
+	if pong.SharedKeyHash != digest(salt, pong.Hostname, string(helo.Options.Nonce), sharedKey) {
+		return fmt.Errorf("server shared key mismatch - %w", ErrForwardHandshake)
+	}
+
+	return nil
+}
+
+// digest returns the hex encoded sha512 digest of the concatenated values.
+func digest(values ...string) string {
+	hash := sha512.New()
+
+	for _, value := range values {
+		hash.Write([]byte(value))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// randomString returns a random base64 encoded string, used for chunk ids and salts.
+func randomString() (string, error) {
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
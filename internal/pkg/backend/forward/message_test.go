@@ -0,0 +1,109 @@
+package forward
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func Test_buildMessage(t *testing.T) {
+	t.Parallel()
+
+	timestamp := time.Date(2023, 4, 5, 10, 44, 53, 123456789, time.UTC)
+
+	logEntry, err := v1.NewLogEntry().
+		ID("id").
+		ClusterID("cluster").
+		Severity(v1.SeverityError).
+		Summary("Action required").
+		Timestamp(timestamp).
+		Build()
+	if err != nil {
+		t.Fatalf("unable to build log entry - %v", err)
+	}
+
+	message, err := buildMessage("ocm.servicelog.cluster", []*v1.LogEntry{logEntry})
+	if err != nil {
+		t.Fatalf("buildMessage() error = %v", err)
+	}
+
+	payload, err := msgpack.Marshal(message)
+	if err != nil {
+		t.Fatalf("unable to marshal message - %v", err)
+	}
+
+	got := &forwardMessage{}
+	if err := msgpack.Unmarshal(payload, got); err != nil {
+		t.Fatalf("unable to unmarshal message - %v", err)
+	}
+
+	if got.Tag != "ocm.servicelog.cluster" || len(got.Entries) != 1 {
+		t.Fatalf("buildMessage() tag = %v, entries = %v", got.Tag, len(got.Entries))
+	}
+
+	if !got.Entries[0].Time.Equal(timestamp) {
+		t.Errorf("buildMessage() time = %v, want %v", got.Entries[0].Time, timestamp)
+	}
+
+	if got.Entries[0].Record.ID != "id" || got.Entries[0].Record.Severity != "Error" {
+		t.Errorf("buildMessage() record = %+v", got.Entries[0].Record)
+	}
+
+	if got.Option.Size != 1 || got.Option.Chunk == "" || got.Option.Chunk != message.Option.Chunk {
+		t.Errorf("buildMessage() option = %+v, want chunk %v", got.Option, message.Option.Chunk)
+	}
+}
+
+func Test_pongMessage_verify(t *testing.T) {
+	t.Parallel()
+
+	helo := &heloMessage{Type: forwardMessageHelo, Options: heloOptions{Nonce: []byte("nonce")}}
+
+	tests := []struct {
+		name    string
+		pong    *pongMessage
+		wantErr bool
+	}{
+		{
+			name: "ensure pong with matching shared key succeeds",
+			pong: &pongMessage{
+				Type:          forwardMessagePong,
+				AuthResult:    true,
+				Hostname:      "server",
+				SharedKeyHash: digest("salt", "server", "nonce", "key"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "ensure pong with mismatched shared key fails",
+			pong: &pongMessage{
+				Type:          forwardMessagePong,
+				AuthResult:    true,
+				Hostname:      "server",
+				SharedKeyHash: digest("salt", "server", "nonce", "other"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "ensure rejected pong fails",
+			pong: &pongMessage{
+				Type:   forwardMessagePong,
+				Reason: "shared_key mismatch",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.pong.verify(helo, "salt", "key"); (err != nil) != tt.wantErr {
+				t.Errorf("pongMessage.verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package gelf
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+const (
+	gelfDialTimeout  = 10 * time.Second
+	gelfWriteTimeout = 10 * time.Second
+)
+
+// GELF is a backend which sends service logs as gelf messages to a graylog input over udp
+// or tcp.  Messages sent over udp are optionally compressed and are chunked when they exceed
+// the chunk size.  Messages sent over tcp are uncompressed and null byte delimited.
+type GELF struct {
+	Config       *config.GELFConfig
+	Connection   net.Conn
+	SentMessages []string
+}
+
+func (gelf *GELF) Initialize(proc *processor.Processor) (err error) {
+	gelf.Config, err = config.GetGELFConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure gelf backend - %w", err)
+	}
+
+	// connect immediately so that we fail fast on a bad configuration
+	return gelf.connect()
+}
+
+func (gelf *GELF) Send(proc *processor.Processor, response *poller.Response) error {
+	for _, logEntry := range response.Logs {
+		if gelf.HasSent(logEntry) {
+			continue
+		}
+
+		if err := gelf.send(logEntry); err != nil {
+			gelf.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to send gelf message")
+
+			continue
+		}
+
+		// add the message to the list of sent messages
+		gelf.SentMessages = append(gelf.SentMessages, logEntry.ID())
+	}
+
+	return nil
+}
+
+func (gelf *GELF) String() string {
+	return config.DefaultBackendGELF
+}
+
+func (gelf *GELF) HasSent(message *v1.LogEntry) bool {
+	for i := range gelf.SentMessages {
+		if message.ID() == gelf.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (gelf *GELF) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", gelf.String())).Msg(message)
+}
+
+// connect establishes a connection to the graylog input, closing any existing connection.
+func (gelf *GELF) connect() error {
+	if gelf.Connection != nil {
+		gelf.Connection.Close()
+		gelf.Connection = nil
+	}
+
+	connection, err := net.DialTimeout(gelf.Config.Protocol, gelf.Config.Address, gelfDialTimeout)
+	if err != nil {
+		return fmt.Errorf("unable to connect to graylog input [%s://%s] - %w", gelf.Config.Protocol, gelf.Config.Address, err)
+	}
+
+	gelf.Connection = connection
+
+	return nil
+}
+
+// send writes a single service log to the graylog input.  Connections are re-established
+// once if the write fails, as the server may have closed an idle connection.
+func (gelf *GELF) send(logEntry *v1.LogEntry) error {
+	frames, err := gelf.frames(buildMessage(logEntry, gelf.Config.Host))
+	if err != nil {
+		return err
+	}
+
+	err = gelf.write(frames)
+	if err == nil {
+		return nil
+	}
+
+	gelf.Log(log.Warn().Err(err), "gelf write failed; reconnecting")
+
+	if connErr := gelf.connect(); connErr != nil {
+		return connErr
+	}
+
+	return gelf.write(frames)
+}
+
+// frames returns the frames to write for a message based on the protocol.
+func (gelf *GELF) frames(message *GELFMessage) ([][]byte, error) {
+	payload, err := message.payload()
+	if err != nil {
+		return nil, err
+	}
+
+	if gelf.Config.Protocol == config.DefaultBackendGELFProtocolTCP {
+		return [][]byte{append(payload, 0)}, nil
+	}
+
+	if gelf.Config.Compress {
+		if payload, err = compress(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	return chunks(payload, gelf.Config.ChunkSize)
+}
+
+// write writes frames to the connection.
+func (gelf *GELF) write(frames [][]byte) error {
+	if gelf.Connection == nil {
+		return fmt.Errorf("missing connection to graylog input [%s] - %w", gelf.Config.Address, net.ErrClosed)
+	}
+
+	if err := gelf.Connection.SetWriteDeadline(time.Now().Add(gelfWriteTimeout)); err != nil {
+		return fmt.Errorf("unable to set write deadline - %w", err)
+	}
+
+	for _, frame := range frames {
+		if _, err := gelf.Connection.Write(frame); err != nil {
+			return fmt.Errorf("unable to write to graylog input - %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: see https://go2docs.graylog.org/current/getting_in_log_data/gelf.html for the format
+// of a gelf message and its chunking.
+const (
+	gelfVersion = "1.1"
+
+	gelfChunkHeaderLength = 12
+	gelfChunkMaxCount     = 128
+
+	// syslog severity levels as used by the gelf level field
+	gelfLevelCritical      = 2
+	gelfLevelError         = 3
+	gelfLevelWarning       = 4
+	gelfLevelInformational = 6
+	gelfLevelDebug         = 7
+)
+
+var (
+	ErrGELFMessageTooLarge = errors.New("message exceeds the maximum number of chunks")
+
+	gelfChunkMagic = []byte{0x1e, 0x0f}
+)
+
+// GELFMessage represents a gelf 1.1 message built from a service log message.  The service
+// log fields which do not map to a gelf field are sent as additional fields.
+//
+// NOTE: gelf reserves the _id additional field, so the service log id is sent as _log_id.
+type GELFMessage struct {
+	Version        string  `json:"version"`
+	Host           string  `json:"host"`
+	ShortMessage   string  `json:"short_message"`
+	FullMessage    string  `json:"full_message,omitempty"`
+	Timestamp      float64 `json:"timestamp,omitempty"`
+	Level          int     `json:"level"`
+	LogID          string  `json:"_log_id,omitempty"`
+	ClusterID      string  `json:"_cluster_id,omitempty"`
+	ExternalID     string  `json:"_external_id,omitempty"`
+	SubscriptionID string  `json:"_subscription_id,omitempty"`
+	ServiceName    string  `json:"_service_name,omitempty"`
+	EventStreamID  string  `json:"_event_stream_id,omitempty"`
+	Username       string  `json:"_username,omitempty"`
+	Severity       string  `json:"_severity,omitempty"`
+	LogType        string  `json:"_log_type,omitempty"`
+	InternalOnly   bool    `json:"_internal_only"`
+}
+
+// buildMessage builds a gelf message from a service log message.
+func buildMessage(logEntry *v1.LogEntry, host string) *GELFMessage {
+	message := &GELFMessage{
+		Version:        gelfVersion,
+		Host:           host,
+		ShortMessage:   logEntry.Summary(),
+		FullMessage:    logEntry.Description(),
+		Level:          level(logEntry.Severity()),
+		LogID:          logEntry.ID(),
+		ClusterID:      logEntry.ClusterID(),
+		ExternalID:     logEntry.ClusterUUID(),
+		SubscriptionID: logEntry.SubscriptionID(),
+		ServiceName:    logEntry.ServiceName(),
+		EventStreamID:  logEntry.EventStreamID(),
+		Username:       logEntry.Username(),
+		Severity:       string(logEntry.Severity()),
+		LogType:        string(logEntry.LogType()),
+		InternalOnly:   logEntry.InternalOnly(),
+	}
+
+	// short_message is required, so fall back to the service name for messages
+	// without a summary
+	if message.ShortMessage == "" {
+		message.ShortMessage = logEntry.ServiceName()
+	}
+
+	if !logEntry.Timestamp().IsZero() {
+		message.Timestamp = float64(logEntry.Timestamp().UnixMilli()) / 1000
+	}
+
+	return message
+}
+
+// level maps a service log severity to a gelf level.
+func level(severity v1.Severity) int {
+	switch utils.SeverityLevel(severity) {
+	case utils.SeverityLevelCritical:
+		return gelfLevelCritical
+	case utils.SeverityLevelError:
+		return gelfLevelError
+	case utils.SeverityLevelWarning:
+		return gelfLevelWarning
+	case utils.SeverityLevelDebug:
+		return gelfLevelDebug
+	default:
+		return gelfLevelInformational
+	}
+}
+
+// compress returns the gzip compressed payload.
+func compress(payload []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	writer := gzip.NewWriter(&buffer)
+
+	if _, err := writer.Write(payload); err != nil {
+		return nil, fmt.Errorf("unable to compress gelf message - %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("unable to compress gelf message - %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// chunks splits a payload into datagrams no larger than the chunk size.  Payloads which fit
+// in a single datagram are not chunked.  Each chunk is prefixed with the chunk magic bytes, a
+// message id shared by all chunks of the message, the sequence number and the sequence count.
+func chunks(payload []byte, chunkSize int) ([][]byte, error) {
+	if len(payload) <= chunkSize {
+		return [][]byte{payload}, nil
+	}
+
+	dataSize := chunkSize - gelfChunkHeaderLength
+
+	count := (len(payload) + dataSize - 1) / dataSize
+	if count > gelfChunkMaxCount {
+		return nil, fmt.Errorf("message of [%d] bytes requires [%d] chunks - %w", len(payload), count, ErrGELFMessageTooLarge)
+	}
+
+	messageID := make([]byte, 8)
+	if _, err := rand.Read(messageID); err != nil {
+		return nil, fmt.Errorf("unable to generate gelf message id - %w", err)
+	}
+
+	datagrams := make([][]byte, 0, count)
+
+	for sequence := 0; sequence < count; sequence++ {
+		start := sequence * dataSize
+
+		end := start + dataSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+
+		datagram := make([]byte, 0, gelfChunkHeaderLength+end-start)
+		datagram = append(datagram, gelfChunkMagic...)
+		datagram = append(datagram, messageID...)
+		datagram = append(datagram, byte(sequence), byte(count))
+		datagram = append(datagram, payload[start:end]...)
+
+		datagrams = append(datagrams, datagram)
+	}
+
+	return datagrams, nil
+}
+
+// payload returns the json encoded message.
+func (message *GELFMessage) payload() ([]byte, error) {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal gelf message - %w", err)
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,118 @@
+package gelf
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+func Test_buildMessage(t *testing.T) {
+	t.Parallel()
+
+	logEntry, err := v1.NewLogEntry().
+		ID("id").
+		ClusterID("cluster").
+		Severity(v1.SeverityWarning).
+		ServiceName("SREManualAction").
+		Summary("Action required").
+		Description("Full description").
+		Timestamp(time.Date(2023, 4, 5, 10, 44, 53, 250000000, time.UTC)).
+		Build()
+	if err != nil {
+		t.Fatalf("unable to build log entry - %v", err)
+	}
+
+	payload, err := buildMessage(logEntry, "host").payload()
+	if err != nil {
+		t.Fatalf("unable to build payload - %v", err)
+	}
+
+	want := `{"version":"1.1","host":"host","short_message":"Action required","full_message":"Full description",` +
+		`"timestamp":1680691493.25,"level":4,"_log_id":"id","_cluster_id":"cluster","_service_name":"SREManualAction",` +
+		`"_severity":"Warning","_internal_only":false}`
+
+	if string(payload) != want {
+		t.Errorf("buildMessage() = %v, want %v", string(payload), want)
+	}
+}
+
+func Test_chunks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		size       int
+		chunkSize  int
+		wantChunks int
+		wantErr    bool
+	}{
+		{
+			name:       "ensure small payload is not chunked",
+			size:       100,
+			chunkSize:  100,
+			wantChunks: 1,
+		},
+		{
+			name:       "ensure large payload is chunked",
+			size:       200,
+			chunkSize:  100,
+			wantChunks: 3,
+		},
+		{
+			name:      "ensure payload requiring too many chunks returns error",
+			size:      129 * 88,
+			chunkSize: 100,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			payload := bytes.Repeat([]byte("a"), tt.size)
+
+			got, err := chunks(payload, tt.chunkSize)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("chunks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) != tt.wantChunks {
+				t.Fatalf("chunks() count = %v, want %v", len(got), tt.wantChunks)
+			}
+
+			if tt.wantChunks == 1 {
+				return
+			}
+
+			reassembled := []byte{}
+
+			for sequence, chunk := range got {
+				if len(chunk) > tt.chunkSize {
+					t.Errorf("chunks() chunk size = %v, want at most %v", len(chunk), tt.chunkSize)
+				}
+
+				if !bytes.Equal(chunk[:2], gelfChunkMagic) || !bytes.Equal(chunk[2:10], got[0][2:10]) {
+					t.Errorf("chunks() invalid chunk header %v", chunk[:gelfChunkHeaderLength])
+				}
+
+				if int(chunk[10]) != sequence || int(chunk[11]) != tt.wantChunks {
+					t.Errorf("chunks() sequence = %v/%v, want %v/%v", chunk[10], chunk[11], sequence, tt.wantChunks)
+				}
+
+				reassembled = append(reassembled, chunk[gelfChunkHeaderLength:]...)
+			}
+
+			if !bytes.Equal(reassembled, payload) {
+				t.Errorf("chunks() reassembled payload does not match")
+			}
+		})
+	}
+}
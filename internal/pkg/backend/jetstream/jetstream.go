@@ -0,0 +1,170 @@
+package jetstream
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	natsConnectionName = "ocm-log-forwarder"
+
+	// subjectTokenEmpty replaces an empty placeholder value, as a subject may not contain an
+	// empty token.
+	subjectTokenEmpty = "unknown"
+)
+
+// JetStream is a backend which publishes each service log as a message to a nats jetstream
+// stream.  The service log id is used as the message id, so that jetstream discards duplicates
+// which are published within the duplicate window of the stream.
+type JetStream struct {
+	Connection   *nats.Conn
+	Stream       nats.JetStreamContext
+	Config       *config.NATSConfig
+	SentMessages []string
+}
+
+func (js *JetStream) Initialize(proc *processor.Processor) error {
+	natsConfig, err := config.GetNATSConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure nats backend - %w", err)
+	}
+
+	js.Config = natsConfig
+
+	// reconnect indefinitely, as the connection lives for the lifetime of the forwarder
+	options := []nats.Option{nats.Name(natsConnectionName), nats.MaxReconnects(-1)}
+
+	switch js.Config.AuthType {
+	case config.DefaultBackendAuthTypeBasic:
+		username, password, err := config.GetNATSBasicAuth(proc.KubeClient, proc.Context)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve nats credentials - %w", err)
+		}
+
+		options = append(options, nats.UserInfo(username, password))
+	case config.DefaultBackendAuthTypeBearer:
+		token, err := config.GetNATSToken(proc.KubeClient, proc.Context)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve nats credentials - %w", err)
+		}
+
+		options = append(options, nats.Token(token))
+	}
+
+	if js.Config.TLSCA != "" {
+		options = append(options, nats.RootCAs(js.Config.TLSCA))
+	}
+
+	js.Connection, err = nats.Connect(js.Config.URL, options...)
+	if err != nil {
+		return fmt.Errorf("unable to connect to nats [%s] - %w", js.Config.URL, err)
+	}
+
+	js.Stream, err = js.Connection.JetStream()
+	if err != nil {
+		return fmt.Errorf("unable to create jetstream context - %w", err)
+	}
+
+	return nil
+}
+
+func (js *JetStream) Send(proc *processor.Processor, response *poller.Response) error {
+	var published, duplicates int
+
+	for _, logEntry := range response.Logs {
+		if js.HasSent(logEntry) {
+			continue
+		}
+
+		data, err := utils.MarshalLogEntry(logEntry)
+		if err != nil {
+			js.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to build message")
+
+			continue
+		}
+
+		msg := nats.NewMsg(subject(js.Config.Subject, proc.Config.ClusterID, logEntry))
+		msg.Data = data
+
+		// publishing waits for the stream to acknowledge that the message is stored
+		ack, err := js.Stream.PublishMsg(msg, nats.MsgId(logEntry.ID()), nats.AckWait(js.Config.AckTimeout))
+		if err != nil {
+			js.Log(
+				log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()).Str("subject", msg.Subject),
+				"failed to publish message",
+			)
+
+			continue
+		}
+
+		if ack.Duplicate {
+			duplicates++
+		}
+
+		js.SentMessages = append(js.SentMessages, logEntry.ID())
+		published++
+	}
+
+	if published > 0 {
+		js.Log(
+			log.Info().Str("cluster", proc.Config.ClusterID).Int("message_count", published).Int("duplicate_count", duplicates),
+			"published service logs to jetstream",
+		)
+	}
+
+	return nil
+}
+
+func (js *JetStream) String() string {
+	return config.DefaultBackendNATS
+}
+
+func (js *JetStream) HasSent(message *v1.LogEntry) bool {
+	for i := range js.SentMessages {
+		if message.ID() == js.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (js *JetStream) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", js.String())).Msg(message)
+}
+
+// subject resolves the subject template for a service log.  Characters which separate tokens or
+// are wildcards within a subject are replaced in the placeholder values, so that a value always
+// resolves to a single token.
+func subject(template, clusterID string, logEntry *v1.LogEntry) string {
+	fields := utils.TemplateFields(clusterID, logEntry)
+
+	for key, value := range fields {
+		value = strings.Map(func(character rune) rune {
+			switch character {
+			case '.', '*', '>', ' ', '\t', '\r', '\n':
+				return '_'
+			default:
+				return character
+			}
+		}, value)
+
+		if value == "" {
+			value = subjectTokenEmpty
+		}
+
+		fields[key] = value
+	}
+
+	return utils.ResolveTemplate(template, fields)
+}
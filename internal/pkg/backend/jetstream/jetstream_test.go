@@ -0,0 +1,324 @@
+package jetstream
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+const (
+	standInInfo       = `INFO {"server_id":"stand-in","version":"2.2.0","proto":1,"headers":true,"max_payload":1048576}` + "\r\n"
+	standInStream     = "ocm"
+	standInAccountAPI = "$JS.API.INFO"
+)
+
+// jetStreamStandIn is a local stand-in for a nats server with jetstream enabled.  It speaks
+// enough of the nats protocol to acknowledge published messages, discards messages whose
+// 'Nats-Msg-Id' it has already stored as duplicates, and can lose the acknowledgement of the
+// first messages it stores, as if the acknowledgement did not arrive within the ack wait.
+type jetStreamStandIn struct {
+	listener net.Listener
+	lostAcks int
+
+	mutex    sync.Mutex
+	stored   []string
+	messages map[string]bool
+}
+
+func newJetStreamStandIn(t *testing.T, lostAcks int) *jetStreamStandIn {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen - %v", err)
+	}
+
+	standIn := &jetStreamStandIn{listener: listener, lostAcks: lostAcks, messages: map[string]bool{}}
+	t.Cleanup(func() { listener.Close() })
+
+	go standIn.serve()
+
+	return standIn
+}
+
+// connect connects a client to the stand-in.
+func (standIn *jetStreamStandIn) connect(t *testing.T) (*nats.Conn, nats.JetStreamContext) {
+	t.Helper()
+
+	connection, err := nats.Connect("nats://"+standIn.listener.Addr().String(), nats.Name(natsConnectionName))
+	if err != nil {
+		t.Fatalf("unable to connect to stand-in - %v", err)
+	}
+
+	t.Cleanup(connection.Close)
+
+	stream, err := connection.JetStream()
+	if err != nil {
+		t.Fatalf("unable to create jetstream context - %v", err)
+	}
+
+	return connection, stream
+}
+
+func (standIn *jetStreamStandIn) serve() {
+	for {
+		conn, err := standIn.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go standIn.handle(conn)
+	}
+}
+
+// handle handles the protocol of a client connection.  Each subscription is assumed to be the
+// reply inbox of the client, which is the only subscription that a publishing client makes.
+func (standIn *jetStreamStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	subscriptions := map[string]string{}
+
+	if _, err := io.WriteString(conn, standInInfo); err != nil {
+		return
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "PING":
+			_, err = io.WriteString(conn, "PONG\r\n")
+		case "SUB":
+			subscriptions[strings.TrimSuffix(fields[1], "*")] = fields[len(fields)-1]
+		case "PUB", "HPUB":
+			var headers, data []byte
+
+			if headers, data, err = readPayload(reader, fields); err != nil {
+				return
+			}
+
+			if response := standIn.respond(fields[1], headers, data); response != "" {
+				err = reply(conn, subscriptions, fields[2], response)
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// respond returns the response to a message published to a subject, or an empty response if
+// the acknowledgement is lost.
+func (standIn *jetStreamStandIn) respond(subject string, headers, data []byte) string {
+	if subject == standInAccountAPI {
+		return `{"type":"io.nats.jetstream.api.v1.account_info_response"}`
+	}
+
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	id := nats.Header{}
+	for _, header := range strings.Split(string(headers), "\r\n") {
+		if key, value, ok := strings.Cut(header, ":"); ok {
+			id.Add(key, strings.TrimSpace(value))
+		}
+	}
+
+	msgID := id.Get(nats.MsgIdHdr)
+
+	duplicate := msgID != "" && standIn.messages[msgID]
+	if !duplicate {
+		standIn.messages[msgID] = true
+		standIn.stored = append(standIn.stored, string(data))
+	}
+
+	if standIn.lostAcks > 0 {
+		standIn.lostAcks--
+
+		return ""
+	}
+
+	return fmt.Sprintf(`{"stream":%q,"seq":%d,"duplicate":%t}`, standInStream, len(standIn.stored), duplicate)
+}
+
+// readPayload reads the headers and data of a PUB or HPUB message.
+func readPayload(reader *bufio.Reader, fields []string) ([]byte, []byte, error) {
+	total, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid message size - %w", err)
+	}
+
+	var headerSize int
+
+	if strings.EqualFold(fields[0], "HPUB") {
+		if headerSize, err = strconv.Atoi(fields[len(fields)-2]); err != nil {
+			return nil, nil, fmt.Errorf("invalid header size - %w", err)
+		}
+	}
+
+	payload := make([]byte, total+len("\r\n"))
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, nil, fmt.Errorf("unable to read message - %w", err)
+	}
+
+	return payload[:headerSize], payload[headerSize:total], nil
+}
+
+// reply sends a response to the reply subject of a message.
+func reply(conn net.Conn, subscriptions map[string]string, subject, response string) error {
+	for prefix, sid := range subscriptions {
+		if strings.HasPrefix(subject, prefix) {
+			_, err := fmt.Fprintf(conn, "MSG %s %s %d\r\n%s\r\n", subject, sid, len(response), response)
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestJetStream_Send(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		lostAcks       int
+		wantFirstSent  []string
+		wantSecondSent []string
+		wantStored     int
+	}{
+		{
+			name:           "ensure messages published again after a restart are discarded as duplicates",
+			wantFirstSent:  []string{"1", "2"},
+			wantSecondSent: []string{"1", "2", "3"},
+			wantStored:     3,
+		},
+		{
+			name:           "ensure messages are not sent until they are acknowledged",
+			lostAcks:       1,
+			wantFirstSent:  []string{"2"},
+			wantSecondSent: []string{"1", "2", "3"},
+			wantStored:     3,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			standIn := newJetStreamStandIn(t, tt.lostAcks)
+			connection, stream := standIn.connect(t)
+
+			natsConfig := &config.NATSConfig{Subject: "ocm.service_logs.{cluster_id}", AckTimeout: 200 * time.Millisecond}
+			proc := &processor.Processor{Config: &config.Config{ClusterID: "test"}, Context: context.Background()}
+
+			logs := []*v1.LogEntry{}
+
+			for _, id := range []string{"1", "2", "3"} {
+				logEntry, err := v1.NewLogEntry().ID(id).Severity(v1.SeverityError).Summary("summary").Build()
+				if err != nil {
+					t.Fatalf("unable to build log entry - %v", err)
+				}
+
+				logs = append(logs, logEntry)
+			}
+
+			js := &JetStream{Connection: connection, Stream: stream, Config: natsConfig}
+			if err := js.Send(proc, &poller.Response{Logs: logs[:2]}); err != nil {
+				t.Fatalf("JetStream.Send() error = %v", err)
+			}
+
+			if strings.Join(js.SentMessages, ",") != strings.Join(tt.wantFirstSent, ",") {
+				t.Errorf("JetStream.Send() sent = %v, want %v", js.SentMessages, tt.wantFirstSent)
+			}
+
+			// a restart forgets the sent messages, so every service log is published again
+			restarted := &JetStream{Connection: connection, Stream: stream, Config: natsConfig}
+			if err := restarted.Send(proc, &poller.Response{Logs: logs}); err != nil {
+				t.Fatalf("JetStream.Send() after restart error = %v", err)
+			}
+
+			if strings.Join(restarted.SentMessages, ",") != strings.Join(tt.wantSecondSent, ",") {
+				t.Errorf("JetStream.Send() after restart sent = %v, want %v", restarted.SentMessages, tt.wantSecondSent)
+			}
+
+			standIn.mutex.Lock()
+			defer standIn.mutex.Unlock()
+
+			if len(standIn.stored) != tt.wantStored {
+				t.Errorf("JetStream.Send() stored = %d messages, want %d", len(standIn.stored), tt.wantStored)
+			}
+		})
+	}
+}
+
+func Test_subject(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		template    string
+		serviceName string
+		want        string
+	}{
+		{
+			name:        "ensure placeholders are resolved",
+			template:    "ocm.service_logs.{cluster_id}.{severity}",
+			serviceName: "SREManualAction",
+			want:        "ocm.service_logs.test.Error",
+		},
+		{
+			name:        "ensure separators and wildcards in values are replaced",
+			template:    "ocm.{service_name}",
+			serviceName: "SRE Manual.Action>*",
+			want:        "ocm.SRE_Manual_Action__",
+		},
+		{
+			name:        "ensure empty values are replaced",
+			template:    "ocm.{service_name}.{cluster_id}",
+			serviceName: "",
+			want:        "ocm.unknown.test",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().ID("1").Severity(v1.SeverityError).ServiceName(tt.serviceName).Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			if got := subject(tt.template, "test", logEntry); got != tt.want {
+				t.Errorf("subject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
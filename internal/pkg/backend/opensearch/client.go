@@ -0,0 +1,150 @@
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	openSearchRequestTimeout = 30 * time.Second
+	openSearchContentType    = "application/json"
+	openSearchBulkType       = "application/x-ndjson"
+
+	// the amazon opensearch service requires the payload hash as a header when signing requests
+	openSearchContentSHA256Header = "X-Amz-Content-Sha256"
+)
+
+var (
+	ErrOpenSearchResponse = errors.New("unexpected response from opensearch")
+)
+
+// OpenSearchError represents an unsuccessful response returned by opensearch.
+type OpenSearchError struct {
+	StatusCode int
+	Body       string
+}
+
+func (osErr *OpenSearchError) Error() string {
+	return fmt.Sprintf("status [%d] body [%s]", osErr.StatusCode, osErr.Body)
+}
+
+func (osErr *OpenSearchError) Unwrap() error {
+	return ErrOpenSearchResponse
+}
+
+// OpenSearchClient is a minimal client for the opensearch rest api.  Requests are authenticated
+// with basic auth or signed with aws signature version 4 when aws credentials are provided.  It
+// does not check the version of the cluster, so it works with amazon opensearch service and
+// opensearch serverless.
+type OpenSearchClient struct {
+	URL      string
+	Username string
+	Password string
+	Region   string
+	Service  string
+	AWS      *aws.Config
+	HTTP     *http.Client
+	Signer   *v4.Signer
+}
+
+// Do performs a request against the opensearch rest api and returns the response body.
+func (client *OpenSearchClient) Do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, method, client.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create [%s %s] request - %w", method, path, err)
+	}
+
+	request.Header.Set("Content-Type", contentType)
+
+	if err := client.authorize(ctx, request, body); err != nil {
+		return nil, fmt.Errorf("unable to authorize [%s %s] request - %w", method, path, err)
+	}
+
+	response, err := client.HTTP.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send [%s %s] request - %w", method, path, err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read [%s %s] response - %w", method, path, err)
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf(
+			"error in [%s %s] request - %w",
+			method,
+			path,
+			&OpenSearchError{StatusCode: response.StatusCode, Body: string(responseBody)},
+		)
+	}
+
+	return responseBody, nil
+}
+
+// authorize adds the authentication to a request.  Requests are signed when aws credentials
+// are configured, otherwise basic auth is used when a username is configured.
+func (client *OpenSearchClient) authorize(ctx context.Context, request *http.Request, body []byte) error {
+	if client.AWS == nil {
+		if client.Username != "" {
+			request.SetBasicAuth(client.Username, client.Password)
+		}
+
+		return nil
+	}
+
+	credentials, err := client.AWS.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve aws credentials - %w", err)
+	}
+
+	payloadHash := sha256.Sum256(body)
+	payload := hex.EncodeToString(payloadHash[:])
+
+	request.Header.Set(openSearchContentSHA256Header, payload)
+
+	if err := client.Signer.SignHTTP(ctx, credentials, request, payload, client.Service, client.Region, time.Now()); err != nil {
+		return fmt.Errorf("unable to sign request - %w", err)
+	}
+
+	return nil
+}
+
+// getHTTPClient returns the http client for the opensearch url, including the optional
+// certificate authority.
+func getHTTPClient(openSearchConfig *config.OpenSearchConfig) (*http.Client, error) {
+	//nolint: gosec
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !openSearchConfig.TLSVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if openSearchConfig.TLSCA != "" {
+		pool, err := utils.CertPoolFromFile(openSearchConfig.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Timeout: openSearchRequestTimeout, Transport: transport}, nil
+}
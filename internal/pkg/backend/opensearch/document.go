@@ -0,0 +1,84 @@
+package opensearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+// OpenSearchDocument represents the document that gets indexed in opensearch.  The fields match
+// the documents of the elasticsearch backend so that dashboards work with either.
+type OpenSearchDocument struct {
+	id          string
+	ClusterID   string `json:"cluster_id"`
+	ExternalID  string `json:"external_id"`
+	Username    string `json:"username"`
+	Severity    string `json:"severity"`
+	ServiceName string `json:"service_name"`
+	EventID     string `json:"event_stream_id"`
+	Message     string `json:"message"`
+	Timestamp   string `json:"@timestamp"`
+}
+
+type bulkAction struct {
+	Index *bulkActionMetadata `json:"index"`
+}
+
+type bulkActionMetadata struct {
+	Index string `json:"_index"`
+	ID    string `json:"_id"`
+}
+
+type bulkResponse struct {
+	Errors bool                           `json:"errors"`
+	Items  []map[string]*bulkResponseItem `json:"items"`
+}
+
+type bulkResponseItem struct {
+	ID     string             `json:"_id"`
+	Status int                `json:"status"`
+	Error  *bulkResponseError `json:"error"`
+}
+
+type bulkResponseError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+// buildDocument builds an opensearch document from a service log message.
+func buildDocument(logEntry *v1.LogEntry) *OpenSearchDocument {
+	return &OpenSearchDocument{
+		id:          logEntry.ID(),
+		ClusterID:   logEntry.ClusterID(),
+		ExternalID:  logEntry.ClusterUUID(),
+		Username:    logEntry.Username(),
+		Severity:    string(logEntry.Severity()),
+		EventID:     logEntry.EventStreamID(),
+		ServiceName: logEntry.ServiceName(),
+		Message:     logEntry.Summary(),
+		Timestamp:   logEntry.Timestamp().UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// buildBulkBody builds the newline delimited body of a bulk request which indexes each document
+// into the index.
+func buildBulkBody(index string, documents []*OpenSearchDocument) ([]byte, error) {
+	var body bytes.Buffer
+
+	encoder := json.NewEncoder(&body)
+
+	for _, document := range documents {
+		if err := encoder.Encode(&bulkAction{Index: &bulkActionMetadata{Index: index, ID: document.id}}); err != nil {
+			return nil, fmt.Errorf("unable to encode bulk action for document [%s] - %w", document.id, err)
+		}
+
+		if err := encoder.Encode(document); err != nil {
+			return nil, fmt.Errorf("unable to encode document [%s] - %w", document.id, err)
+		}
+	}
+
+	return body.Bytes(), nil
+}
@@ -0,0 +1,288 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	openSearchBatchSize = 100
+	openSearchSeedSize  = 1000
+)
+
+// OpenSearch is a backend which indexes service logs in opensearch, including the amazon
+// opensearch service and opensearch serverless.
+type OpenSearch struct {
+	Client       *OpenSearchClient
+	Config       *config.OpenSearchConfig
+	SentMessages []string
+}
+
+type searchRequest struct {
+	Query  map[string]interface{}   `json:"query"`
+	Sort   []map[string]interface{} `json:"sort,omitempty"`
+	Size   int                      `json:"size"`
+	Source bool                     `json:"_source"`
+}
+
+type searchResponse struct {
+	Hits struct {
+		Hits []struct {
+			ID string `json:"_id"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+func (ops *OpenSearch) Initialize(proc *processor.Processor) (err error) {
+	ops.Config, err = config.GetOpenSearchConfig(proc.Config.ClusterID)
+	if err != nil {
+		return fmt.Errorf("unable to configure opensearch backend - %w", err)
+	}
+
+	httpClient, err := getHTTPClient(ops.Config)
+	if err != nil {
+		return fmt.Errorf("unable to configure opensearch client - %w", err)
+	}
+
+	ops.Client = &OpenSearchClient{
+		URL:     ops.Config.URL,
+		Region:  ops.Config.Region,
+		Service: ops.Config.Service,
+		HTTP:    httpClient,
+		Signer:  v4.NewSigner(),
+	}
+
+	switch ops.Config.AuthType {
+	case config.DefaultBackendAuthTypeBasic:
+		ops.Client.Username, ops.Client.Password, err = config.GetOpenSearchBasicAuth(proc.KubeClient, proc.Context)
+		if err != nil {
+			return fmt.Errorf("unable to configure basic auth type - %w", err)
+		}
+	case config.DefaultBackendAuthTypeIRSA, config.DefaultBackendAuthTypeStatic:
+		var accessKeyID, secretAccessKey string
+
+		// retrieve static credentials if requested, otherwise we fall back to the default
+		// credential chain which includes irsa
+		if ops.Config.AuthType == config.DefaultBackendAuthTypeStatic {
+			accessKeyID, secretAccessKey, err = config.GetOpenSearchStaticCredentials(proc.KubeClient, proc.Context)
+			if err != nil {
+				return fmt.Errorf("unable to configure static auth type - %w", err)
+			}
+		}
+
+		awsConfig, err := utils.GetAWSConfig(proc.Context, ops.Config.Region, accessKeyID, secretAccessKey)
+		if err != nil {
+			return fmt.Errorf("unable to configure aws - %w", err)
+		}
+
+		ops.Client.AWS = &awsConfig
+	}
+
+	return nil
+}
+
+func (ops *OpenSearch) Send(proc *processor.Processor, response *poller.Response) error {
+	documents := []*OpenSearchDocument{}
+
+	for _, logEntry := range response.Logs {
+		if ops.HasSent(logEntry) {
+			continue
+		}
+
+		documents = append(documents, buildDocument(logEntry))
+	}
+
+	// we want to do this serially so we do not overwhelm the opensearch api
+	for batchCount := 0; len(documents) > 0; batchCount++ {
+		size := openSearchBatchSize
+		if size > len(documents) {
+			size = len(documents)
+		}
+
+		if err := ops.bulk(proc, documents[:size]); err != nil {
+			ops.Log(log.Err(err).Str("cluster", proc.Config.ClusterID), fmt.Sprintf("batch number [%d] failed to send", batchCount))
+		}
+
+		documents = documents[size:]
+	}
+
+	return nil
+}
+
+// Recent returns the ids of the most recently indexed documents for the cluster.
+func (ops *OpenSearch) Recent(proc *processor.Processor) ([]string, error) {
+	return ops.search(proc, &searchRequest{
+		Query: map[string]interface{}{
+			"match_phrase": map[string]interface{}{"cluster_id": proc.Config.ClusterID},
+		},
+		Sort: []map[string]interface{}{
+			{"@timestamp": map[string]interface{}{"order": "desc", "unmapped_type": "date"}},
+		},
+		Size: openSearchSeedSize,
+	})
+}
+
+// Present returns the ids which are already indexed.
+func (ops *OpenSearch) Present(proc *processor.Processor, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return []string{}, nil
+	}
+
+	return ops.search(proc, &searchRequest{
+		Query: map[string]interface{}{
+			"ids": map[string]interface{}{"values": ids},
+		},
+		Size: len(ids),
+	})
+}
+
+// MarkSent stores service log ids as sent.
+func (ops *OpenSearch) MarkSent(ids ...string) {
+	ops.SentMessages = append(ops.SentMessages, ids...)
+}
+
+func (ops *OpenSearch) String() string {
+	return config.DefaultBackendOpenSearch
+}
+
+func (ops *OpenSearch) HasSent(message *v1.LogEntry) bool {
+	for i := range ops.SentMessages {
+		if message.ID() == ops.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ops *OpenSearch) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", ops.String())).Msg(message)
+}
+
+// bulk indexes a batch of documents.  Documents which are rejected are left unsent and are
+// attempted again on the next poll.
+func (ops *OpenSearch) bulk(proc *processor.Processor, documents []*OpenSearchDocument) error {
+	documents = ops.unindexedDocuments(proc, documents)
+	if len(documents) == 0 {
+		return nil
+	}
+
+	body, err := buildBulkBody(ops.Config.Index, documents)
+	if err != nil {
+		return err
+	}
+
+	ops.Log(
+		log.Info().Str("cluster", proc.Config.ClusterID).Str("index", ops.Config.Index).Int("document_count", len(documents)),
+		"sending documents to opensearch",
+	)
+
+	responseBody, err := ops.Client.Do(proc.Context, http.MethodPost, "/_bulk", openSearchBulkType, body)
+	if err != nil {
+		return err
+	}
+
+	response := &bulkResponse{}
+	if err := json.Unmarshal(responseBody, response); err != nil {
+		return fmt.Errorf("unable to unmarshal bulk response - %w", err)
+	}
+
+	for _, item := range response.Items {
+		for _, result := range item {
+			switch {
+			// a conflict means the document already exists, so we treat it as sent
+			case result.Status >= http.StatusOK && result.Status < http.StatusMultipleChoices, result.Status == http.StatusConflict:
+				ops.SentMessages = append(ops.SentMessages, result.ID)
+
+				ops.Log(log.Debug().Str("message_id", result.ID), "succeeded opensearch id")
+			case result.Error != nil:
+				ops.Log(
+					log.Error().
+						Str("message_id", result.ID).
+						Int("status_code", result.Status).
+						Str("type", result.Error.Type).
+						Str("reason", result.Error.Reason),
+					"error in opensearch request",
+				)
+			default:
+				ops.Log(log.Error().Str("message_id", result.ID).Int("status_code", result.Status), "error in opensearch request")
+			}
+		}
+	}
+
+	return nil
+}
+
+// unindexedDocuments returns the documents which are not already indexed, which may be the case
+// after a restart.  Indexed documents are marked as sent.  If the lookup fails, all documents are
+// returned so that they are sent as they would be without a lookup.
+func (ops *OpenSearch) unindexedDocuments(proc *processor.Processor, documents []*OpenSearchDocument) []*OpenSearchDocument {
+	ids := make([]string, len(documents))
+	for i := range documents {
+		ids[i] = documents[i].id
+	}
+
+	present, err := ops.Present(proc, ids)
+	if err != nil {
+		ops.Log(log.Warn().Err(err).Str("cluster", proc.Config.ClusterID), "unable to look up existing documents")
+
+		return documents
+	}
+
+	indexed := make(map[string]bool, len(present))
+	for _, id := range present {
+		indexed[id] = true
+	}
+
+	ops.MarkSent(present...)
+
+	unindexed := make([]*OpenSearchDocument, 0, len(documents))
+
+	for _, document := range documents {
+		if !indexed[document.id] {
+			unindexed = append(unindexed, document)
+		}
+	}
+
+	return unindexed
+}
+
+// search searches the index and returns the ids of the hits.  Missing indices are ignored so
+// that searching before the first document is indexed does not fail.
+func (ops *OpenSearch) search(proc *processor.Processor, request *searchRequest) ([]string, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal search request - %w", err)
+	}
+
+	path := fmt.Sprintf("/%s/_search?ignore_unavailable=true", url.PathEscape(ops.Config.Index))
+
+	responseBody, err := ops.Client.Do(proc.Context, http.MethodPost, path, openSearchContentType, body)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &searchResponse{}
+	if err := json.Unmarshal(responseBody, response); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal search response - %w", err)
+	}
+
+	ids := make([]string, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		ids = append(ids, hit.ID)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,117 @@
+package opensearch
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+func TestOpenSearch_Send(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		aws           *aws.Config
+		wantAuthorize string
+	}{
+		{
+			name:          "ensure requests use basic auth",
+			wantAuthorize: "Basic ",
+		},
+		{
+			name: "ensure requests are signed with aws credentials",
+			aws: &aws.Config{
+				Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+			},
+			wantAuthorize: "AWS4-HMAC-SHA256 Credential=AKID/",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var bulkLines []string
+
+			// a stand-in for opensearch where document 1 is already indexed
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Authorization"); !strings.HasPrefix(got, tt.wantAuthorize) {
+					t.Errorf("Authorization = %v, want prefix %v", got, tt.wantAuthorize)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+
+				switch r.URL.Path {
+				case "/ocm_service_logs/_search":
+					_, _ = w.Write([]byte(`{"hits":{"hits":[{"_id":"1"}]}}`))
+				case "/_bulk":
+					scanner := bufio.NewScanner(r.Body)
+					for scanner.Scan() {
+						bulkLines = append(bulkLines, scanner.Text())
+					}
+
+					_, _ = w.Write([]byte(`{"errors":true,"items":[{"index":{"_id":"2","status":201}},` +
+						`{"index":{"_id":"3","status":400,"error":{"type":"mapper_parsing_exception","reason":"bad"}}}]}`))
+				default:
+					t.Errorf("unexpected request path [%s]", r.URL.Path)
+				}
+			}))
+			defer server.Close()
+
+			ops := &OpenSearch{
+				Config: &config.OpenSearchConfig{Index: "ocm_service_logs"},
+				Client: &OpenSearchClient{
+					URL:      server.URL,
+					Username: "user",
+					Password: "pass",
+					Region:   "us-east-1",
+					Service:  config.DefaultBackendOpenSearchServiceManaged,
+					AWS:      tt.aws,
+					HTTP:     server.Client(),
+					Signer:   v4.NewSigner(),
+				},
+			}
+
+			response := &poller.Response{}
+
+			for _, id := range []string{"1", "2", "3"} {
+				logEntry, err := v1.NewLogEntry().ID(id).ClusterID("cluster").Timestamp(time.Now()).Build()
+				if err != nil {
+					t.Fatalf("unable to build log entry - %v", err)
+				}
+
+				response.Logs = append(response.Logs, logEntry)
+			}
+
+			proc := &processor.Processor{Config: &config.Config{ClusterID: "cluster"}, Context: context.TODO()}
+			if err := ops.Send(proc, response); err != nil {
+				t.Fatalf("OpenSearch.Send() error = %v", err)
+			}
+
+			if len(bulkLines) != 4 || bulkLines[0] != `{"index":{"_index":"ocm_service_logs","_id":"2"}}` {
+				t.Errorf("OpenSearch.Send() bulk body = %v, want documents 2 and 3", bulkLines)
+			}
+
+			for id, want := range map[string]bool{"1": true, "2": true, "3": false} {
+				logEntry, _ := v1.NewLogEntry().ID(id).Build()
+				if got := ops.HasSent(logEntry); got != want {
+					t.Errorf("OpenSearch.HasSent(%s) = %v, want %v", id, got, want)
+				}
+			}
+		})
+	}
+}
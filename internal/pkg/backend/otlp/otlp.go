@@ -0,0 +1,348 @@
+package otlp
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/http2"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	otlpRequestTimeout  = 30 * time.Second
+	otlpResponseMaxRead = 1024 * 1024
+	otlpBatchSize       = 512
+
+	otlpPathGRPC = "/opentelemetry.proto.collector.logs.v1.LogsService/Export"
+	otlpPathHTTP = "/v1/logs"
+
+	otlpContentTypeGRPC = "application/grpc"
+	otlpContentTypeHTTP = "application/x-protobuf"
+
+	grpcStatusOK     = "0"
+	grpcPrefixLength = 5
+)
+
+var (
+	ErrOTLPResponse = errors.New("unexpected response from otlp endpoint")
+)
+
+// OTLP is a backend which exports service logs as opentelemetry log records to an otlp
+// endpoint, such as an opentelemetry collector, over either grpc or http/protobuf.
+type OTLP struct {
+	Config       *config.OTLPConfig
+	Headers      map[string]string
+	Client       *http.Client
+	ClusterName  string
+	SentMessages []string
+}
+
+func (otlp *OTLP) Initialize(proc *processor.Processor) (err error) {
+	otlp.Config, err = config.GetOTLPConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure otlp backend - %w", err)
+	}
+
+	otlp.Headers, err = config.GetOTLPHeaders(proc.KubeClient, proc.Context)
+	if err != nil {
+		return fmt.Errorf("unable to configure otlp headers - %w", err)
+	}
+
+	otlp.Client, err = getClient(otlp.Config)
+	if err != nil {
+		return fmt.Errorf("unable to configure otlp client - %w", err)
+	}
+
+	otlp.ClusterName = otlp.clusterName(proc)
+
+	return nil
+}
+
+func (otlp *OTLP) Send(proc *processor.Processor, response *poller.Response) error {
+	logs := []*v1.LogEntry{}
+
+	for _, logEntry := range response.Logs {
+		if otlp.HasSent(logEntry) {
+			continue
+		}
+
+		logs = append(logs, logEntry)
+	}
+
+	for start := 0; start < len(logs); start += otlpBatchSize {
+		end := start + otlpBatchSize
+		if end > len(logs) {
+			end = len(logs)
+		}
+
+		batch := logs[start:end]
+
+		if err := otlp.export(proc, batch); err != nil {
+			otlp.Log(
+				log.Err(err).Str("cluster", proc.Config.ClusterID),
+				fmt.Sprintf("batch number [%d] failed to export", start/otlpBatchSize),
+			)
+
+			continue
+		}
+
+		// add the messages to the list of sent messages
+		for _, logEntry := range batch {
+			otlp.SentMessages = append(otlp.SentMessages, logEntry.ID())
+		}
+	}
+
+	return nil
+}
+
+func (otlp *OTLP) String() string {
+	return config.DefaultBackendOTLP
+}
+
+func (otlp *OTLP) HasSent(message *v1.LogEntry) bool {
+	for i := range otlp.SentMessages {
+		if message.ID() == otlp.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (otlp *OTLP) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", otlp.String())).Msg(message)
+}
+
+// clusterName returns the name of the cluster, which is not part of a service log and is only
+// retrieved once.  It is only used to enrich the log records, so a failure is logged rather than
+// returned.
+func (otlp *OTLP) clusterName(proc *processor.Processor) string {
+	ocm, err := poller.NewPoller(proc)
+	if err != nil {
+		otlp.Log(log.Warn().Err(err).Str("cluster", proc.Config.ClusterID), "unable to retrieve cluster name")
+
+		return ""
+	}
+	defer ocm.Client.Close()
+
+	name, err := ocm.RequestClusterName(proc)
+	if err != nil {
+		otlp.Log(log.Warn().Err(err).Str("cluster", proc.Config.ClusterID), "unable to retrieve cluster name")
+
+		return ""
+	}
+
+	return name
+}
+
+// export sends a single batch of service logs to the otlp endpoint.
+func (otlp *OTLP) export(proc *processor.Processor, batch []*v1.LogEntry) error {
+	var responseBody []byte
+
+	var err error
+
+	otlp.Log(
+		log.Info().Str("cluster", proc.Config.ClusterID).Str("endpoint", otlp.Config.Endpoint).Int("document_count", len(batch)),
+		"exporting service logs to otlp endpoint",
+	)
+
+	body, err := buildExportRequest(batch, otlp.ClusterName)
+	if err != nil {
+		return err
+	}
+
+	switch otlp.Config.Protocol {
+	case config.DefaultBackendOTLPProtocolGRPC:
+		responseBody, err = otlp.exportGRPC(proc, body)
+	default:
+		responseBody, err = otlp.exportHTTP(proc, body)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// rejected log records are reported by the server but are not retryable, so we
+	// only log them
+	rejected, message, err := partialSuccess(responseBody)
+	if err != nil {
+		return fmt.Errorf("unable to decode export response - %w", err)
+	}
+
+	if rejected > 0 || message != "" {
+		otlp.Log(
+			log.Warn().Str("cluster", proc.Config.ClusterID).Int64("rejected_count", rejected).Str("error_message", message),
+			"otlp endpoint partially rejected service logs",
+		)
+	}
+
+	return nil
+}
+
+// exportHTTP sends an export request using the http/protobuf protocol.
+func (otlp *OTLP) exportHTTP(proc *processor.Processor, body []byte) ([]byte, error) {
+	response, err := otlp.do(proc, otlpPathHTTP, otlpContentTypeHTTP, body)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, otlpResponseMaxRead))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read otlp response - %w", err)
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("status [%d] with body [%s] - %w", response.StatusCode, string(responseBody), ErrOTLPResponse)
+	}
+
+	// the response is only a protobuf message when the server says so
+	if !strings.HasPrefix(response.Header.Get("Content-Type"), otlpContentTypeHTTP) {
+		return nil, nil
+	}
+
+	return responseBody, nil
+}
+
+// exportGRPC sends an export request using the grpc protocol.  Messages are prefixed with a
+// compression flag and the message length, and the status of the call is returned in the
+// trailers, or in the headers when the server responds without a body.
+func (otlp *OTLP) exportGRPC(proc *processor.Processor, body []byte) ([]byte, error) {
+	frame := make([]byte, grpcPrefixLength, grpcPrefixLength+len(body))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(body)))
+	frame = append(frame, body...)
+
+	response, err := otlp.do(proc, otlpPathGRPC, otlpContentTypeGRPC, frame)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, otlpResponseMaxRead))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read otlp response - %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status [%d] - %w", response.StatusCode, ErrOTLPResponse)
+	}
+
+	status, message := response.Trailer.Get("grpc-status"), response.Trailer.Get("grpc-message")
+	if status == "" {
+		status, message = response.Header.Get("grpc-status"), response.Header.Get("grpc-message")
+	}
+
+	if status != grpcStatusOK {
+		return nil, fmt.Errorf("grpc status [%s] with message [%s] - %w", status, message, ErrOTLPResponse)
+	}
+
+	if len(responseBody) < grpcPrefixLength {
+		return nil, nil
+	}
+
+	length := binary.BigEndian.Uint32(responseBody[1:grpcPrefixLength])
+	if responseBody[0] != 0 || int(length) > len(responseBody)-grpcPrefixLength {
+		return nil, fmt.Errorf("invalid grpc response message - %w", ErrOTLPResponse)
+	}
+
+	return responseBody[grpcPrefixLength : grpcPrefixLength+int(length)], nil
+}
+
+// do sends a request to a path of the otlp endpoint along with the configured headers.
+func (otlp *OTLP) do(proc *processor.Processor, path, contentType string, body []byte) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(
+		proc.Context,
+		http.MethodPost,
+		strings.TrimSuffix(otlp.Config.Endpoint, "/")+path,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create otlp request - %w", err)
+	}
+
+	for key, value := range otlp.Headers {
+		request.Header.Set(key, value)
+	}
+
+	request.Header.Set("Content-Type", contentType)
+
+	if contentType == otlpContentTypeGRPC {
+		request.Header.Set("TE", "trailers")
+	}
+
+	response, err := otlp.Client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to send otlp request - %w", err)
+	}
+
+	return response, nil
+}
+
+// getClient returns the http client used to reach the otlp endpoint.  grpc requires http/2,
+// which is negotiated over tls for https endpoints and spoken directly (h2c) for http endpoints.
+func getClient(otlpConfig *config.OTLPConfig) (*http.Client, error) {
+	endpoint, err := url.Parse(otlpConfig.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse endpoint [%s] - %w", otlpConfig.Endpoint, err)
+	}
+
+	tlsConfig, err := getTLSConfig(otlpConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to set tls config - %w", err)
+	}
+
+	if otlpConfig.Protocol != config.DefaultBackendOTLPProtocolGRPC {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+
+		return &http.Client{Timeout: otlpRequestTimeout, Transport: transport}, nil
+	}
+
+	transport := &http2.Transport{TLSClientConfig: tlsConfig}
+
+	if endpoint.Scheme == "http" {
+		transport.AllowHTTP = true
+		transport.DialTLS = func(network, address string, _ *tls.Config) (net.Conn, error) {
+			return net.DialTimeout(network, address, otlpRequestTimeout)
+		}
+	}
+
+	return &http.Client{Timeout: otlpRequestTimeout, Transport: transport}, nil
+}
+
+// getTLSConfig returns the tls configuration for the otlp endpoint, including the
+// optional certificate authority.
+func getTLSConfig(otlpConfig *config.OTLPConfig) (*tls.Config, error) {
+	//nolint: gosec
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !otlpConfig.TLSVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if otlpConfig.TLSCA != "" {
+		pool, err := utils.CertPoolFromFile(otlpConfig.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
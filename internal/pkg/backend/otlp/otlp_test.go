@@ -0,0 +1,216 @@
+package otlp
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	logsv1 "go.opentelemetry.io/proto/otlp/logs/v1"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+// collectorStandIn is a local stand-in for an opentelemetry collector, which accepts export
+// requests over grpc (h2c) or http/protobuf and records the log records that it receives.
+type collectorStandIn struct {
+	grpcStatus string
+	httpStatus int
+	rejected   uint64
+
+	mutex   sync.Mutex
+	records []*logsv1.LogRecord
+	headers http.Header
+}
+
+func (standIn *collectorStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	standIn.headers = r.Header.Clone()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	if r.URL.Path == otlpPathGRPC {
+		if r.ProtoMajor != 2 || r.Header.Get("Content-Type") != otlpContentTypeGRPC || len(body) < grpcPrefixLength {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		body = body[grpcPrefixLength:]
+	}
+
+	request := &logsv1.LogsData{}
+	if err := proto.Unmarshal(body, request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	for _, resourceLogs := range request.ResourceLogs {
+		for _, scopeLogs := range resourceLogs.ScopeLogs {
+			standIn.records = append(standIn.records, scopeLogs.LogRecords...)
+		}
+	}
+
+	response := standIn.response()
+
+	if r.URL.Path == otlpPathGRPC {
+		// the status of a grpc call is sent in the trailers
+		w.Header().Set("Content-Type", otlpContentTypeGRPC)
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		w.WriteHeader(http.StatusOK)
+
+		frame := make([]byte, grpcPrefixLength, grpcPrefixLength+len(response))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(response)))
+		_, _ = w.Write(append(frame, response...))
+
+		w.Header().Set("Grpc-Status", standIn.grpcStatus)
+		w.Header().Set("Grpc-Message", "stand-in")
+
+		return
+	}
+
+	w.Header().Set("Content-Type", otlpContentTypeHTTP)
+	w.WriteHeader(standIn.httpStatus)
+	_, _ = w.Write(response)
+}
+
+// response returns an ExportLogsServiceResponse with the configured number of rejected records.
+func (standIn *collectorStandIn) response() []byte {
+	if standIn.rejected == 0 {
+		return nil
+	}
+
+	var partial, response []byte
+
+	partial = protowire.AppendTag(partial, fieldPartialSuccessRejected, protowire.VarintType)
+	partial = protowire.AppendVarint(partial, standIn.rejected)
+	response = protowire.AppendTag(response, fieldExportResponsePartialSuccess, protowire.BytesType)
+
+	return protowire.AppendBytes(response, partial)
+}
+
+func TestOTLP_Send(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		protocol    string
+		grpcStatus  string
+		httpStatus  int
+		rejected    uint64
+		wantRecords int
+		wantSent    int
+	}{
+		{
+			name:        "ensure grpc exports over h2c are sent",
+			protocol:    config.DefaultBackendOTLPProtocolGRPC,
+			grpcStatus:  grpcStatusOK,
+			wantRecords: 3,
+			wantSent:    3,
+		},
+		{
+			name:        "ensure grpc exports with a partial success are sent",
+			protocol:    config.DefaultBackendOTLPProtocolGRPC,
+			grpcStatus:  grpcStatusOK,
+			rejected:    1,
+			wantRecords: 3,
+			wantSent:    3,
+		},
+		{
+			name:        "ensure grpc exports with an error status are not sent",
+			protocol:    config.DefaultBackendOTLPProtocolGRPC,
+			grpcStatus:  "14",
+			wantRecords: 3,
+			wantSent:    0,
+		},
+		{
+			name:        "ensure http exports are sent",
+			protocol:    config.DefaultBackendOTLPProtocolHTTP,
+			httpStatus:  http.StatusOK,
+			rejected:    1,
+			wantRecords: 3,
+			wantSent:    3,
+		},
+		{
+			name:        "ensure http exports with an error status are not sent",
+			protocol:    config.DefaultBackendOTLPProtocolHTTP,
+			httpStatus:  http.StatusServiceUnavailable,
+			wantRecords: 3,
+			wantSent:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			standIn := &collectorStandIn{grpcStatus: tt.grpcStatus, httpStatus: tt.httpStatus, rejected: tt.rejected}
+
+			server := httptest.NewServer(h2c.NewHandler(standIn, &http2.Server{}))
+			t.Cleanup(server.Close)
+
+			otlpConfig := &config.OTLPConfig{Endpoint: server.URL, Protocol: tt.protocol, TLSVerify: true}
+
+			client, err := getClient(otlpConfig)
+			if err != nil {
+				t.Fatalf("getClient() error = %v", err)
+			}
+
+			otlp := &OTLP{
+				Config:      otlpConfig,
+				Client:      client,
+				Headers:     map[string]string{"Authorization": "Bearer token"},
+				ClusterName: "name",
+			}
+
+			logs := []*v1.LogEntry{}
+
+			for _, id := range []string{"1", "2", "3"} {
+				logEntry, err := v1.NewLogEntry().ID(id).ClusterID("test").Summary("summary").Build()
+				if err != nil {
+					t.Fatalf("unable to build log entry - %v", err)
+				}
+
+				logs = append(logs, logEntry)
+			}
+
+			proc := &processor.Processor{Config: &config.Config{ClusterID: "test"}, Context: context.TODO()}
+
+			if err := otlp.Send(proc, &poller.Response{Logs: logs}); err != nil {
+				t.Fatalf("OTLP.Send() error = %v", err)
+			}
+
+			if len(standIn.records) != tt.wantRecords {
+				t.Errorf("OTLP.Send() records received = %v, want %v", len(standIn.records), tt.wantRecords)
+			}
+
+			if len(otlp.SentMessages) != tt.wantSent {
+				t.Errorf("OTLP.Send() sent = %v, want %v", otlp.SentMessages, tt.wantSent)
+			}
+
+			if got := standIn.headers.Get("Authorization"); !strings.HasPrefix(got, "Bearer") {
+				t.Errorf("OTLP.Send() authorization header = %v, want bearer token", got)
+			}
+		})
+	}
+}
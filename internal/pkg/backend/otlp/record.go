@@ -0,0 +1,201 @@
+package otlp
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	logsv1 "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcev1 "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: the messages of the opentelemetry collector service are generated in the same package as
+// its grpc service, so the requests are built from the LogsData message instead, which shares the
+// field numbers of an ExportLogsServiceRequest, and the response is decoded by hand.  See
+// https://github.com/open-telemetry/opentelemetry-proto/tree/main/opentelemetry/proto.
+const (
+	// opentelemetry.proto.collector.logs.v1.ExportLogsServiceResponse
+	fieldExportResponsePartialSuccess protowire.Number = 1
+	fieldPartialSuccessRejected       protowire.Number = 1
+	fieldPartialSuccessErrorMessage   protowire.Number = 2
+
+	otlpScopeName = "ocm-log-forwarder"
+)
+
+// Resource represents the cluster that a set of service logs belongs to.
+type Resource struct {
+	ClusterID   string
+	ExternalID  string
+	ClusterName string
+}
+
+// attributes returns the resource attributes for the cluster.
+func (resource Resource) attributes() []*commonv1.KeyValue {
+	return keyValues(
+		"service.name", otlpScopeName,
+		"ocm.cluster.id", resource.ClusterID,
+		"ocm.cluster.external_id", resource.ExternalID,
+		"ocm.cluster.name", resource.ClusterName,
+		"k8s.cluster.name", resource.ClusterName,
+	)
+}
+
+// buildExportRequest encodes an ExportLogsServiceRequest for a set of service logs.  Service
+// logs are grouped into a ResourceLogs message per cluster.
+func buildExportRequest(logs []*v1.LogEntry, clusterName string) ([]byte, error) {
+	request := &logsv1.LogsData{}
+	scopeLogs := map[Resource]*logsv1.ScopeLogs{}
+
+	for _, logEntry := range logs {
+		resource := Resource{ClusterID: logEntry.ClusterID(), ExternalID: logEntry.ClusterUUID(), ClusterName: clusterName}
+
+		if _, ok := scopeLogs[resource]; !ok {
+			scopeLogs[resource] = &logsv1.ScopeLogs{Scope: &commonv1.InstrumentationScope{Name: otlpScopeName}}
+
+			request.ResourceLogs = append(request.ResourceLogs, &logsv1.ResourceLogs{
+				Resource:  &resourcev1.Resource{Attributes: resource.attributes()},
+				ScopeLogs: []*logsv1.ScopeLogs{scopeLogs[resource]},
+			})
+		}
+
+		scopeLogs[resource].LogRecords = append(scopeLogs[resource].LogRecords, buildLogRecord(logEntry))
+	}
+
+	encoded, err := proto.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode export request - %w", err)
+	}
+
+	return encoded, nil
+}
+
+// buildLogRecord builds a LogRecord for a service log.  The body of the record is the summary
+// and the remaining fields are record attributes.
+func buildLogRecord(logEntry *v1.LogEntry) *logsv1.LogRecord {
+	record := &logsv1.LogRecord{
+		ObservedTimeUnixNano: uint64(time.Now().UnixNano()),
+		SeverityNumber:       severityNumber(logEntry.Severity()),
+		SeverityText:         string(logEntry.Severity()),
+		Body:                 &commonv1.AnyValue{Value: &commonv1.AnyValue_StringValue{StringValue: logEntry.Summary()}},
+		Attributes: append(keyValues(
+			"ocm.id", logEntry.ID(),
+			"ocm.description", logEntry.Description(),
+			"ocm.service_name", logEntry.ServiceName(),
+			"ocm.event_stream_id", logEntry.EventStreamID(),
+			"ocm.username", logEntry.Username(),
+			"ocm.subscription_id", logEntry.SubscriptionID(),
+			"ocm.log_type", string(logEntry.LogType()),
+		), &commonv1.KeyValue{
+			Key:   "ocm.internal_only",
+			Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_BoolValue{BoolValue: logEntry.InternalOnly()}},
+		}),
+	}
+
+	if !logEntry.Timestamp().IsZero() {
+		record.TimeUnixNano = uint64(logEntry.Timestamp().UnixNano())
+	}
+
+	return record
+}
+
+// keyValues returns string attributes from pairs of keys and values.  Empty values are omitted.
+func keyValues(pairs ...string) []*commonv1.KeyValue {
+	attributes := []*commonv1.KeyValue{}
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] == "" {
+			continue
+		}
+
+		attributes = append(attributes, &commonv1.KeyValue{
+			Key:   pairs[i],
+			Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_StringValue{StringValue: pairs[i+1]}},
+		})
+	}
+
+	return attributes
+}
+
+// severityNumber maps a service log severity to an opentelemetry severity number.
+func severityNumber(severity v1.Severity) logsv1.SeverityNumber {
+	switch utils.SeverityLevel(severity) {
+	case utils.SeverityLevelCritical:
+		return logsv1.SeverityNumber_SEVERITY_NUMBER_FATAL
+	case utils.SeverityLevelError:
+		return logsv1.SeverityNumber_SEVERITY_NUMBER_ERROR
+	case utils.SeverityLevelWarning:
+		return logsv1.SeverityNumber_SEVERITY_NUMBER_WARN
+	case utils.SeverityLevelInfo:
+		return logsv1.SeverityNumber_SEVERITY_NUMBER_INFO
+	case utils.SeverityLevelDebug:
+		return logsv1.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	default:
+		return logsv1.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED
+	}
+}
+
+// partialSuccess decodes the partial success of an ExportLogsServiceResponse, which reports
+// the number of rejected log records.
+func partialSuccess(response []byte) (rejected int64, message string, err error) {
+	err = consumeFields(response, func(field protowire.Number, _ protowire.Type, value []byte, _ uint64) error {
+		if field != fieldExportResponsePartialSuccess {
+			return nil
+		}
+
+		return consumeFields(value, func(field protowire.Number, _ protowire.Type, value []byte, number uint64) error {
+			switch field {
+			case fieldPartialSuccessRejected:
+				rejected = int64(number)
+			case fieldPartialSuccessErrorMessage:
+				message = string(value)
+			}
+
+			return nil
+		})
+	})
+
+	return rejected, message, err
+}
+
+// consumeFields iterates over the fields of an encoded message.  Length delimited fields are
+// passed as bytes and varint fields are passed as a number.
+func consumeFields(buffer []byte, fn func(protowire.Number, protowire.Type, []byte, uint64) error) error {
+	for len(buffer) > 0 {
+		field, fieldType, length := protowire.ConsumeTag(buffer)
+		if length < 0 {
+			return fmt.Errorf("unable to decode field tag - %w", protowire.ParseError(length))
+		}
+
+		buffer = buffer[length:]
+
+		var value []byte
+
+		var number uint64
+
+		switch fieldType {
+		case protowire.BytesType:
+			value, length = protowire.ConsumeBytes(buffer)
+		case protowire.VarintType:
+			number, length = protowire.ConsumeVarint(buffer)
+		default:
+			length = protowire.ConsumeFieldValue(field, fieldType, buffer)
+		}
+
+		if length < 0 {
+			return fmt.Errorf("unable to decode field [%d] - %w", field, protowire.ParseError(length))
+		}
+
+		buffer = buffer[length:]
+
+		if err := fn(field, fieldType, value, number); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package otlp
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	logsv1 "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+func Test_severityNumber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		severity v1.Severity
+		want     logsv1.SeverityNumber
+	}{
+		{
+			name:     "ensure info maps to info",
+			severity: v1.SeverityInfo,
+			want:     logsv1.SeverityNumber_SEVERITY_NUMBER_INFO,
+		},
+		{
+			name:     "ensure warning maps to warn",
+			severity: v1.SeverityWarning,
+			want:     logsv1.SeverityNumber_SEVERITY_NUMBER_WARN,
+		},
+		{
+			name:     "ensure critical maps to fatal",
+			severity: utils.SeverityCritical,
+			want:     logsv1.SeverityNumber_SEVERITY_NUMBER_FATAL,
+		},
+		{
+			name:     "ensure unknown maps to unspecified",
+			severity: v1.Severity("Unknown"),
+			want:     logsv1.SeverityNumber_SEVERITY_NUMBER_UNSPECIFIED,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := severityNumber(tt.severity); got != tt.want {
+				t.Errorf("severityNumber() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_buildExportRequest(t *testing.T) {
+	t.Parallel()
+
+	logs := []*v1.LogEntry{}
+
+	for _, clusterID := range []string{"first", "second", "first"} {
+		logEntry, err := v1.NewLogEntry().ID(clusterID).ClusterID(clusterID).ClusterUUID("uuid").Summary("summary").Build()
+		if err != nil {
+			t.Fatalf("unable to build log entry - %v", err)
+		}
+
+		logs = append(logs, logEntry)
+	}
+
+	encoded, err := buildExportRequest(logs, "name")
+	if err != nil {
+		t.Fatalf("buildExportRequest() error = %v", err)
+	}
+
+	request := &logsv1.LogsData{}
+	if err := proto.Unmarshal(encoded, request); err != nil {
+		t.Fatalf("unable to decode export request - %v", err)
+	}
+
+	if len(request.ResourceLogs) != 2 {
+		t.Fatalf("buildExportRequest() resource logs = %v, want %v", len(request.ResourceLogs), 2)
+	}
+
+	records := 0
+	for _, resourceLogs := range request.ResourceLogs {
+		records += len(resourceLogs.ScopeLogs[0].LogRecords)
+	}
+
+	if records != 3 {
+		t.Errorf("buildExportRequest() log records = %v, want %v", records, 3)
+	}
+
+	if got := request.ResourceLogs[0].ScopeLogs[0].LogRecords[0].Body.GetStringValue(); got != "summary" {
+		t.Errorf("buildExportRequest() body = %v, want %v", got, "summary")
+	}
+
+	// the external id is not the uid of the kubernetes cluster, so it is only set as an ocm attribute
+	attributes := map[string]string{}
+	for _, attribute := range request.ResourceLogs[0].Resource.Attributes {
+		attributes[attribute.Key] = attribute.Value.GetStringValue()
+	}
+
+	want := map[string]string{
+		"service.name":            otlpScopeName,
+		"ocm.cluster.id":          "first",
+		"ocm.cluster.external_id": "uuid",
+		"ocm.cluster.name":        "name",
+		"k8s.cluster.name":        "name",
+	}
+
+	if !reflect.DeepEqual(attributes, want) {
+		t.Errorf("buildExportRequest() resource attributes = %v, want %v", attributes, want)
+	}
+}
+
+func Test_partialSuccess(t *testing.T) {
+	t.Parallel()
+
+	var message, response []byte
+
+	message = protowire.AppendTag(message, fieldPartialSuccessRejected, protowire.VarintType)
+	message = protowire.AppendVarint(message, 2)
+	message = protowire.AppendTag(message, fieldPartialSuccessErrorMessage, protowire.BytesType)
+	message = protowire.AppendString(message, "rejected")
+	response = protowire.AppendTag(response, fieldExportResponsePartialSuccess, protowire.BytesType)
+	response = protowire.AppendBytes(response, message)
+
+	rejected, errorMessage, err := partialSuccess(response)
+	if err != nil {
+		t.Fatalf("partialSuccess() error = %v", err)
+	}
+
+	if rejected != 2 || errorMessage != "rejected" {
+		t.Errorf("partialSuccess() = %v, %v, want %v, %v", rejected, errorMessage, 2, "rejected")
+	}
+}
@@ -0,0 +1,106 @@
+package pagerduty
+
+import (
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	pagerDutyEventActionTrigger = "trigger"
+	pagerDutyClient             = "ocm-log-forwarder"
+	pagerDutySummaryMaxLength   = 1024
+	pagerDutyDedupKeyMaxLength  = 255
+
+	pagerDutySeverityCritical = "critical"
+	pagerDutySeverityError    = "error"
+	pagerDutySeverityWarning  = "warning"
+	pagerDutySeverityInfo     = "info"
+)
+
+// PagerDutyEvent represents an event that is sent to the pagerduty events v2 api.
+type PagerDutyEvent struct {
+	RoutingKey  string                `json:"routing_key"`
+	EventAction string                `json:"event_action"`
+	DedupKey    string                `json:"dedup_key"`
+	Client      string                `json:"client"`
+	Payload     PagerDutyEventPayload `json:"payload"`
+}
+
+// PagerDutyEventPayload represents the payload of a pagerduty event, which contains
+// the details of the service log.
+type PagerDutyEventPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details"`
+}
+
+// buildEvent builds a pagerduty trigger event from a service log message.
+func buildEvent(logEntry *v1.LogEntry, routingKey, dedupKey string) *PagerDutyEvent {
+	event := &PagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: pagerDutyEventActionTrigger,
+		DedupKey:    eventDedupKey(logEntry, dedupKey),
+		Client:      pagerDutyClient,
+		Payload: PagerDutyEventPayload{
+			Summary:   utils.Truncate(logEntry.Summary(), pagerDutySummaryMaxLength, ""),
+			Source:    logEntry.ClusterID(),
+			Severity:  eventSeverity(logEntry.Severity()),
+			Component: logEntry.ServiceName(),
+			Group:     logEntry.ClusterID(),
+			Class:     string(logEntry.LogType()),
+			CustomDetails: map[string]string{
+				"id":              logEntry.ID(),
+				"cluster_id":      logEntry.ClusterID(),
+				"external_id":     logEntry.ClusterUUID(),
+				"subscription_id": logEntry.SubscriptionID(),
+				"username":        logEntry.Username(),
+				"severity":        string(logEntry.Severity()),
+				"service_name":    logEntry.ServiceName(),
+				"event_stream_id": logEntry.EventStreamID(),
+				"description":     logEntry.Description(),
+			},
+		},
+	}
+
+	if !logEntry.Timestamp().IsZero() {
+		event.Payload.Timestamp = logEntry.Timestamp().UTC().Format(time.RFC3339)
+	}
+
+	return event
+}
+
+// eventDedupKey returns a stable dedup key for a service log so that re-sending the
+// same service log does not open a new incident.  The event stream id falls back to
+// the service log id when it is not set.
+func eventDedupKey(logEntry *v1.LogEntry, dedupKey string) string {
+	key := logEntry.ID()
+
+	if dedupKey == config.DefaultBackendPagerDutyDedupKeyEventStreamID && logEntry.EventStreamID() != "" {
+		key = logEntry.EventStreamID()
+	}
+
+	return utils.Truncate(key, pagerDutyDedupKeyMaxLength, "")
+}
+
+// eventSeverity maps a service log severity to a pagerduty severity.
+func eventSeverity(severity v1.Severity) string {
+	switch utils.SeverityLevel(severity) {
+	case utils.SeverityLevelCritical:
+		return pagerDutySeverityCritical
+	case utils.SeverityLevelError:
+		return pagerDutySeverityError
+	case utils.SeverityLevelWarning:
+		return pagerDutySeverityWarning
+	default:
+		return pagerDutySeverityInfo
+	}
+}
@@ -0,0 +1,100 @@
+package pagerduty
+
+import (
+	"strings"
+	"testing"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+func Test_buildEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		severity      v1.Severity
+		eventStreamID string
+		dedupKey      string
+		summary       string
+		wantSeverity  string
+		wantDedupKey  string
+		wantSummary   string
+	}{
+		{
+			name:         "ensure error severity uses the service log id",
+			severity:     v1.SeverityError,
+			dedupKey:     config.DefaultBackendPagerDutyDedupKeyID,
+			wantSeverity: pagerDutySeverityError,
+			wantDedupKey: "id",
+		},
+		{
+			name:          "ensure critical severity uses the event stream id",
+			severity:      utils.SeverityCritical,
+			eventStreamID: "stream",
+			dedupKey:      config.DefaultBackendPagerDutyDedupKeyEventStreamID,
+			wantSeverity:  pagerDutySeverityCritical,
+			wantDedupKey:  "stream",
+		},
+		{
+			name:         "ensure fatal severity falls back to the service log id",
+			severity:     v1.SeverityFatal,
+			dedupKey:     config.DefaultBackendPagerDutyDedupKeyEventStreamID,
+			wantSeverity: pagerDutySeverityCritical,
+			wantDedupKey: "id",
+		},
+		{
+			name:         "ensure long summaries are truncated between characters",
+			severity:     v1.SeverityError,
+			dedupKey:     config.DefaultBackendPagerDutyDedupKeyID,
+			summary:      strings.Repeat("é", pagerDutySummaryMaxLength+1),
+			wantSeverity: pagerDutySeverityError,
+			wantDedupKey: "id",
+			wantSummary:  strings.Repeat("é", pagerDutySummaryMaxLength),
+		},
+		{
+			name:         "ensure debug severity maps to info",
+			severity:     v1.SeverityDebug,
+			dedupKey:     config.DefaultBackendPagerDutyDedupKeyID,
+			wantSeverity: pagerDutySeverityInfo,
+			wantDedupKey: "id",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().
+				ID("id").
+				ClusterID("cluster").
+				Severity(tt.severity).
+				EventStreamID(tt.eventStreamID).
+				Summary(tt.summary).
+				Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			event := buildEvent(logEntry, "routing", tt.dedupKey)
+			if event.Payload.Severity != tt.wantSeverity {
+				t.Errorf("buildEvent() severity = %v, want %v", event.Payload.Severity, tt.wantSeverity)
+			}
+
+			if event.DedupKey != tt.wantDedupKey {
+				t.Errorf("buildEvent() dedup key = %v, want %v", event.DedupKey, tt.wantDedupKey)
+			}
+
+			if event.Payload.Summary != tt.wantSummary {
+				t.Errorf("buildEvent() summary = %v, want %v", event.Payload.Summary, tt.wantSummary)
+			}
+
+			if event.Payload.CustomDetails["cluster_id"] != "cluster" {
+				t.Errorf("buildEvent() cluster_id = %v, want %v", event.Payload.CustomDetails["cluster_id"], "cluster")
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package pagerduty
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	pagerDutyRequestTimeout  = 10 * time.Second
+	pagerDutyResponseMaxRead = 512
+)
+
+var (
+	ErrPagerDutyResponse = errors.New("unexpected response from pagerduty events api")
+)
+
+// PagerDuty is a backend which triggers pagerduty incidents from actionable service logs
+// using the pagerduty events v2 api.
+type PagerDuty struct {
+	URL          string
+	RoutingKey   string
+	DedupKey     string
+	Threshold    v1.Severity
+	Client       *http.Client
+	SentMessages []string
+}
+
+func (pd *PagerDuty) Initialize(proc *processor.Processor) (err error) {
+	pd.RoutingKey, err = config.GetPagerDutyRoutingKey(proc.KubeClient, proc.Context)
+	if err != nil {
+		return fmt.Errorf("unable to configure pagerduty routing key - %w", err)
+	}
+
+	pd.Threshold, err = config.GetPagerDutySeverityThreshold()
+	if err != nil {
+		return fmt.Errorf("unable to configure pagerduty severity threshold - %w", err)
+	}
+
+	pd.DedupKey, err = config.GetPagerDutyDedupKey()
+	if err != nil {
+		return fmt.Errorf("unable to configure pagerduty dedup key - %w", err)
+	}
+
+	pd.URL = config.GetPagerDutyURL()
+	pd.Client = &http.Client{Timeout: pagerDutyRequestTimeout}
+
+	return nil
+}
+
+func (pd *PagerDuty) Send(proc *processor.Processor, response *poller.Response) error {
+	for _, logEntry := range response.Logs {
+		if pd.HasSent(logEntry) || !utils.SeverityAtLeast(logEntry.Severity(), pd.Threshold) {
+			continue
+		}
+
+		// failed events are left unsent so that they are retried on the next poll
+		if err := pd.send(proc, logEntry); err != nil {
+			pd.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to send pagerduty event")
+
+			continue
+		}
+	}
+
+	return nil
+}
+
+func (pd *PagerDuty) String() string {
+	return config.DefaultBackendPagerDuty
+}
+
+func (pd *PagerDuty) HasSent(message *v1.LogEntry) bool {
+	for i := range pd.SentMessages {
+		if message.ID() == pd.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pd *PagerDuty) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", pd.String())).Msg(message)
+}
+
+func (pd *PagerDuty) send(proc *processor.Processor, logEntry *v1.LogEntry) error {
+	event := buildEvent(logEntry, pd.RoutingKey, pd.DedupKey)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("unable to marshal pagerduty event - %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(proc.Context, http.MethodPost, pd.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to create pagerduty request - %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := pd.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("unable to send pagerduty request - %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusAccepted {
+		responseBody, _ := io.ReadAll(io.LimitReader(response.Body, pagerDutyResponseMaxRead))
+
+		return fmt.Errorf("status [%d] with body [%s] - %w", response.StatusCode, string(responseBody), ErrPagerDutyResponse)
+	}
+
+	pd.Log(
+		log.Info().
+			Str("cluster", proc.Config.ClusterID).
+			Str("message_id", logEntry.ID()).
+			Str("dedup_key", event.DedupKey).
+			Str("severity", event.Payload.Severity),
+		"triggered pagerduty event",
+	)
+
+	// add the message to the list of sent messages
+	pd.SentMessages = append(pd.SentMessages, logEntry.ID())
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+const (
+	postgresDriver    = "postgres"
+	postgresBatchSize = 500
+	postgresSeedSize  = 1000
+
+	// postgresSavepoint is the savepoint which each row of a batch is inserted within.
+	postgresSavepoint = "service_log"
+)
+
+// Postgres is a backend which inserts service logs into a postgres table.  Duplicate service
+// logs are ignored by the database, so the table never contains a service log more than once.
+type Postgres struct {
+	DB           *sql.DB
+	Config       *config.PostgresConfig
+	SentMessages []string
+}
+
+func (pg *Postgres) Initialize(proc *processor.Processor) (err error) {
+	pg.Config, err = config.GetPostgresConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure postgres backend - %w", err)
+	}
+
+	username, password, err := config.GetPostgresCredentials(proc.KubeClient, proc.Context)
+	if err != nil {
+		return fmt.Errorf("unable to configure postgres credentials - %w", err)
+	}
+
+	// the connection is not established until it is used, so we ping the database to fail fast
+	pg.DB, err = sql.Open(postgresDriver, pg.Config.DataSourceName(username, password))
+	if err != nil {
+		return fmt.Errorf("unable to open postgres database - %w", err)
+	}
+
+	if err := pg.DB.PingContext(proc.Context); err != nil {
+		return fmt.Errorf("unable to connect to postgres database [%s:%s/%s] - %w", pg.Config.Host, pg.Config.Port, pg.Config.Database, err)
+	}
+
+	if err := pg.migrate(proc.Context); err != nil {
+		return fmt.Errorf("unable to migrate postgres schema - %w", err)
+	}
+
+	return nil
+}
+
+func (pg *Postgres) Send(proc *processor.Processor, response *poller.Response) error {
+	rows := []*PostgresRow{}
+
+	for _, logEntry := range response.Logs {
+		if pg.HasSent(logEntry) {
+			continue
+		}
+
+		row, err := buildRow(logEntry)
+		if err != nil {
+			pg.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to build postgres row")
+
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	for batchCount := 0; len(rows) > 0; batchCount++ {
+		size := postgresBatchSize
+		if size > len(rows) {
+			size = len(rows)
+		}
+
+		if err := pg.insert(proc, rows[:size]); err != nil {
+			pg.Log(log.Err(err).Str("cluster", proc.Config.ClusterID), fmt.Sprintf("batch number [%d] failed to send", batchCount))
+		}
+
+		rows = rows[size:]
+	}
+
+	return nil
+}
+
+// Recent returns the ids of the most recent service logs for the cluster.
+func (pg *Postgres) Recent(proc *processor.Processor) ([]string, error) {
+	return pg.ids(proc, fmt.Sprintf(
+		`SELECT id FROM %s WHERE cluster_id = $1 ORDER BY "timestamp" DESC LIMIT %d`,
+		pg.qualifiedTable(),
+		postgresSeedSize,
+	), proc.Config.ClusterID)
+}
+
+// Present returns the ids which are already stored.
+func (pg *Postgres) Present(proc *processor.Processor, ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return []string{}, nil
+	}
+
+	return pg.ids(proc, fmt.Sprintf("SELECT id FROM %s WHERE id = ANY($1)", pg.qualifiedTable()), pq.Array(ids))
+}
+
+// MarkSent stores service log ids as sent.
+func (pg *Postgres) MarkSent(ids ...string) {
+	pg.SentMessages = append(pg.SentMessages, ids...)
+}
+
+func (pg *Postgres) String() string {
+	return config.DefaultBackendPostgres
+}
+
+func (pg *Postgres) HasSent(message *v1.LogEntry) bool {
+	for i := range pg.SentMessages {
+		if message.ID() == pg.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (pg *Postgres) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", pg.String())).Msg(message)
+}
+
+// insert inserts a batch of rows in a single transaction.  Rows which already exist are ignored
+// by the database and are treated as sent.  Each row is inserted within a savepoint, so that a
+// row which the database rejects, such as one with a nul character which is not valid text, is
+// skipped rather than aborting the batch.  Skipped rows are not sent and are attempted again on
+// the next poll.
+func (pg *Postgres) insert(proc *processor.Processor, rows []*PostgresRow) error {
+	pg.Log(
+		log.Info().Str("cluster", proc.Config.ClusterID).Str("table", pg.qualifiedTable()).Int("document_count", len(rows)),
+		"sending rows to postgres",
+	)
+
+	tx, err := pg.DB.BeginTx(proc.Context, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction - %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	statement, err := tx.PrepareContext(proc.Context, insertStatement(pg.qualifiedTable()))
+	if err != nil {
+		return fmt.Errorf("unable to prepare insert into [%s] - %w", pg.qualifiedTable(), err)
+	}
+	defer statement.Close()
+
+	inserted := 0
+	sent := make([]string, 0, len(rows))
+
+	for _, row := range rows {
+		if _, err := tx.ExecContext(proc.Context, "SAVEPOINT "+postgresSavepoint); err != nil {
+			return fmt.Errorf("unable to create savepoint in [%s] - %w", pg.qualifiedTable(), err)
+		}
+
+		result, err := statement.ExecContext(proc.Context, row.Values()...)
+		if err != nil {
+			if _, rollbackErr := tx.ExecContext(proc.Context, "ROLLBACK TO SAVEPOINT "+postgresSavepoint); rollbackErr != nil {
+				return fmt.Errorf("unable to roll back service log [%s] in [%s] - %w", row.ID, pg.qualifiedTable(), rollbackErr)
+			}
+
+			pg.Log(
+				log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", row.ID),
+				fmt.Sprintf("skipping service log rejected by [%s]", pg.qualifiedTable()),
+			)
+
+			continue
+		}
+
+		if _, err := tx.ExecContext(proc.Context, "RELEASE SAVEPOINT "+postgresSavepoint); err != nil {
+			return fmt.Errorf("unable to release savepoint in [%s] - %w", pg.qualifiedTable(), err)
+		}
+
+		if affected, err := result.RowsAffected(); err == nil {
+			inserted += int(affected)
+		}
+
+		sent = append(sent, row.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit insert into [%s] - %w", pg.qualifiedTable(), err)
+	}
+
+	pg.SentMessages = append(pg.SentMessages, sent...)
+
+	pg.Log(
+		log.Info().
+			Str("cluster", proc.Config.ClusterID).
+			Int("inserted", inserted).
+			Int("existing", len(sent)-inserted).
+			Int("skipped", len(rows)-len(sent)),
+		"inserted rows into postgres",
+	)
+
+	return nil
+}
+
+// ids runs a query which returns service log ids.
+func (pg *Postgres) ids(proc *processor.Processor, query string, args ...interface{}) ([]string, error) {
+	rows, err := pg.DB.QueryContext(proc.Context, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query [%s] - %w", pg.qualifiedTable(), err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("unable to scan id from [%s] - %w", pg.qualifiedTable(), err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read ids from [%s] - %w", pg.qualifiedTable(), err)
+	}
+
+	return ids, nil
+}
+
+// qualifiedTable returns the schema qualified name of the service log table.
+func (pg *Postgres) qualifiedTable() string {
+	return pg.Config.Schema + "." + pg.Config.Table
+}
+
+// insertStatement returns the statement which inserts a row into the table.  The database
+// enforces deduplication by ignoring rows with an existing id.
+func insertStatement(table string) string {
+	columns := columns()
+
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (id) DO NOTHING",
+		table,
+		strings.Join(columns, ", "),
+		strings.Join(placeholders, ", "),
+	)
+}
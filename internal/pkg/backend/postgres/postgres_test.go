@@ -0,0 +1,518 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+var (
+	errStandInAborted       = errors.New("current transaction is aborted, commands ignored until end of transaction block")
+	errStandInInvalidText   = errors.New(`invalid byte sequence for encoding "UTF8": 0x00`)
+	errStandInMissingSchema = errors.New("schema does not exist")
+	errStandInMissingTable  = errors.New("relation does not exist")
+	errStandInStatement     = errors.New("statement is not supported by the stand-in")
+)
+
+var (
+	standInTablePattern  = regexp.MustCompile(`CREATE TABLE IF NOT EXISTS (\S+) \(`)
+	standInInsertPattern = regexp.MustCompile(`^INSERT INTO (\S+) \(`)
+	standInLimitPattern  = regexp.MustCompile(`LIMIT (\d+)$`)
+)
+
+// postgresStandIn is a local stand-in for a postgres database, which is used through a
+// database/sql driver.  It interprets only the statements issued by the backend: schema and
+// table creation, inserts which ignore conflicting ids and reject nul characters, savepoints
+// and the id queries.  Like postgres, a transaction is aborted by a failed statement until it
+// is rolled back to a savepoint.  Schema changes are applied immediately rather than on commit.
+type postgresStandIn struct {
+	mutex      sync.Mutex
+	schemas    map[string]bool
+	tables     map[string]bool
+	migrations int
+	rows       map[string]standInRow
+}
+
+type standInRow struct {
+	clusterID string
+	timestamp time.Time
+}
+
+func newPostgresStandIn(schemas ...string) *postgresStandIn {
+	standIn := &postgresStandIn{schemas: map[string]bool{}, tables: map[string]bool{}, rows: map[string]standInRow{}}
+
+	for _, schema := range schemas {
+		standIn.schemas[schema] = true
+	}
+
+	return standIn
+}
+
+func (standIn *postgresStandIn) Connect(context.Context) (driver.Conn, error) {
+	return &standInConn{standIn: standIn}, nil
+}
+
+func (standIn *postgresStandIn) Driver() driver.Driver {
+	return nil
+}
+
+// ids returns the committed ids, sorted.
+func (standIn *postgresStandIn) ids() []string {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	ids := []string{}
+	for id := range standIn.rows {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+type standInConn struct {
+	standIn *postgresStandIn
+	tx      *standInTx
+}
+
+type standInTx struct {
+	conn       *standInConn
+	pending    map[string]standInRow
+	savepoints []map[string]standInRow
+	aborted    bool
+}
+
+func (conn *standInConn) Prepare(query string) (driver.Stmt, error) {
+	return &standInStmt{conn: conn, query: query}, nil
+}
+
+func (conn *standInConn) Close() error {
+	return nil
+}
+
+func (conn *standInConn) Begin() (driver.Tx, error) {
+	return conn.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (conn *standInConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	conn.tx = &standInTx{conn: conn, pending: map[string]standInRow{}}
+
+	return conn.tx, nil
+}
+
+func (tx *standInTx) Commit() error {
+	defer func() { tx.conn.tx = nil }()
+
+	if tx.aborted {
+		return errStandInAborted
+	}
+
+	tx.conn.standIn.mutex.Lock()
+	defer tx.conn.standIn.mutex.Unlock()
+
+	for id, row := range tx.pending {
+		tx.conn.standIn.rows[id] = row
+	}
+
+	return nil
+}
+
+func (tx *standInTx) Rollback() error {
+	tx.conn.tx = nil
+
+	return nil
+}
+
+func (conn *standInConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	query = strings.TrimSpace(query)
+
+	if conn.tx != nil && conn.tx.aborted && !strings.HasPrefix(query, "ROLLBACK TO SAVEPOINT") {
+		return nil, errStandInAborted
+	}
+
+	result, err := conn.exec(query, args)
+	if err != nil && conn.tx != nil {
+		conn.tx.aborted = true
+	}
+
+	return result, err
+}
+
+//nolint:cyclop
+func (conn *standInConn) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	standIn := conn.standIn
+
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "SELECT pg_advisory_xact_lock"):
+		return driver.ResultNoRows, nil
+	case strings.HasPrefix(query, "CREATE SCHEMA IF NOT EXISTS "):
+		standIn.schemas[strings.TrimPrefix(query, "CREATE SCHEMA IF NOT EXISTS ")] = true
+
+		return driver.ResultNoRows, nil
+	case strings.HasPrefix(query, "SAVEPOINT "):
+		conn.tx.savepoints = append(conn.tx.savepoints, copyRows(conn.tx.pending))
+
+		return driver.ResultNoRows, nil
+	case strings.HasPrefix(query, "ROLLBACK TO SAVEPOINT "):
+		conn.tx.pending = copyRows(conn.tx.savepoints[len(conn.tx.savepoints)-1])
+		conn.tx.aborted = false
+
+		return driver.ResultNoRows, nil
+	case strings.HasPrefix(query, "RELEASE SAVEPOINT "):
+		conn.tx.savepoints = conn.tx.savepoints[:len(conn.tx.savepoints)-1]
+
+		return driver.ResultNoRows, nil
+	case standInTablePattern.MatchString(query):
+		table := standInTablePattern.FindStringSubmatch(query)[1]
+		if !standIn.schemas[strings.Split(table, ".")[0]] {
+			return nil, fmt.Errorf("table [%s] - %w", table, errStandInMissingSchema)
+		}
+
+		standIn.tables[table] = true
+
+		return driver.ResultNoRows, nil
+	case standInInsertPattern.MatchString(query) && strings.Contains(query, "_migrations "):
+		standIn.migrations++
+
+		return driver.RowsAffected(1), nil
+	case standInInsertPattern.MatchString(query):
+		if table := standInInsertPattern.FindStringSubmatch(query)[1]; !standIn.tables[table] {
+			return nil, fmt.Errorf("table [%s] - %w", table, errStandInMissingTable)
+		}
+
+		for _, arg := range args {
+			if value, ok := arg.Value.(string); ok && strings.ContainsRune(value, 0) {
+				return nil, errStandInInvalidText
+			}
+		}
+
+		id := args[0].Value.(string)
+		if _, ok := standIn.rows[id]; ok {
+			return driver.RowsAffected(0), nil
+		}
+
+		if _, ok := conn.tx.pending[id]; ok {
+			return driver.RowsAffected(0), nil
+		}
+
+		conn.tx.pending[id] = standInRow{clusterID: args[1].Value.(string), timestamp: args[5].Value.(time.Time)}
+
+		return driver.RowsAffected(1), nil
+	default:
+		return nil, fmt.Errorf("[%s] - %w", query, errStandInStatement)
+	}
+}
+
+func (conn *standInConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	query = strings.TrimSpace(query)
+
+	if conn.tx != nil && conn.tx.aborted {
+		return nil, errStandInAborted
+	}
+
+	standIn := conn.standIn
+
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "SELECT EXISTS (SELECT 1 FROM pg_namespace"):
+		return &standInRows{column: "exists", values: []driver.Value{standIn.schemas[args[0].Value.(string)]}}, nil
+	case strings.HasPrefix(query, "SELECT COALESCE(MAX(version), 0)"):
+		return &standInRows{column: "version", values: []driver.Value{int64(standIn.migrations)}}, nil
+	case strings.Contains(query, "WHERE id = ANY($1)"):
+		var ids pq.StringArray
+		if err := ids.Scan(args[0].Value); err != nil {
+			return nil, err
+		}
+
+		present := []driver.Value{}
+
+		for _, id := range ids {
+			if _, ok := standIn.rows[id]; ok {
+				present = append(present, id)
+			}
+		}
+
+		return &standInRows{column: "id", values: present}, nil
+	case strings.Contains(query, "WHERE cluster_id = $1"):
+		limit, _ := strconv.Atoi(standInLimitPattern.FindStringSubmatch(query)[1])
+
+		recent := []string{}
+
+		for id, row := range standIn.rows {
+			if row.clusterID == args[0].Value.(string) {
+				recent = append(recent, id)
+			}
+		}
+
+		sort.Slice(recent, func(i, j int) bool {
+			return standIn.rows[recent[i]].timestamp.After(standIn.rows[recent[j]].timestamp)
+		})
+
+		values := []driver.Value{}
+		for i := 0; i < len(recent) && i < limit; i++ {
+			values = append(values, recent[i])
+		}
+
+		return &standInRows{column: "id", values: values}, nil
+	default:
+		return nil, fmt.Errorf("[%s] - %w", query, errStandInStatement)
+	}
+}
+
+type standInStmt struct {
+	conn  *standInConn
+	query string
+}
+
+func (stmt *standInStmt) Close() error {
+	return nil
+}
+
+func (stmt *standInStmt) NumInput() int {
+	return -1
+}
+
+func (stmt *standInStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errStandInStatement
+}
+
+func (stmt *standInStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errStandInStatement
+}
+
+func (stmt *standInStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return stmt.conn.ExecContext(ctx, stmt.query, args)
+}
+
+// standInRows returns a single column of values.
+type standInRows struct {
+	column string
+	values []driver.Value
+	next   int
+}
+
+func (rows *standInRows) Columns() []string {
+	return []string{rows.column}
+}
+
+func (rows *standInRows) Close() error {
+	return nil
+}
+
+func (rows *standInRows) Next(dest []driver.Value) error {
+	if rows.next >= len(rows.values) {
+		return io.EOF
+	}
+
+	dest[0] = rows.values[rows.next]
+	rows.next++
+
+	return nil
+}
+
+func copyRows(rows map[string]standInRow) map[string]standInRow {
+	copied := make(map[string]standInRow, len(rows))
+	for id, row := range rows {
+		copied[id] = row
+	}
+
+	return copied
+}
+
+// newTestPostgres returns a postgres backend which is connected to a stand-in and migrated.
+func newTestPostgres(t *testing.T, standIn *postgresStandIn) *Postgres {
+	t.Helper()
+
+	db := sql.OpenDB(standIn)
+	t.Cleanup(func() { db.Close() })
+
+	// a single connection keeps each transaction on the connection it was started on
+	db.SetMaxOpenConns(1)
+
+	pg := &Postgres{DB: db, Config: &config.PostgresConfig{Schema: "ocm", Table: "service_logs"}}
+
+	if err := pg.migrate(context.TODO()); err != nil {
+		t.Fatalf("Postgres.migrate() error = %v", err)
+	}
+
+	return pg
+}
+
+func TestPostgres_migrate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		schemas []string
+	}{
+		{
+			name:    "ensure a missing schema is created",
+			schemas: []string{},
+		},
+		{
+			name:    "ensure an existing schema is used",
+			schemas: []string{"ocm"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			standIn := newPostgresStandIn(tt.schemas...)
+			pg := newTestPostgres(t, standIn)
+
+			// migrating again, as on a restart, applies no migrations
+			if err := pg.migrate(context.TODO()); err != nil {
+				t.Fatalf("Postgres.migrate() error = %v", err)
+			}
+
+			if !standIn.schemas["ocm"] || !standIn.tables["ocm.service_logs"] {
+				t.Errorf("Postgres.migrate() schemas = %v, tables = %v, want ocm.service_logs", standIn.schemas, standIn.tables)
+			}
+
+			if want := len(migrations("ocm", "service_logs")); standIn.migrations != want {
+				t.Errorf("Postgres.migrate() migrations = %d, want %d", standIn.migrations, want)
+			}
+		})
+	}
+}
+
+func TestPostgres_Send(t *testing.T) {
+	t.Parallel()
+
+	standIn := newPostgresStandIn()
+	pg := newTestPostgres(t, standIn)
+	proc := &processor.Processor{Config: &config.Config{ClusterID: "cluster"}, Context: context.TODO()}
+
+	response := testResponse(t,
+		testLogEntry{id: "1", summary: "upgrade"},
+		testLogEntry{id: "2", summary: "nul \x00 character"},
+		testLogEntry{id: "3", summary: "upgrade"},
+	)
+
+	// a row which the database rejects is skipped without losing the rest of the batch
+	if err := pg.Send(proc, response); err != nil {
+		t.Fatalf("Postgres.Send() error = %v", err)
+	}
+
+	if got := strings.Join(standIn.ids(), ","); got != "1,3" {
+		t.Errorf("Postgres.Send() rows = %v, want %v", got, "1,3")
+	}
+
+	if got := strings.Join(pg.SentMessages, ","); got != "1,3" {
+		t.Errorf("Postgres.Send() sent = %v, want %v", got, "1,3")
+	}
+
+	// a restart inserts existing rows again, which are ignored by the database and treated as sent
+	restarted := &Postgres{DB: pg.DB, Config: pg.Config}
+
+	if err := restarted.Send(proc, testResponse(t, testLogEntry{id: "1"}, testLogEntry{id: "4"})); err != nil {
+		t.Fatalf("Postgres.Send() error = %v", err)
+	}
+
+	if got := strings.Join(standIn.ids(), ","); got != "1,3,4" {
+		t.Errorf("Postgres.Send() rows = %v, want %v", got, "1,3,4")
+	}
+
+	if got := strings.Join(restarted.SentMessages, ","); got != "1,4" {
+		t.Errorf("Postgres.Send() sent = %v, want %v", got, "1,4")
+	}
+}
+
+func TestPostgres_lookup(t *testing.T) {
+	t.Parallel()
+
+	standIn := newPostgresStandIn()
+	pg := newTestPostgres(t, standIn)
+	proc := &processor.Processor{Config: &config.Config{ClusterID: "cluster"}, Context: context.TODO()}
+
+	now := time.Now()
+
+	if err := pg.Send(proc, testResponse(t,
+		testLogEntry{id: "old", timestamp: now.Add(-time.Hour)},
+		testLogEntry{id: "new", timestamp: now},
+		testLogEntry{id: "other", cluster: "other", timestamp: now},
+	)); err != nil {
+		t.Fatalf("Postgres.Send() error = %v", err)
+	}
+
+	recent, err := pg.Recent(proc)
+	if err != nil {
+		t.Fatalf("Postgres.Recent() error = %v", err)
+	}
+
+	if got := strings.Join(recent, ","); got != "new,old" {
+		t.Errorf("Postgres.Recent() = %v, want %v", got, "new,old")
+	}
+
+	present, err := pg.Present(proc, []string{"old", "missing", "other"})
+	if err != nil {
+		t.Fatalf("Postgres.Present() error = %v", err)
+	}
+
+	if got := strings.Join(present, ","); got != "old,other" {
+		t.Errorf("Postgres.Present() = %v, want %v", got, "old,other")
+	}
+}
+
+type testLogEntry struct {
+	id        string
+	cluster   string
+	summary   string
+	timestamp time.Time
+}
+
+func testResponse(t *testing.T, entries ...testLogEntry) *poller.Response {
+	t.Helper()
+
+	response := &poller.Response{}
+
+	for _, entry := range entries {
+		if entry.cluster == "" {
+			entry.cluster = "cluster"
+		}
+
+		if entry.timestamp.IsZero() {
+			entry.timestamp = time.Now()
+		}
+
+		logEntry, err := v1.NewLogEntry().
+			ID(entry.id).
+			ClusterID(entry.cluster).
+			Summary(entry.summary).
+			Timestamp(entry.timestamp).
+			Build()
+		if err != nil {
+			t.Fatalf("unable to build log entry - %v", err)
+		}
+
+		response.Logs = append(response.Logs, logEntry)
+	}
+
+	return response
+}
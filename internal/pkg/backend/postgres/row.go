@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// PostgresRow represents the values inserted into the service log table for a service log.
+type PostgresRow struct {
+	ID             string
+	ClusterID      string
+	ClusterUUID    sql.NullString
+	SubscriptionID sql.NullString
+	EventStreamID  sql.NullString
+	Timestamp      time.Time
+	Severity       sql.NullString
+	ServiceName    sql.NullString
+	LogType        sql.NullString
+	Summary        sql.NullString
+	Description    sql.NullString
+	Username       sql.NullString
+	InternalOnly   bool
+	Entry          string
+}
+
+// buildRow builds a row from a service log message.  Severities which are not part of the
+// severity enum are stored as null; the original value remains available in the entry.
+func buildRow(logEntry *v1.LogEntry) (*PostgresRow, error) {
+	entry, err := utils.MarshalLogEntry(logEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := logEntry.Timestamp()
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	return &PostgresRow{
+		ID:             logEntry.ID(),
+		ClusterID:      logEntry.ClusterID(),
+		ClusterUUID:    nullString(logEntry.ClusterUUID()),
+		SubscriptionID: nullString(logEntry.SubscriptionID()),
+		EventStreamID:  nullString(logEntry.EventStreamID()),
+		Timestamp:      timestamp.UTC(),
+		Severity:       nullString(severity(logEntry.Severity())),
+		ServiceName:    nullString(logEntry.ServiceName()),
+		LogType:        nullString(string(logEntry.LogType())),
+		Summary:        nullString(logEntry.Summary()),
+		Description:    nullString(logEntry.Description()),
+		Username:       nullString(logEntry.Username()),
+		InternalOnly:   logEntry.InternalOnly(),
+		Entry:          string(entry),
+	}, nil
+}
+
+// columns returns the columns which are inserted for each service log, in the order of the values
+// of a row.
+func columns() []string {
+	return []string{
+		"id",
+		"cluster_id",
+		"cluster_uuid",
+		"subscription_id",
+		"event_stream_id",
+		`"timestamp"`,
+		"severity",
+		"service_name",
+		"log_type",
+		"summary",
+		"description",
+		"username",
+		"internal_only",
+		"entry",
+	}
+}
+
+// Values returns the values of the row in the order of the inserted columns.
+func (row *PostgresRow) Values() []interface{} {
+	return []interface{}{
+		row.ID,
+		row.ClusterID,
+		row.ClusterUUID,
+		row.SubscriptionID,
+		row.EventStreamID,
+		row.Timestamp,
+		row.Severity,
+		row.ServiceName,
+		row.LogType,
+		row.Summary,
+		row.Description,
+		row.Username,
+		row.InternalOnly,
+		row.Entry,
+	}
+}
+
+// severity returns the value of the severity enum for a service log severity, which is matched
+// case insensitively, or an empty string if the severity is unknown.
+func severity(logSeverity v1.Severity) string {
+	for _, known := range []v1.Severity{
+		v1.SeverityDebug,
+		v1.SeverityInfo,
+		v1.SeverityWarning,
+		v1.SeverityError,
+		v1.SeverityFatal,
+		utils.SeverityCritical,
+	} {
+		if strings.EqualFold(string(logSeverity), string(known)) {
+			return string(known)
+		}
+	}
+
+	return ""
+}
+
+// nullString returns a null string for empty values.
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+func Test_buildRow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		severity     v1.Severity
+		username     string
+		wantSeverity string
+		wantUsername bool
+	}{
+		{
+			name:         "ensure known severity is stored as the enum value",
+			severity:     "warning",
+			username:     "admin",
+			wantSeverity: "Warning",
+			wantUsername: true,
+		},
+		{
+			name:         "ensure unknown severity and empty values are stored as null",
+			severity:     "Unknown",
+			wantSeverity: "",
+			wantUsername: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().
+				ID("id").
+				ClusterID("cluster").
+				Severity(tt.severity).
+				Username(tt.username).
+				Timestamp(time.Date(2023, 4, 5, 10, 44, 53, 0, time.FixedZone("east", 3600))).
+				Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			row, err := buildRow(logEntry)
+			if err != nil {
+				t.Fatalf("buildRow() error = %v", err)
+			}
+
+			if row.Severity.String != tt.wantSeverity || row.Severity.Valid != (tt.wantSeverity != "") {
+				t.Errorf("buildRow() severity = %v, want %v", row.Severity, tt.wantSeverity)
+			}
+
+			if row.Username.Valid != tt.wantUsername {
+				t.Errorf("buildRow() username = %v, want valid %v", row.Username, tt.wantUsername)
+			}
+
+			if row.Timestamp.Location() != time.UTC || row.Timestamp.Hour() != 9 {
+				t.Errorf("buildRow() timestamp = %v, want utc", row.Timestamp)
+			}
+
+			entry := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(row.Entry), &entry); err != nil || entry["id"] != "id" {
+				t.Errorf("buildRow() entry = %v, want the full service log", row.Entry)
+			}
+
+			if len(row.Values()) != len(columns()) {
+				t.Errorf("buildRow() values = %d, want %d columns", len(row.Values()), len(columns()))
+			}
+		})
+	}
+}
+
+func Test_insertStatement(t *testing.T) {
+	t.Parallel()
+
+	want := `INSERT INTO public.service_logs (id, cluster_id, cluster_uuid, subscription_id, event_stream_id, "timestamp", ` +
+		`severity, service_name, log_type, summary, description, username, internal_only, entry) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) ON CONFLICT (id) DO NOTHING`
+
+	if got := insertStatement("public.service_logs"); got != want {
+		t.Errorf("insertStatement() = %v, want %v", got, want)
+	}
+}
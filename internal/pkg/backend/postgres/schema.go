@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// migrations returns the statements which create and migrate the schema of the service log
+// table, in the order they are applied.  Each migration is applied at most once and its index
+// is recorded as the schema version, so existing migrations must never be changed; new columns
+// or indexes are added as new migrations.
+func migrations(schema, table string) []string {
+	replacer := strings.NewReplacer("{schema}", schema, "{table}", table)
+
+	return []string{
+		// 1: the service log table with typed columns for querying and the full entry as jsonb
+		replacer.Replace(`
+DO $$ BEGIN
+	CREATE TYPE {schema}.{table}_severity AS ENUM ('Debug', 'Info', 'Warning', 'Error', 'Fatal', 'Critical');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS {schema}.{table} (
+	id              text PRIMARY KEY,
+	cluster_id      text NOT NULL,
+	cluster_uuid    text,
+	subscription_id text,
+	event_stream_id text,
+	"timestamp"     timestamptz NOT NULL,
+	severity        {schema}.{table}_severity,
+	service_name    text,
+	log_type        text,
+	summary         text,
+	description     text,
+	username        text,
+	internal_only   boolean NOT NULL DEFAULT false,
+	entry           jsonb NOT NULL,
+	inserted_at     timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS {table}_cluster_id_timestamp_idx ON {schema}.{table} (cluster_id, "timestamp" DESC);
+CREATE INDEX IF NOT EXISTS {table}_severity_idx ON {schema}.{table} (severity);`),
+	}
+}
+
+// migrate creates the schema of the service log table or migrates it to the latest version.  An
+// advisory lock is held for the duration of the migration so that multiple forwarders sharing a
+// database do not migrate it concurrently.  The database schema is created if it does not exist,
+// which requires the create privilege on the database.
+func (pg *Postgres) migrate(ctx context.Context) error {
+	tx, err := pg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin migration - %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", pg.qualifiedTable()); err != nil {
+		return fmt.Errorf("unable to lock [%s] for migration - %w", pg.qualifiedTable(), err)
+	}
+
+	if err := pg.ensureSchema(ctx, tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s.%s_migrations (version integer PRIMARY KEY, applied_at timestamptz NOT NULL DEFAULT now())`,
+		pg.Config.Schema,
+		pg.Config.Table,
+	)); err != nil {
+		return fmt.Errorf("unable to create migrations table for [%s] - %w", pg.qualifiedTable(), err)
+	}
+
+	version, err := pg.schemaVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for index, migration := range migrations(pg.Config.Schema, pg.Config.Table) {
+		if index < version {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, migration); err != nil {
+			return fmt.Errorf("unable to apply migration [%d] to [%s] - %w", index+1, pg.qualifiedTable(), err)
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"INSERT INTO %s.%s_migrations (version) VALUES ($1)",
+			pg.Config.Schema,
+			pg.Config.Table,
+		), index+1); err != nil {
+			return fmt.Errorf("unable to record migration [%d] of [%s] - %w", index+1, pg.qualifiedTable(), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit migration of [%s] - %w", pg.qualifiedTable(), err)
+	}
+
+	return nil
+}
+
+// schemaVersion returns the number of migrations which have been applied.
+func (pg *Postgres) schemaVersion(ctx context.Context, tx *sql.Tx) (int, error) {
+	var version int
+
+	if err := tx.QueryRowContext(ctx, fmt.Sprintf(
+		"SELECT COALESCE(MAX(version), 0) FROM %s.%s_migrations",
+		pg.Config.Schema,
+		pg.Config.Table,
+	)).Scan(&version); err != nil {
+		return 0, fmt.Errorf("unable to retrieve schema version of [%s] - %w", pg.qualifiedTable(), err)
+	}
+
+	return version, nil
+}
+
+// ensureSchema creates the database schema of the service log table if it does not exist.  The
+// schema is looked up first, as creating a schema requires the create privilege on the database
+// even if it already exists.
+func (pg *Postgres) ensureSchema(ctx context.Context, tx *sql.Tx) error {
+	var exists bool
+
+	if err := tx.QueryRowContext(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1)",
+		pg.Config.Schema,
+	).Scan(&exists); err != nil {
+		return fmt.Errorf("unable to retrieve schema [%s] - %w", pg.Config.Schema, err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pg.Config.Schema)); err != nil {
+		return fmt.Errorf("unable to create schema [%s] - %w", pg.Config.Schema, err)
+	}
+
+	return nil
+}
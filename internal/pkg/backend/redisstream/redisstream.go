@@ -0,0 +1,191 @@
+package redisstream
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/redisclient"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+var (
+	ErrRedisReplicasMissing = errors.New("entry was not acknowledged by enough replicas")
+)
+
+const (
+	// Fields of a stream entry.
+	entryFieldMessageID  = "message_id"
+	entryFieldClusterID  = "cluster_id"
+	entryFieldSeverity   = "severity"
+	entryFieldServiceLog = "service_log"
+
+	// dedupKeySuffix is appended to the stream name, followed by the service log id, to build the
+	// key which records that a service log was added to the stream.
+	dedupKeySuffix = ":message_id:"
+
+	// appendScript adds an entry to a stream unless its deduplication key exists, in which case it
+	// returns nil.  Both happen atomically, so a service log is only added once even if several
+	// forwarders publish it.
+	//
+	// KEYS[1] is the stream, KEYS[2] is the deduplication key, ARGV[1] is the ttl of the
+	// deduplication key in seconds or 0 to disable deduplication, ARGV[2] is the approximate
+	// maximum length of the stream or 0 for no limit, and the remaining arguments are the fields
+	// and values of the entry.
+	appendScript = `
+if ARGV[1] ~= '0' and not redis.call('SET', KEYS[2], '1', 'NX', 'EX', ARGV[1]) then
+  return nil
+end
+
+local args = {'XADD', KEYS[1]}
+if ARGV[2] ~= '0' then
+  table.insert(args, 'MAXLEN')
+  table.insert(args, '~')
+  table.insert(args, ARGV[2])
+end
+table.insert(args, '*')
+
+for i = 3, #ARGV do
+  table.insert(args, ARGV[i])
+end
+
+return redis.call(unpack(args))
+`
+)
+
+// RedisStream is a backend which adds each service log as an entry to a redis stream.  The
+// service log id is stored in the 'message_id' field of the entry and is used to discard
+// duplicates.
+type RedisStream struct {
+	Client       *redis.Client
+	Script       *redis.Script
+	Config       *config.RedisConfig
+	SentMessages []string
+}
+
+func (rs *RedisStream) Initialize(proc *processor.Processor) error {
+	redisConfig, err := config.GetRedisConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure redis backend - %w", err)
+	}
+
+	rs.Config = redisConfig
+
+	if rs.Client, err = redisclient.New(proc, &rs.Config.RedisConnectionConfig); err != nil {
+		return err
+	}
+
+	rs.Script = redis.NewScript(appendScript)
+
+	return nil
+}
+
+func (rs *RedisStream) Send(proc *processor.Processor, response *poller.Response) error {
+	var added, duplicates int
+
+	for _, logEntry := range response.Logs {
+		if rs.HasSent(logEntry) {
+			continue
+		}
+
+		duplicate, err := rs.add(proc, logEntry)
+		if err != nil {
+			rs.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to add stream entry")
+
+			continue
+		}
+
+		if duplicate {
+			duplicates++
+		}
+
+		rs.SentMessages = append(rs.SentMessages, logEntry.ID())
+		added++
+	}
+
+	if added > 0 {
+		rs.Log(
+			log.Info().Str("cluster", proc.Config.ClusterID).Int("entry_count", added).Int("duplicate_count", duplicates),
+			"added service logs to redis stream",
+		)
+	}
+
+	return nil
+}
+
+func (rs *RedisStream) String() string {
+	return config.DefaultBackendRedis
+}
+
+func (rs *RedisStream) HasSent(message *v1.LogEntry) bool {
+	for i := range rs.SentMessages {
+		if message.ID() == rs.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (rs *RedisStream) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", rs.String())).Msg(message)
+}
+
+// add adds a service log to its stream and waits for the entry to be acknowledged.  It returns
+// whether the service log was discarded as a duplicate.
+func (rs *RedisStream) add(proc *processor.Processor, logEntry *v1.LogEntry) (bool, error) {
+	data, err := utils.MarshalLogEntry(logEntry)
+	if err != nil {
+		return false, fmt.Errorf("unable to build stream entry - %w", err)
+	}
+
+	stream := utils.ResolveTemplate(rs.Config.Stream, utils.TemplateFields(proc.Config.ClusterID, logEntry))
+	dedupKey := stream + dedupKeySuffix + logEntry.ID()
+
+	err = rs.Script.Run(
+		proc.Context,
+		rs.Client,
+		[]string{stream, dedupKey},
+		int64(rs.Config.DedupTTL.Seconds()),
+		rs.Config.MaxLength,
+		entryFieldMessageID, logEntry.ID(),
+		entryFieldClusterID, proc.Config.ClusterID,
+		entryFieldSeverity, string(logEntry.Severity()),
+		entryFieldServiceLog, string(data),
+	).Err()
+
+	if errors.Is(err, redis.Nil) {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("unable to add entry to stream [%s] - %w", stream, err)
+	}
+
+	if rs.Config.WaitReplicas == 0 {
+		return false, nil
+	}
+
+	// the entry is only considered sent once it is on enough replicas to survive a failover.  if
+	// it is not, the deduplication key is removed so that the service log is added again on the
+	// next poll.
+	replicas, err := rs.Client.Wait(proc.Context, rs.Config.WaitReplicas, rs.Config.Timeout).Result()
+	if err == nil && replicas < int64(rs.Config.WaitReplicas) {
+		err = fmt.Errorf("acknowledged by [%d/%d] replicas - %w", replicas, rs.Config.WaitReplicas, ErrRedisReplicasMissing)
+	}
+
+	if err != nil {
+		rs.Client.Del(proc.Context, dedupKey)
+
+		return false, fmt.Errorf("unable to wait for replicas of stream [%s] - %w", stream, err)
+	}
+
+	return false, nil
+}
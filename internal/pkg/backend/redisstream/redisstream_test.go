@@ -0,0 +1,101 @@
+package redisstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+func TestRedisStream_Send(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		dedupTTL    time.Duration
+		forwarders  int
+		wantEntries int
+	}{
+		{
+			name:        "ensure service logs from multiple forwarders are deduplicated",
+			dedupTTL:    time.Hour,
+			forwarders:  2,
+			wantEntries: 2,
+		},
+		{
+			name:        "ensure service logs are not deduplicated when disabled",
+			dedupTTL:    0,
+			forwarders:  2,
+			wantEntries: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+			proc := &processor.Processor{
+				Config:  &config.Config{ClusterID: "test"},
+				Context: context.Background(),
+			}
+
+			logs := []*v1.LogEntry{buildLogEntry(t, "1", v1.SeverityInfo), buildLogEntry(t, "2", v1.SeverityError)}
+
+			// each forwarder has its own sent messages, as separate processes would
+			for i := 0; i < tt.forwarders; i++ {
+				rs := &RedisStream{
+					Client: client,
+					Script: redis.NewScript(appendScript),
+					Config: &config.RedisConfig{Stream: "ocm:{cluster_id}", DedupTTL: tt.dedupTTL},
+				}
+
+				if err := rs.Send(proc, &poller.Response{Logs: logs}); err != nil {
+					t.Fatalf("RedisStream.Send() error = %v", err)
+				}
+
+				if len(rs.SentMessages) != len(logs) {
+					t.Fatalf("RedisStream.Send() sent = %v, want %d service logs", rs.SentMessages, len(logs))
+				}
+			}
+
+			entries, err := client.XRange(proc.Context, "ocm:test", "-", "+").Result()
+			if err != nil {
+				t.Fatalf("unable to read stream - %v", err)
+			}
+
+			if len(entries) != tt.wantEntries {
+				t.Fatalf("RedisStream.Send() entries = %d, want %d", len(entries), tt.wantEntries)
+			}
+
+			if got := entries[0].Values[entryFieldMessageID]; got != "1" {
+				t.Errorf("RedisStream.Send() entry message_id = %v, want 1", got)
+			}
+
+			if tt.dedupTTL > 0 && server.TTL("ocm:test"+dedupKeySuffix+"1") != tt.dedupTTL {
+				t.Errorf("RedisStream.Send() dedup ttl = %v, want %v", server.TTL("ocm:test"+dedupKeySuffix+"1"), tt.dedupTTL)
+			}
+		})
+	}
+}
+
+func buildLogEntry(t *testing.T, id string, severity v1.Severity) *v1.LogEntry {
+	t.Helper()
+
+	logEntry, err := v1.NewLogEntry().ID(id).Severity(severity).Summary("test summary").Build()
+	if err != nil {
+		t.Fatalf("unable to build log entry - %v", err)
+	}
+
+	return logEntry
+}
@@ -0,0 +1,69 @@
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"path"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// S3Object represents a set of buffered service logs which are written as a single
+// compressed NDJSON object to a partition of the bucket.
+type S3Object struct {
+	Partition string
+	IDs       []string
+	Data      bytes.Buffer
+}
+
+// partition returns the partition of the bucket that a service log is written to in
+// the form of 'cluster_id/yyyy/mm/dd'.
+func partition(logEntry *v1.LogEntry) string {
+	timestamp := logEntry.Timestamp().UTC()
+
+	return fmt.Sprintf("%s/%04d/%02d/%02d", logEntry.ClusterID(), timestamp.Year(), timestamp.Month(), timestamp.Day())
+}
+
+// add adds a service log as a single line of json to the object.
+func (object *S3Object) add(logEntry *v1.LogEntry) error {
+	data, err := utils.MarshalLogEntry(logEntry)
+	if err != nil {
+		return fmt.Errorf("unable to add service log to object - %w", err)
+	}
+
+	object.Data.Write(data)
+	object.Data.WriteByte('\n')
+	object.IDs = append(object.IDs, logEntry.ID())
+
+	return nil
+}
+
+// key returns the key of the object within the bucket.
+func (object *S3Object) key(prefix string, flushTime time.Time) string {
+	return path.Join(
+		prefix,
+		object.Partition,
+		fmt.Sprintf("%s.ndjson.gz", flushTime.UTC().Format("20060102T150405.000000000Z")),
+	)
+}
+
+// compress returns the gzip compressed contents of the object.
+func (object *S3Object) compress() ([]byte, error) {
+	var compressed bytes.Buffer
+
+	writer := gzip.NewWriter(&compressed)
+
+	if _, err := writer.Write(object.Data.Bytes()); err != nil {
+		return nil, fmt.Errorf("unable to compress object - %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("unable to close compressed object - %w", err)
+	}
+
+	return compressed.Bytes(), nil
+}
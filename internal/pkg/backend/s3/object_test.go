@@ -0,0 +1,89 @@
+package s3
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+func TestS3Object(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		prefix        string
+		timestamps    []time.Time
+		wantPartition string
+		wantKey       string
+	}{
+		{
+			name:          "ensure objects are partitioned by cluster and day",
+			timestamps:    []time.Time{time.Date(2023, 4, 5, 23, 59, 0, 0, time.UTC)},
+			wantPartition: "cluster/2023/04/05",
+			wantKey:       "cluster/2023/04/05/20230406T010203.000000000Z.ndjson.gz",
+		},
+		{
+			name:   "ensure prefix is prepended to the key",
+			prefix: "archive/",
+			timestamps: []time.Time{
+				time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2023, 12, 1, 1, 0, 0, 0, time.UTC),
+			},
+			wantPartition: "cluster/2023/12/01",
+			wantKey:       "archive/cluster/2023/12/01/20230406T010203.000000000Z.ndjson.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			object := &S3Object{}
+
+			for i := range tt.timestamps {
+				logEntry, err := v1.NewLogEntry().ID(tt.timestamps[i].String()).ClusterID("cluster").Timestamp(tt.timestamps[i]).Build()
+				if err != nil {
+					t.Fatalf("unable to build log entry - %v", err)
+				}
+
+				if got := partition(logEntry); got != tt.wantPartition {
+					t.Errorf("partition() = %v, want %v", got, tt.wantPartition)
+				}
+
+				object.Partition = partition(logEntry)
+				if err := object.add(logEntry); err != nil {
+					t.Fatalf("S3Object.add() error = %v", err)
+				}
+			}
+
+			if got := object.key(tt.prefix, time.Date(2023, 4, 6, 1, 2, 3, 0, time.UTC)); got != tt.wantKey {
+				t.Errorf("S3Object.key() = %v, want %v", got, tt.wantKey)
+			}
+
+			compressed, err := object.compress()
+			if err != nil {
+				t.Fatalf("S3Object.compress() error = %v", err)
+			}
+
+			reader, err := gzip.NewReader(bytes.NewReader(compressed))
+			if err != nil {
+				t.Fatalf("unable to read compressed object - %v", err)
+			}
+
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("unable to read compressed object - %v", err)
+			}
+
+			if lines := strings.Count(string(data), "\n"); lines != len(tt.timestamps) {
+				t.Errorf("S3Object.compress() lines = %v, want %v", lines, len(tt.timestamps))
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package s3
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	s3ContentType     = "application/x-ndjson"
+	s3ContentEncoding = "gzip"
+)
+
+// S3 is a backend which archives service logs as compressed NDJSON objects to an
+// s3 compatible bucket.  Service logs are buffered in memory and flushed when either
+// the buffered size or the age of the buffer reaches its configured limit.
+type S3 struct {
+	Client        *awss3.Client
+	Config        *config.S3Config
+	Objects       map[string]*S3Object
+	BufferedSince time.Time
+	SentMessages  []string
+}
+
+func (s3 *S3) Initialize(proc *processor.Processor) (err error) {
+	var accessKeyID, secretAccessKey string
+
+	s3.Config, err = config.GetS3Config()
+	if err != nil {
+		return fmt.Errorf("unable to configure s3 backend - %w", err)
+	}
+
+	// retrieve static credentials if requested, otherwise we fall back to the default
+	// credential chain which includes irsa
+	if s3.Config.AuthType == config.DefaultBackendAuthTypeStatic {
+		accessKeyID, secretAccessKey, err = config.GetS3StaticCredentials(proc.KubeClient, proc.Context)
+		if err != nil {
+			return fmt.Errorf("unable to configure static auth type - %w", err)
+		}
+	}
+
+	awsConfig, err := utils.GetAWSConfig(proc.Context, s3.Config.Region, accessKeyID, secretAccessKey)
+	if err != nil {
+		return fmt.Errorf("unable to configure aws - %w", err)
+	}
+
+	s3.Client = awss3.NewFromConfig(awsConfig, func(options *awss3.Options) {
+		if s3.Config.Endpoint != "" {
+			options.BaseEndpoint = aws.String(s3.Config.Endpoint)
+		}
+
+		options.UsePathStyle = s3.Config.ForcePathStyle
+	})
+
+	s3.Objects = map[string]*S3Object{}
+
+	return nil
+}
+
+func (s3 *S3) Send(proc *processor.Processor, response *poller.Response) error {
+	for _, logEntry := range response.Logs {
+		if s3.HasSent(logEntry) || s3.IsBuffered(logEntry) {
+			continue
+		}
+
+		if err := s3.buffer(logEntry); err != nil {
+			s3.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to buffer service log")
+		}
+	}
+
+	if !s3.shouldFlush() {
+		s3.Log(
+			log.Debug().Str("cluster", proc.Config.ClusterID).Int("buffered_bytes", s3.bufferedBytes()),
+			"flush thresholds not reached; buffering service logs",
+		)
+
+		return nil
+	}
+
+	s3.flush(proc)
+
+	return nil
+}
+
+func (s3 *S3) String() string {
+	return config.DefaultBackendS3
+}
+
+func (s3 *S3) HasSent(message *v1.LogEntry) bool {
+	for i := range s3.SentMessages {
+		if message.ID() == s3.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsBuffered determines if a message is buffered but not yet written to the bucket.
+func (s3 *S3) IsBuffered(message *v1.LogEntry) bool {
+	object, ok := s3.Objects[partition(message)]
+	if !ok {
+		return false
+	}
+
+	for i := range object.IDs {
+		if message.ID() == object.IDs[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s3 *S3) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", s3.String())).Msg(message)
+}
+
+// buffer adds a service log to the object for its partition.
+func (s3 *S3) buffer(logEntry *v1.LogEntry) error {
+	key := partition(logEntry)
+
+	object, ok := s3.Objects[key]
+	if !ok {
+		object = &S3Object{Partition: key}
+		s3.Objects[key] = object
+	}
+
+	if s3.BufferedSince.IsZero() {
+		s3.BufferedSince = time.Now()
+	}
+
+	return object.add(logEntry)
+}
+
+// bufferedBytes returns the uncompressed size of all buffered objects.
+func (s3 *S3) bufferedBytes() (size int) {
+	for _, object := range s3.Objects {
+		size += object.Data.Len()
+	}
+
+	return size
+}
+
+// shouldFlush determines if the buffered objects have reached either their size or
+// age limit.
+func (s3 *S3) shouldFlush() bool {
+	if len(s3.Objects) == 0 {
+		return false
+	}
+
+	return s3.bufferedBytes() >= s3.Config.FlushBytes || time.Since(s3.BufferedSince) >= s3.Config.FlushInterval
+}
+
+// flush writes all buffered objects to the bucket.  Objects which fail to write remain
+// buffered and are retried on the next flush.
+func (s3 *S3) flush(proc *processor.Processor) {
+	flushTime := time.Now()
+
+	// write partitions in order so that logging is predictable
+	partitions := make([]string, 0, len(s3.Objects))
+	for key := range s3.Objects {
+		partitions = append(partitions, key)
+	}
+
+	sort.Strings(partitions)
+
+	for _, key := range partitions {
+		object := s3.Objects[key]
+
+		if err := s3.put(proc, object, flushTime); err != nil {
+			s3.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("partition", key), "failed to write s3 object")
+
+			continue
+		}
+
+		// add the messages to the list of sent messages
+		s3.SentMessages = append(s3.SentMessages, object.IDs...)
+		delete(s3.Objects, key)
+	}
+
+	if len(s3.Objects) == 0 {
+		s3.BufferedSince = time.Time{}
+	}
+}
+
+// put writes a single object to the bucket.
+func (s3 *S3) put(proc *processor.Processor, object *S3Object, flushTime time.Time) error {
+	body, err := object.compress()
+	if err != nil {
+		return err
+	}
+
+	input := &awss3.PutObjectInput{
+		Bucket:          aws.String(s3.Config.Bucket),
+		Key:             aws.String(object.key(s3.Config.Prefix, flushTime)),
+		Body:            bytes.NewReader(body),
+		ContentLength:   aws.Int64(int64(len(body))),
+		ContentType:     aws.String(s3ContentType),
+		ContentEncoding: aws.String(s3ContentEncoding),
+	}
+
+	if s3.Config.Encryption != config.DefaultBackendS3EncryptionNone {
+		input.ServerSideEncryption = types.ServerSideEncryption(s3.Config.Encryption)
+	}
+
+	if s3.Config.Encryption == config.DefaultBackendS3EncryptionKMS && s3.Config.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(s3.Config.KMSKeyID)
+	}
+
+	if _, err := s3.Client.PutObject(proc.Context, input); err != nil {
+		return fmt.Errorf("unable to put object [%s] in bucket [%s] - %w", *input.Key, s3.Config.Bucket, err)
+	}
+
+	s3.Log(
+		log.Info().
+			Str("cluster", proc.Config.ClusterID).
+			Str("bucket", s3.Config.Bucket).
+			Str("key", *input.Key).
+			Int("document_count", len(object.IDs)),
+		"wrote service logs to s3",
+	)
+
+	return nil
+}
@@ -0,0 +1,264 @@
+package s3
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+// s3StandIn is a local stand-in for an s3 compatible bucket, which stores the decompressed
+// objects that it receives.  Objects with a key containing the failing value are rejected.
+type s3StandIn struct {
+	mutex   sync.Mutex
+	failing string
+	objects map[string]string
+}
+
+func (standIn *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if standIn.failing != "" && strings.Contains(r.URL.Path, standIn.failing) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+
+		return
+	}
+
+	reader, err := gzip.NewReader(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	standIn.objects[strings.TrimPrefix(r.URL.Path, "/bucket/")] = string(data)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// fail sets the value of the keys which are rejected.  An empty value accepts all keys.
+func (standIn *s3StandIn) fail(failing string) {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	standIn.failing = failing
+}
+
+// ids returns the ids of the service logs in all stored objects, sorted.
+func (standIn *s3StandIn) ids() []string {
+	standIn.mutex.Lock()
+	defer standIn.mutex.Unlock()
+
+	ids := []string{}
+
+	for _, data := range standIn.objects {
+		for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+			logEntry, err := v1.UnmarshalLogEntry(line)
+			if err == nil {
+				ids = append(ids, logEntry.ID())
+			}
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids
+}
+
+func newTestS3(t *testing.T, flushBytes int, flushInterval time.Duration) (*S3, *s3StandIn) {
+	t.Helper()
+
+	standIn := &s3StandIn{objects: map[string]string{}}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	return &S3{
+		Client: awss3.New(awss3.Options{
+			Region:       "us-east-1",
+			Credentials:  credentials.NewStaticCredentialsProvider("id", "secret", ""),
+			BaseEndpoint: aws.String(server.URL),
+			UsePathStyle: true,
+		}),
+		Config: &config.S3Config{
+			Bucket:        "bucket",
+			Encryption:    config.DefaultBackendS3EncryptionNone,
+			FlushBytes:    flushBytes,
+			FlushInterval: flushInterval,
+		},
+		Objects: map[string]*S3Object{},
+	}, standIn
+}
+
+func testResponse(t *testing.T, clusters ...string) *poller.Response {
+	t.Helper()
+
+	response := &poller.Response{}
+
+	for i, cluster := range clusters {
+		logEntry, err := v1.NewLogEntry().
+			ID(cluster + "-" + string(rune('a'+i))).
+			ClusterID(cluster).
+			Summary("test").
+			Timestamp(time.Date(2023, 4, 5, 0, i, 0, 0, time.UTC)).
+			Build()
+		if err != nil {
+			t.Fatalf("unable to build log entry - %v", err)
+		}
+
+		response.Logs = append(response.Logs, logEntry)
+	}
+
+	return response
+}
+
+func TestS3_Send(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		flushBytes    int
+		flushInterval time.Duration
+		age           time.Duration
+		wantSent      []string
+	}{
+		{
+			name:          "ensure service logs are buffered below the flush thresholds",
+			flushBytes:    1 << 20,
+			flushInterval: time.Hour,
+			wantSent:      []string{},
+		},
+		{
+			name:          "ensure service logs are flushed when the buffered size is reached",
+			flushBytes:    1,
+			flushInterval: time.Hour,
+			wantSent:      []string{"one-a", "one-b", "two-c"},
+		},
+		{
+			name:          "ensure service logs are flushed when the buffer is older than the interval",
+			flushBytes:    1 << 20,
+			flushInterval: time.Hour,
+			age:           2 * time.Hour,
+			wantSent:      []string{"one-a", "one-b", "two-c"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s3, standIn := newTestS3(t, tt.flushBytes, tt.flushInterval)
+			proc := &processor.Processor{Config: &config.Config{ClusterID: "one"}, Context: context.TODO()}
+			response := testResponse(t, "one", "one", "two")
+
+			if err := s3.Send(proc, response); err != nil {
+				t.Fatalf("S3.Send() error = %v", err)
+			}
+
+			// the same service logs are returned by the next poll, once the buffer has aged
+			if tt.age > 0 {
+				s3.BufferedSince = s3.BufferedSince.Add(-tt.age)
+
+				if err := s3.Send(proc, response); err != nil {
+					t.Fatalf("S3.Send() error = %v", err)
+				}
+			}
+
+			if got := strings.Join(standIn.ids(), ","); got != strings.Join(tt.wantSent, ",") {
+				t.Errorf("S3.Send() uploaded = %v, want %v", got, tt.wantSent)
+			}
+
+			if got := strings.Join(s3.SentMessages, ","); got != strings.Join(tt.wantSent, ",") {
+				t.Errorf("S3.Send() sent = %v, want %v", got, tt.wantSent)
+			}
+
+			for _, logEntry := range response.Logs {
+				// a service log is either buffered or sent, but never both
+				if sent, buffered := s3.HasSent(logEntry), s3.IsBuffered(logEntry); sent == buffered {
+					t.Errorf("S3.Send() service log [%s] sent = %v, buffered = %v", logEntry.ID(), sent, buffered)
+				}
+			}
+
+			if flushed := len(tt.wantSent) > 0; flushed != s3.BufferedSince.IsZero() {
+				t.Errorf("S3.Send() buffered since = %v, want reset = %v", s3.BufferedSince, flushed)
+			}
+		})
+	}
+}
+
+func TestS3_Send_failure(t *testing.T) {
+	t.Parallel()
+
+	s3, standIn := newTestS3(t, 1, time.Hour)
+	proc := &processor.Processor{Config: &config.Config{ClusterID: "one"}, Context: context.TODO()}
+	response := testResponse(t, "one", "two")
+
+	// a failed upload keeps its object buffered while the other partitions are written
+	standIn.fail("two/")
+
+	if err := s3.Send(proc, response); err != nil {
+		t.Fatalf("S3.Send() error = %v", err)
+	}
+
+	if got := strings.Join(s3.SentMessages, ","); got != "one-a" {
+		t.Errorf("S3.Send() sent = %v, want %v", got, "one-a")
+	}
+
+	if !s3.IsBuffered(response.Logs[1]) || s3.HasSent(response.Logs[1]) {
+		t.Errorf("S3.Send() service log [%s] is not buffered after a failed upload", response.Logs[1].ID())
+	}
+
+	if s3.BufferedSince.IsZero() {
+		t.Errorf("S3.Send() buffered since was reset with objects remaining")
+	}
+
+	// the remaining object is written on the next flush
+	standIn.fail("")
+
+	if err := s3.Send(proc, response); err != nil {
+		t.Fatalf("S3.Send() error = %v", err)
+	}
+
+	if got := strings.Join(standIn.ids(), ","); got != "one-a,two-b" {
+		t.Errorf("S3.Send() uploaded = %v, want %v", got, "one-a,two-b")
+	}
+
+	if got := strings.Join(s3.SentMessages, ","); got != "one-a,two-b" {
+		t.Errorf("S3.Send() sent = %v, want %v", got, "one-a,two-b")
+	}
+
+	if len(s3.Objects) != 0 || !s3.BufferedSince.IsZero() {
+		t.Errorf("S3.Send() objects = %d, buffered since = %v, want empty buffer", len(s3.Objects), s3.BufferedSince)
+	}
+}
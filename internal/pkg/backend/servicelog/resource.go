@@ -0,0 +1,123 @@
+package servicelog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/json"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	serviceLogGroup    = "ocm-log-forwarder.io"
+	serviceLogVersion  = "v1alpha1"
+	serviceLogResource = "servicelogs"
+	serviceLogKind     = "ServiceLog"
+
+	// resources are named after a hash of the exact service log id, as ids are not guaranteed to
+	// be valid resource names and lowercasing them may make two ids collide.
+	serviceLogNamePrefix     = "ocm-service-log"
+	serviceLogNameHashLength = 32
+
+	serviceLogLabelID       = "ocm-log-forwarder/id"
+	serviceLogLabelCluster  = "ocm-log-forwarder/cluster-id"
+	serviceLogLabelSeverity = "ocm-log-forwarder/severity"
+	serviceLogLabelService  = "ocm-log-forwarder/service"
+
+	labelValueMaxLength = 63
+)
+
+// resource returns the group, version and resource of the servicelog custom resource.
+func resource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: serviceLogGroup, Version: serviceLogVersion, Resource: serviceLogResource}
+}
+
+// buildResource builds a servicelog custom resource from a service log.  The spec mirrors the
+// fields of the service log as they are returned by the OCM API, including its exact id, and the
+// resource is named after the service log id so that a service log is never stored more than once.
+func buildResource(clusterID, namespace string, logEntry *v1.LogEntry) (*unstructured.Unstructured, error) {
+	data, err := utils.MarshalLogEntry(logEntry)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build servicelog resource - %w", err)
+	}
+
+	// the apimachinery json package decodes numbers as integers where possible, which is required
+	// by unstructured objects
+	spec := map[string]interface{}{}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("unable to build servicelog resource [%s] - %w", logEntry.ID(), err)
+	}
+
+	delete(spec, "kind")
+
+	object := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	object.SetAPIVersion(serviceLogGroup + "/" + serviceLogVersion)
+	object.SetKind(serviceLogKind)
+	object.SetName(resourceName(logEntry.ID()))
+	object.SetNamespace(namespace)
+	object.SetLabels(map[string]string{
+		serviceLogLabelID:       labelValue(logEntry.ID()),
+		serviceLogLabelCluster:  labelValue(clusterID),
+		serviceLogLabelSeverity: labelValue(string(logEntry.Severity())),
+		serviceLogLabelService:  labelValue(logEntry.ServiceName()),
+	})
+
+	return object, nil
+}
+
+// resourceName returns the name of the servicelog custom resource of a service log id, which is a
+// valid dns subdomain name for any id.
+func resourceName(id string) string {
+	hash := sha256.Sum256([]byte(id))
+
+	return fmt.Sprintf("%s.%s", serviceLogNamePrefix, hex.EncodeToString(hash[:])[:serviceLogNameHashLength])
+}
+
+// resourceID returns the exact id of the service log of a servicelog custom resource.
+func resourceID(object *unstructured.Unstructured) string {
+	id, _, _ := unstructured.NestedString(object.Object, "spec", "id")
+
+	return id
+}
+
+// resourceTimestamp returns the timestamp of the service log of a servicelog custom resource, or
+// the creation timestamp of the resource if the service log has none.
+func resourceTimestamp(object *unstructured.Unstructured) time.Time {
+	if value, found, err := unstructured.NestedString(object.Object, "spec", "timestamp"); err == nil && found {
+		if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+			return timestamp
+		}
+	}
+
+	return object.GetCreationTimestamp().Time
+}
+
+// labelValue converts a value into a valid label value by replacing invalid characters and
+// truncating it to the maximum length.
+func labelValue(value string) string {
+	valid := strings.Map(func(character rune) rune {
+		switch {
+		case character >= 'a' && character <= 'z',
+			character >= 'A' && character <= 'Z',
+			character >= '0' && character <= '9',
+			character == '-', character == '_', character == '.':
+			return character
+		default:
+			return '-'
+		}
+	}, value)
+
+	if len(valid) > labelValueMaxLength {
+		valid = valid[:labelValueMaxLength]
+	}
+
+	// label values must begin and end with an alphanumeric character
+	return strings.Trim(valid, "-_.")
+}
@@ -0,0 +1,192 @@
+package servicelog
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+const serviceLogListLimit = 500
+
+var ErrServiceLogNameConflict = errors.New("resource name belongs to another service log")
+
+// ServiceLog is a backend which stores service logs as namespaced servicelog custom resources, so
+// that GitOps and policy tools are able to react to them.
+type ServiceLog struct {
+	Client       dynamic.ResourceInterface
+	Config       *config.ServiceLogConfig
+	SentMessages []string
+}
+
+func (sl *ServiceLog) Initialize(proc *processor.Processor) error {
+	serviceLogConfig, err := config.GetServiceLogConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure servicelog backend - %w", err)
+	}
+
+	sl.Config = serviceLogConfig
+	sl.Client = proc.KubeDynamicClient.Resource(resource()).Namespace(sl.Config.Namespace)
+
+	// list the resources now so that a missing custom resource definition or permissions fail fast
+	if _, err := sl.Client.List(proc.Context, metav1.ListOptions{Limit: 1}); err != nil {
+		return fmt.Errorf("unable to list servicelog resources in namespace [%s] - %w", sl.Config.Namespace, err)
+	}
+
+	return nil
+}
+
+func (sl *ServiceLog) Send(proc *processor.Processor, response *poller.Response) error {
+	var created int
+
+	for _, logEntry := range response.Logs {
+		if sl.HasSent(logEntry) {
+			continue
+		}
+
+		// service logs which are already past the retention would be removed by the next garbage
+		// collection, so they are not created at all
+		if sl.expired(logEntry.Timestamp()) {
+			sl.SentMessages = append(sl.SentMessages, logEntry.ID())
+
+			continue
+		}
+
+		object, err := buildResource(proc.Config.ClusterID, sl.Config.Namespace, logEntry)
+		if err != nil {
+			sl.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to build resource")
+
+			continue
+		}
+
+		// a resource which already exists was created before a restart
+		_, err = sl.Client.Create(proc.Context, object, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			err = sl.existing(proc, object.GetName(), logEntry.ID())
+		}
+
+		if err != nil {
+			sl.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to create resource")
+
+			continue
+		}
+
+		sl.SentMessages = append(sl.SentMessages, logEntry.ID())
+		created++
+	}
+
+	if created > 0 {
+		sl.Log(
+			log.Info().Str("cluster", proc.Config.ClusterID).Str("namespace", sl.Config.Namespace).Int("resource_count", created),
+			"created servicelog resources",
+		)
+	}
+
+	if err := sl.collect(proc); err != nil {
+		sl.Log(log.Err(err).Str("cluster", proc.Config.ClusterID), "failed to garbage collect servicelog resources")
+	}
+
+	return nil
+}
+
+func (sl *ServiceLog) String() string {
+	return config.DefaultBackendServiceLog
+}
+
+func (sl *ServiceLog) HasSent(message *v1.LogEntry) bool {
+	for i := range sl.SentMessages {
+		if message.ID() == sl.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (sl *ServiceLog) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", sl.String())).Msg(message)
+}
+
+// existing verifies that an existing servicelog resource stores the service log with the id, rather
+// than another service log whose id hashes to the same name.
+func (sl *ServiceLog) existing(proc *processor.Processor, name, id string) error {
+	object, err := sl.Client.Get(proc.Context, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to retrieve servicelog resource [%s/%s] - %w", sl.Config.Namespace, name, err)
+	}
+
+	if existing := resourceID(object); existing != id {
+		return fmt.Errorf(
+			"servicelog resource [%s/%s] stores service log [%s] - %w",
+			sl.Config.Namespace,
+			name,
+			existing,
+			ErrServiceLogNameConflict,
+		)
+	}
+
+	return nil
+}
+
+// collect deletes the servicelog resources of the cluster whose service logs are older than the
+// retention.
+func (sl *ServiceLog) collect(proc *processor.Processor) error {
+	if sl.Config.Retention == 0 {
+		return nil
+	}
+
+	options := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", serviceLogLabelCluster, labelValue(proc.Config.ClusterID)),
+		Limit:         serviceLogListLimit,
+	}
+
+	var deleted int
+
+	for {
+		list, err := sl.Client.List(proc.Context, options)
+		if err != nil {
+			return fmt.Errorf("unable to list servicelog resources in namespace [%s] - %w", sl.Config.Namespace, err)
+		}
+
+		for i := range list.Items {
+			if !sl.expired(resourceTimestamp(&list.Items[i])) {
+				continue
+			}
+
+			name := list.Items[i].GetName()
+			if err := sl.Client.Delete(proc.Context, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("unable to delete servicelog resource [%s/%s] - %w", sl.Config.Namespace, name, err)
+			}
+
+			deleted++
+		}
+
+		if options.Continue = list.GetContinue(); options.Continue == "" {
+			break
+		}
+	}
+
+	if deleted > 0 {
+		sl.Log(
+			log.Info().Str("cluster", proc.Config.ClusterID).Str("namespace", sl.Config.Namespace).Int("resource_count", deleted),
+			"deleted expired servicelog resources",
+		)
+	}
+
+	return nil
+}
+
+// expired determines if a service log timestamp is older than the retention.
+func (sl *ServiceLog) expired(timestamp time.Time) bool {
+	return sl.Config.Retention > 0 && !timestamp.IsZero() && time.Since(timestamp) > sl.Config.Retention
+}
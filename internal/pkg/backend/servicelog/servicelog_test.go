@@ -0,0 +1,207 @@
+package servicelog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+func TestServiceLog_Send(t *testing.T) {
+	t.Parallel()
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{resource(): serviceLogKind + "List"},
+	)
+
+	sl := &ServiceLog{
+		Client: client.Resource(resource()).Namespace("test"),
+		Config: &config.ServiceLogConfig{Namespace: "test", Retention: 24 * time.Hour},
+	}
+
+	proc := &processor.Processor{
+		Config:  &config.Config{ClusterID: "test"},
+		Context: context.Background(),
+	}
+
+	// a resource created by a previous poll which has since expired
+	expired := buildLogEntry(t, "Expired", time.Now().Add(-48*time.Hour))
+
+	object, err := buildResource(proc.Config.ClusterID, sl.Config.Namespace, expired)
+	if err != nil {
+		t.Fatalf("buildResource() error = %v", err)
+	}
+
+	if _, err := sl.Client.Create(proc.Context, object, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("unable to create resource - %v", err)
+	}
+
+	logs := []*v1.LogEntry{
+		buildLogEntry(t, "Current", time.Now()),
+		buildLogEntry(t, "Old", time.Now().Add(-48*time.Hour)),
+	}
+
+	if err := sl.Send(proc, &poller.Response{Logs: logs}); err != nil {
+		t.Fatalf("ServiceLog.Send() error = %v", err)
+	}
+
+	list, err := sl.Client.List(proc.Context, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unable to list resources - %v", err)
+	}
+
+	if len(list.Items) != 1 || list.Items[0].GetName() != resourceName("Current") {
+		t.Fatalf("ServiceLog.Send() resources = %+v, want only the current service log", list.Items)
+	}
+
+	current := list.Items[0]
+
+	if summary, _, _ := unstructured.NestedString(current.Object, "spec", "summary"); summary != "test summary" {
+		t.Errorf("ServiceLog.Send() spec.summary = %v, want test summary", summary)
+	}
+
+	wantLabels := map[string]string{
+		serviceLogLabelCluster:  "test",
+		serviceLogLabelSeverity: "Error",
+		serviceLogLabelService:  "SRE-Manual-Action",
+	}
+
+	for key, want := range wantLabels {
+		if got := current.GetLabels()[key]; got != want {
+			t.Errorf("ServiceLog.Send() label %s = %v, want %v", key, got, want)
+		}
+	}
+
+	if len(sl.SentMessages) != 2 {
+		t.Errorf("ServiceLog.Send() sent = %v, want 2 service logs", sl.SentMessages)
+	}
+}
+
+func TestServiceLog_Send_existing(t *testing.T) {
+	t.Parallel()
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{resource(): serviceLogKind + "List"},
+	)
+
+	sl := &ServiceLog{
+		Client: client.Resource(resource()).Namespace("test"),
+		Config: &config.ServiceLogConfig{Namespace: "test"},
+	}
+
+	proc := &processor.Processor{
+		Config:  &config.Config{ClusterID: "test"},
+		Context: context.Background(),
+	}
+
+	// a resource created before a restart, and a resource of another service log which was given
+	// the name of a service log, as if their ids collided
+	created := buildLogEntry(t, "Created", time.Now())
+	conflict := buildLogEntry(t, "Conflict", time.Now())
+
+	for name, logEntry := range map[string]*v1.LogEntry{
+		resourceName("Created"):  created,
+		resourceName("Conflict"): buildLogEntry(t, "Other", time.Now()),
+	} {
+		object, err := buildResource(proc.Config.ClusterID, sl.Config.Namespace, logEntry)
+		if err != nil {
+			t.Fatalf("buildResource() error = %v", err)
+		}
+
+		object.SetName(name)
+
+		if _, err := sl.Client.Create(proc.Context, object, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("unable to create resource - %v", err)
+		}
+	}
+
+	// ids which only differ by case are different service logs
+	logs := []*v1.LogEntry{created, conflict, buildLogEntry(t, "abc", time.Now()), buildLogEntry(t, "ABC", time.Now())}
+
+	if err := sl.Send(proc, &poller.Response{Logs: logs}); err != nil {
+		t.Fatalf("ServiceLog.Send() error = %v", err)
+	}
+
+	want := []string{"Created", "abc", "ABC"}
+	if strings.Join(sl.SentMessages, ",") != strings.Join(want, ",") {
+		t.Errorf("ServiceLog.Send() sent = %v, want %v", sl.SentMessages, want)
+	}
+
+	for _, id := range []string{"abc", "ABC"} {
+		object, err := sl.Client.Get(proc.Context, resourceName(id), metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unable to get resource - %v", err)
+		}
+
+		if got := resourceID(object); got != id {
+			t.Errorf("ServiceLog.Send() spec.id = %v, want %v", got, id)
+		}
+	}
+}
+
+func Test_labelValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{
+			name:  "ensure valid values are unchanged",
+			value: "SREManualAction",
+			want:  "SREManualAction",
+		},
+		{
+			name:  "ensure invalid characters are replaced and trimmed",
+			value: " SRE Manual/Action!",
+			want:  "SRE-Manual-Action",
+		},
+		{
+			name:  "ensure long values are truncated",
+			value: "a123456789b123456789c123456789d123456789e123456789f123456789g123456789",
+			want:  "a123456789b123456789c123456789d123456789e123456789f123456789g12",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := labelValue(tt.value); got != tt.want {
+				t.Errorf("labelValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func buildLogEntry(t *testing.T, id string, timestamp time.Time) *v1.LogEntry {
+	t.Helper()
+
+	logEntry, err := v1.NewLogEntry().
+		ID(id).
+		Severity(v1.SeverityError).
+		ServiceName("SRE Manual/Action").
+		Summary("test summary").
+		Timestamp(timestamp).
+		Build()
+	if err != nil {
+		t.Fatalf("unable to build log entry - %v", err)
+	}
+
+	return logEntry
+}
@@ -2,26 +2,59 @@ package stdout
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"sort"
 
 	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/format"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
 )
 
+// StdOut is a backend which writes service logs to stdout, either as log messages of the
+// forwarder, as the rows of a table or, if a formatter is set, as lines in the format of the
+// formatter without any fields of the forwarder.
 type StdOut struct {
+	Config       *config.StdOutConfig
+	Formatter    format.Formatter
+	Writer       io.Writer
 	SentMessages []string
+
+	table *table
 }
 
 func (stdout *StdOut) Initialize(proc *processor.Processor) (err error) {
-	// nothing to initialize with this backend so we simply return
+	stdout.Config, err = config.GetStdOutConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure stdout backend - %w", err)
+	}
+
+	stdout.Writer = os.Stdout
+
+	switch stdout.Config.Format {
+	case config.DefaultBackendStdOutFormatLog:
+	case config.DefaultBackendStdOutFormatTable:
+		stdout.table = newTable(stdout.Writer)
+	default:
+		stdout.Formatter, err = format.NewFormatter(stdout.Config.Format)
+		if err != nil {
+			return fmt.Errorf("unable to configure stdout backend - %w", err)
+		}
+	}
+
 	return nil
 }
 
 func (stdout *StdOut) Send(proc *processor.Processor, response *poller.Response) error {
+	if stdout.Formatter != nil || stdout.table != nil {
+		return stdout.sendOrdered(proc, response)
+	}
+
 	logChan := make(chan *v1.LogEntry, len(response.Logs))
 
 	for i := range response.Logs {
@@ -39,7 +72,38 @@ func (stdout *StdOut) Send(proc *processor.Processor, response *poller.Response)
 			continue
 		}
 
-		stdout.send(logMessage)
+		if err := stdout.send(logMessage); err != nil {
+			stdout.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logMessage.ID()), "failed to write service log")
+		}
+	}
+
+	return nil
+}
+
+// sendOrdered writes formatted lines and table rows oldest first so that the output reads
+// chronologically.
+func (stdout *StdOut) sendOrdered(proc *processor.Processor, response *poller.Response) error {
+	logs := make([]*v1.LogEntry, len(response.Logs))
+	copy(logs, response.Logs)
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Timestamp().Before(logs[j].Timestamp())
+	})
+
+	for _, logEntry := range logs {
+		if stdout.HasSent(logEntry) {
+			continue
+		}
+
+		if err := stdout.send(logEntry); err != nil {
+			stdout.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to write service log")
+		}
+	}
+
+	// the rows of the table are buffered so that their columns are aligned
+	if stdout.table != nil {
+		if err := stdout.table.flush(); err != nil {
+			stdout.Log(log.Err(err).Str("cluster", proc.Config.ClusterID), "failed to write service logs")
+		}
 	}
 
 	return nil
@@ -68,7 +132,34 @@ func (stdout *StdOut) Log(event *zerolog.Event, message string) {
 	event.Str("source", fmt.Sprintf("%s-backend", stdout.String())).Msg(message)
 }
 
-func (stdout *StdOut) send(logEntry *v1.LogEntry) {
+func (stdout *StdOut) send(logEntry *v1.LogEntry) error {
+	if stdout.table != nil {
+		if err := stdout.table.write(logEntry); err != nil {
+			return fmt.Errorf("unable to write service log - %w", err)
+		}
+
+		// add the message to the list of sent messages
+		stdout.SentMessages = append(stdout.SentMessages, logEntry.ID())
+
+		return nil
+	}
+
+	if stdout.Formatter != nil {
+		line, err := stdout.Formatter.Format(logEntry)
+		if err != nil {
+			return fmt.Errorf("unable to format service log - %w", err)
+		}
+
+		if _, err := fmt.Fprintln(stdout.Writer, string(line)); err != nil {
+			return fmt.Errorf("unable to write service log - %w", err)
+		}
+
+		// add the message to the list of sent messages
+		stdout.SentMessages = append(stdout.SentMessages, logEntry.ID())
+
+		return nil
+	}
+
 	// log the message to stdout
 	stdout.Log(
 		log.Info().
@@ -85,4 +176,6 @@ func (stdout *StdOut) send(logEntry *v1.LogEntry) {
 
 	// add the message to the list of sent messages
 	stdout.SentMessages = append(stdout.SentMessages, logEntry.ID())
+
+	return nil
 }
@@ -0,0 +1,164 @@
+package stdout
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/format"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+)
+
+func TestStdOut_Send(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "ensure json is one service log per line without forwarder fields",
+			format: config.DefaultFormatJSON,
+			want: `{"kind":"LogEntry","id":"1","cluster_id":"test","description":"","internal_only":false,` +
+				`"service_name":"SREManualAction","severity":"Warning","summary":"node\treplaced","timestamp":"2023-04-05T10:44:53Z"}` +
+				"\n",
+		},
+		{
+			name:   "ensure logfmt is one service log per line without forwarder fields",
+			format: config.DefaultFormatLogfmt,
+			want: `timestamp=2023-04-05T10:44:53Z id=1 cluster_id=test severity=Warning service_name=SREManualAction ` +
+				`internal_only=false summary="node\treplaced"` + "\n",
+		},
+		{
+			name:   "ensure table has a header once and aligned columns",
+			format: config.DefaultBackendStdOutFormatTable,
+			want: "TIMESTAMP             CLUSTER  SEVERITY  SERVICE          SUMMARY\n" +
+				"2023-04-05T10:44:53Z  test     Warning   SREManualAction  node replaced\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().
+				ID("1").
+				ClusterID("test").
+				Severity(v1.SeverityWarning).
+				ServiceName("SREManualAction").
+				Summary("node\treplaced").
+				Description("").
+				Timestamp(time.Date(2023, 4, 5, 10, 44, 53, 0, time.UTC)).
+				InternalOnly(false).
+				Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			var output bytes.Buffer
+
+			stdout := &StdOut{Config: &config.StdOutConfig{Format: tt.format}, Writer: &output}
+
+			if tt.format == config.DefaultBackendStdOutFormatTable {
+				stdout.table = newTable(stdout.Writer)
+			} else if stdout.Formatter, err = format.NewFormatter(tt.format); err != nil {
+				t.Fatalf("NewFormatter() error = %v", err)
+			}
+
+			proc := &processor.Processor{Config: &config.Config{ClusterID: "test"}}
+
+			// the second response is skipped as the service log was already sent
+			for i := 0; i < 2; i++ {
+				if err := stdout.Send(proc, &poller.Response{Logs: []*v1.LogEntry{logEntry}}); err != nil {
+					t.Fatalf("StdOut.Send() error = %v", err)
+				}
+			}
+
+			if got := output.String(); got != tt.want {
+				t.Errorf("StdOut.Send() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStdOut_Send_order(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format string
+		want   []string
+	}{
+		{
+			name:   "ensure logfmt lines are written oldest first",
+			format: config.DefaultFormatLogfmt,
+			want:   []string{"id=1 ", "id=2 ", "id=3 ", "id=4 ", "id=5 "},
+		},
+		{
+			name:   "ensure table rows are written oldest first",
+			format: config.DefaultBackendStdOutFormatTable,
+			want:   []string{"TIMESTAMP", "summary-1", "summary-2", "summary-3", "summary-4", "summary-5"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			response := &poller.Response{}
+			start := time.Date(2023, 4, 5, 10, 44, 53, 0, time.UTC)
+
+			for _, minute := range []int{3, 5, 1, 4, 2} {
+				logEntry, err := v1.NewLogEntry().
+					ID(strconv.Itoa(minute)).
+					ClusterID("test").
+					Summary(fmt.Sprintf("summary-%d", minute)).
+					Timestamp(start.Add(time.Duration(minute) * time.Minute)).
+					Build()
+				if err != nil {
+					t.Fatalf("unable to build log entry - %v", err)
+				}
+
+				response.Logs = append(response.Logs, logEntry)
+			}
+
+			var (
+				output bytes.Buffer
+				err    error
+			)
+
+			stdout := &StdOut{Config: &config.StdOutConfig{Format: tt.format}, Writer: &output}
+
+			if tt.format == config.DefaultBackendStdOutFormatTable {
+				stdout.table = newTable(stdout.Writer)
+			} else if stdout.Formatter, err = format.NewFormatter(tt.format); err != nil {
+				t.Fatalf("NewFormatter() error = %v", err)
+			}
+
+			if err := stdout.Send(&processor.Processor{Config: &config.Config{ClusterID: "test"}}, response); err != nil {
+				t.Fatalf("StdOut.Send() error = %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("StdOut.Send() =\n%s\nwant %d lines", output.String(), len(tt.want))
+			}
+
+			for i := range lines {
+				if !strings.Contains(lines[i], tt.want[i]) {
+					t.Errorf("StdOut.Send() line %d = %s, want %s", i, lines[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
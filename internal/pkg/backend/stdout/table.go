@@ -0,0 +1,92 @@
+package stdout
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+const (
+	tableMinWidth = 0
+	tableTabWidth = 8
+	tablePadding  = 2
+	tablePadChar  = ' '
+
+	// tableEmpty is the value of an empty column, so that every row has the same number of columns.
+	tableEmpty = "-"
+)
+
+// tableColumns are the columns of the table, in order.
+func tableColumns() []string {
+	return []string{"TIMESTAMP", "CLUSTER", "SEVERITY", "SERVICE", "SUMMARY"}
+}
+
+// table writes service logs as the rows of a human-readable table.  The header is written before
+// the first row, and the columns are aligned across the rows written before each flush.
+type table struct {
+	writer *tabwriter.Writer
+	header bool
+}
+
+func newTable(writer io.Writer) *table {
+	return &table{writer: tabwriter.NewWriter(writer, tableMinWidth, tableTabWidth, tablePadding, tablePadChar, 0)}
+}
+
+func (t *table) write(logEntry *v1.LogEntry) error {
+	if !t.header {
+		if err := t.writeRow(tableColumns()); err != nil {
+			return err
+		}
+
+		t.header = true
+	}
+
+	var timestamp string
+	if !logEntry.Timestamp().IsZero() {
+		timestamp = logEntry.Timestamp().UTC().Format(time.RFC3339)
+	}
+
+	return t.writeRow([]string{
+		timestamp,
+		logEntry.ClusterID(),
+		string(logEntry.Severity()),
+		logEntry.ServiceName(),
+		logEntry.Summary(),
+	})
+}
+
+func (t *table) writeRow(columns []string) error {
+	for i := range columns {
+		columns[i] = tableCell(columns[i])
+	}
+
+	if _, err := fmt.Fprintln(t.writer, strings.Join(columns, "\t")); err != nil {
+		return fmt.Errorf("unable to write table row - %w", err)
+	}
+
+	return nil
+}
+
+func (t *table) flush() error {
+	if err := t.writer.Flush(); err != nil {
+		return fmt.Errorf("unable to flush table - %w", err)
+	}
+
+	return nil
+}
+
+// tableCell replaces the tabs and line breaks of a value, which would break the columns of the
+// table, with spaces.
+func tableCell(value string) string {
+	value = strings.NewReplacer("\t", " ", "\r\n", " ", "\r", " ", "\n", " ").Replace(value)
+
+	if value == "" {
+		return tableEmpty
+	}
+
+	return value
+}
@@ -0,0 +1,153 @@
+package syslog
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: see https://datatracker.ietf.org/doc/html/rfc5424 for the format of a syslog message.
+const (
+	syslogVersion         = 1
+	syslogNilValue        = "-"
+	syslogTimestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+	syslogHostnameMax     = 255
+	syslogAppNameMax      = 48
+	syslogMsgIDMax        = 32
+	syslogBOM             = "\xef\xbb\xbf"
+
+	syslogSeverityCritical      = 2
+	syslogSeverityError         = 3
+	syslogSeverityWarning       = 4
+	syslogSeverityInformational = 6
+	syslogSeverityDebug         = 7
+)
+
+// SyslogMessage represents an RFC 5424 syslog message built from a service log message.
+type SyslogMessage struct {
+	Facility       int
+	Severity       int
+	Timestamp      string
+	Hostname       string
+	AppName        string
+	MsgID          string
+	StructuredData string
+	Message        string
+}
+
+// buildMessage builds a syslog message from a service log message.  The service log fields are
+// carried as parameters of a single structured data element.
+func buildMessage(logEntry *v1.LogEntry, facility int, hostname, appName, sdID string) *SyslogMessage {
+	message := &SyslogMessage{
+		Facility:  facility,
+		Severity:  severity(logEntry.Severity()),
+		Timestamp: syslogNilValue,
+		Hostname:  header(hostname, syslogHostnameMax),
+		AppName:   header(appName, syslogAppNameMax),
+		MsgID:     header(logEntry.ServiceName(), syslogMsgIDMax),
+		StructuredData: structuredData(sdID, [][2]string{
+			{"id", logEntry.ID()},
+			{"cluster_id", logEntry.ClusterID()},
+			{"external_id", logEntry.ClusterUUID()},
+			{"service_name", logEntry.ServiceName()},
+			{"event_stream_id", logEntry.EventStreamID()},
+			{"username", logEntry.Username()},
+			{"severity", string(logEntry.Severity())},
+		}),
+		Message: logEntry.Summary(),
+	}
+
+	if !logEntry.Timestamp().IsZero() {
+		message.Timestamp = logEntry.Timestamp().Format(syslogTimestampFormat)
+	}
+
+	return message
+}
+
+// String returns the message in its RFC 5424 wire format.
+func (message *SyslogMessage) String() string {
+	msg := ""
+	if message.Message != "" {
+		msg = " " + syslogBOM + message.Message
+	}
+
+	return fmt.Sprintf(
+		"<%d>%d %s %s %s %s %s %s%s",
+		message.Facility*8+message.Severity,
+		syslogVersion,
+		message.Timestamp,
+		message.Hostname,
+		message.AppName,
+		syslogNilValue,
+		message.MsgID,
+		message.StructuredData,
+		msg,
+	)
+}
+
+// severity maps a service log severity to a syslog severity.
+func severity(logSeverity v1.Severity) int {
+	switch utils.SeverityLevel(logSeverity) {
+	case utils.SeverityLevelCritical:
+		return syslogSeverityCritical
+	case utils.SeverityLevelError:
+		return syslogSeverityError
+	case utils.SeverityLevelWarning:
+		return syslogSeverityWarning
+	case utils.SeverityLevelDebug:
+		return syslogSeverityDebug
+	default:
+		return syslogSeverityInformational
+	}
+}
+
+// header returns a header field which only contains printable us-ascii characters and
+// does not exceed its maximum length.  Empty fields are returned as the nil value.
+func header(value string, length int) string {
+	printable := strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' {
+			return '_'
+		}
+
+		return r
+	}, value)
+
+	if printable == "" {
+		return syslogNilValue
+	}
+
+	if len(printable) > length {
+		return printable[:length]
+	}
+
+	return printable
+}
+
+// structuredData returns a structured data element with the provided parameters.  Parameters
+// with empty values are omitted.
+func structuredData(sdID string, params [][2]string) string {
+	var element strings.Builder
+
+	element.WriteString("[" + sdID)
+
+	for _, param := range params {
+		if param[1] == "" {
+			continue
+		}
+
+		element.WriteString(fmt.Sprintf(" %s=\"%s\"", param[0], escapeParamValue(param[1])))
+	}
+
+	element.WriteString("]")
+
+	return element.String()
+}
+
+// escapeParamValue escapes the characters which must be escaped in a structured data
+// parameter value.
+func escapeParamValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
@@ -0,0 +1,64 @@
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+func TestSyslogMessage_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		severity v1.Severity
+		service  string
+		summary  string
+		username string
+		want     string
+	}{
+		{
+			name:     "ensure error message is formatted correctly",
+			severity: v1.SeverityError,
+			service:  "SREManualAction",
+			summary:  "Action required",
+			username: "admin",
+			want: `<131>1 2023-04-05T10:44:53.000000Z host ocm-log-forwarder - SREManualAction ` +
+				`[ocm@32473 id="id" cluster_id="cluster" service_name="SREManualAction" username="admin" severity="Error"] ` +
+				"\xef\xbb\xbfAction required",
+		},
+		{
+			name:     "ensure structured data values are escaped and missing header fields are nil",
+			severity: v1.SeverityInfo,
+			username: `a"b\c]d`,
+			want: `<134>1 2023-04-05T10:44:53.000000Z host ocm-log-forwarder - - ` +
+				`[ocm@32473 id="id" cluster_id="cluster" username="a\"b\\c\]d" severity="Info"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().
+				ID("id").
+				ClusterID("cluster").
+				Severity(tt.severity).
+				ServiceName(tt.service).
+				Summary(tt.summary).
+				Username(tt.username).
+				Timestamp(time.Date(2023, 4, 5, 10, 44, 53, 0, time.UTC)).
+				Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			// local0 facility
+			if got := buildMessage(logEntry, 16, "host", "ocm-log-forwarder", "ocm@32473").String(); got != tt.want {
+				t.Errorf("SyslogMessage.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package syslog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/format"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	syslogDialTimeout  = 10 * time.Second
+	syslogWriteTimeout = 10 * time.Second
+)
+
+// Syslog is a backend which sends service logs as RFC 5424 syslog messages over udp, tcp
+// or tls.  Messages sent over tcp and tls use octet counting framing as defined by RFC 6587.
+// The service log is carried as structured data, or, if a formatter is set, as the message in
+// the format of the formatter.
+type Syslog struct {
+	Config       *config.SyslogConfig
+	TLSConfig    *tls.Config
+	Formatter    format.Formatter
+	Connection   net.Conn
+	SentMessages []string
+}
+
+func (syslog *Syslog) Initialize(proc *processor.Processor) (err error) {
+	syslog.Config, err = config.GetSyslogConfig()
+	if err != nil {
+		return fmt.Errorf("unable to configure syslog backend - %w", err)
+	}
+
+	if syslog.Config.Format != config.DefaultBackendSyslogFormatSD {
+		syslog.Formatter, err = format.NewFormatter(syslog.Config.Format)
+		if err != nil {
+			return fmt.Errorf("unable to configure syslog backend - %w", err)
+		}
+	}
+
+	if syslog.Config.Protocol == config.DefaultBackendSyslogProtocolTLS {
+		syslog.TLSConfig, err = getTLSConfig(syslog.Config)
+		if err != nil {
+			return fmt.Errorf("unable to set tls config - %w", err)
+		}
+	}
+
+	// connect immediately so that we fail fast on a bad configuration
+	return syslog.connect()
+}
+
+func (syslog *Syslog) Send(proc *processor.Processor, response *poller.Response) error {
+	for _, logEntry := range response.Logs {
+		if syslog.HasSent(logEntry) {
+			continue
+		}
+
+		if err := syslog.send(logEntry); err != nil {
+			syslog.Log(log.Err(err).Str("cluster", proc.Config.ClusterID).Str("message_id", logEntry.ID()), "failed to send syslog message")
+
+			continue
+		}
+
+		// add the message to the list of sent messages
+		syslog.SentMessages = append(syslog.SentMessages, logEntry.ID())
+	}
+
+	return nil
+}
+
+func (syslog *Syslog) String() string {
+	return config.DefaultBackendSyslog
+}
+
+func (syslog *Syslog) HasSent(message *v1.LogEntry) bool {
+	for i := range syslog.SentMessages {
+		if message.ID() == syslog.SentMessages[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (syslog *Syslog) Log(event *zerolog.Event, message string) {
+	event.Str("source", fmt.Sprintf("%s-backend", syslog.String())).Msg(message)
+}
+
+// connect establishes a connection to the syslog server, closing any existing connection.
+func (syslog *Syslog) connect() (err error) {
+	if syslog.Connection != nil {
+		syslog.Connection.Close()
+		syslog.Connection = nil
+	}
+
+	var connection net.Conn
+
+	switch syslog.Config.Protocol {
+	case config.DefaultBackendSyslogProtocolTLS:
+		connection, err = tls.DialWithDialer(
+			&net.Dialer{Timeout: syslogDialTimeout},
+			config.DefaultBackendSyslogProtocolTCP,
+			syslog.Config.Address,
+			syslog.TLSConfig,
+		)
+	default:
+		connection, err = net.DialTimeout(syslog.Config.Protocol, syslog.Config.Address, syslogDialTimeout)
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to connect to syslog server [%s://%s] - %w", syslog.Config.Protocol, syslog.Config.Address, err)
+	}
+
+	syslog.Connection = connection
+
+	return nil
+}
+
+// send writes a single service log to the syslog server.  Stream connections are re-established
+// once if the write fails, as the server may have closed an idle connection.
+func (syslog *Syslog) send(logEntry *v1.LogEntry) error {
+	syslogMessage := buildMessage(
+		logEntry,
+		syslog.Config.Facility,
+		syslog.Config.Hostname,
+		syslog.Config.AppName,
+		syslog.Config.SDID,
+	)
+
+	if syslog.Formatter != nil {
+		line, err := syslog.Formatter.Format(logEntry)
+		if err != nil {
+			return fmt.Errorf("unable to format syslog message - %w", err)
+		}
+
+		syslogMessage.StructuredData = syslogNilValue
+		syslogMessage.Message = string(line)
+	}
+
+	message := syslogMessage.String()
+
+	frame := []byte(message)
+	if syslog.Config.Protocol != config.DefaultBackendSyslogProtocolUDP {
+		frame = []byte(fmt.Sprintf("%d %s", len(message), message))
+	}
+
+	err := syslog.write(frame)
+	if err == nil {
+		return nil
+	}
+
+	syslog.Log(log.Warn().Err(err), "syslog write failed; reconnecting")
+
+	if connErr := syslog.connect(); connErr != nil {
+		return connErr
+	}
+
+	return syslog.write(frame)
+}
+
+// write writes a frame to the connection.
+func (syslog *Syslog) write(frame []byte) error {
+	if syslog.Connection == nil {
+		return fmt.Errorf("missing connection to syslog server [%s] - %w", syslog.Config.Address, net.ErrClosed)
+	}
+
+	if err := syslog.Connection.SetWriteDeadline(time.Now().Add(syslogWriteTimeout)); err != nil {
+		return fmt.Errorf("unable to set write deadline - %w", err)
+	}
+
+	if _, err := syslog.Connection.Write(frame); err != nil {
+		return fmt.Errorf("unable to write to syslog server - %w", err)
+	}
+
+	return nil
+}
+
+// getTLSConfig returns the tls configuration for a syslog connection, including the
+// optional certificate authority and client certificate.
+func getTLSConfig(syslogConfig *config.SyslogConfig) (*tls.Config, error) {
+	host, _, err := net.SplitHostPort(syslogConfig.Address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse address [%s] - %w", syslogConfig.Address, err)
+	}
+
+	//nolint: gosec
+	tlsConfig := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: !syslogConfig.TLSVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if syslogConfig.TLSCA != "" {
+		pool, err := utils.CertPoolFromFile(syslogConfig.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if syslogConfig.TLSCertificate != "" {
+		certificate, err := tls.LoadX509KeyPair(syslogConfig.TLSCertificate, syslogConfig.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"unable to load key pair: cert=%s, key=%s - %w",
+				syslogConfig.TLSCertificate,
+				syslogConfig.TLSKey,
+				err,
+			)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
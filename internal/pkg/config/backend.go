@@ -2,10 +2,12 @@ package config
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
 
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
@@ -18,6 +20,8 @@ var (
 	ErrBackendAuthSecretFormat    = errors.New("invalid secret format")
 	ErrBackendAuthMissingUsername = errors.New("unable to find username")
 	ErrBackendAuthMissingPassword = errors.New("unable to find password")
+	ErrBackendSecretMissingKey    = errors.New("unable to find key in secret")
+	ErrBackendConfigInvalid       = errors.New("invalid backend configuration")
 )
 
 // NOTE: we are not storing credentials rather pointers to credentials here so
@@ -32,23 +36,45 @@ const (
 	defaultEnvironmentBackendElasticSearchSecretName      = "BACKEND_ES_SECRET_NAME"
 	defaultEnvironmentBackendElasticSearchSecretNamespace = "BACKEND_ES_SECRET_NAMESPACE"
 	defaultEnvironmentBackendElasticIndex                 = "BACKEND_ES_INDEX"
-	DefaultEnvironmentBackendElasticTLSCertificate        = "BACKEND_ES_CERT"
-	DefaultEnvironmentBackendElasticTLSKey                = "BACKEND_ES_KEY"
-	DefaultEnvironmentBackendElasticTLSVerify             = "BACKEND_ES_TLS_VERIFY"
 
 	// Default Settings for Environment Variables.
 	DefaultBackendElasticSearch                = "elasticsearch"
 	DefaultBackendStdOut                       = "stdout"
+	DefaultBackendSlack                        = "slack"
+	DefaultBackendTeams                        = "teams"
+	DefaultBackendPagerDuty                    = "pagerduty"
+	DefaultBackendS3                           = "s3"
+	DefaultBackendCloudWatch                   = "cloudwatch"
+	DefaultBackendSyslog                       = "syslog"
+	DefaultBackendOTLP                         = "otlp"
+	DefaultBackendGELF                         = "gelf"
+	DefaultBackendForward                      = "forward"
+	DefaultBackendOpenSearch                   = "opensearch"
+	DefaultBackendPostgres                     = "postgres"
+	DefaultBackendFile                         = "file"
+	DefaultBackendEvents                       = "events"
+	DefaultBackendServiceLog                   = "servicelog"
+	DefaultBackendNATS                         = "nats"
+	DefaultBackendRedis                        = "redis"
+	DefaultBackendAMQP                         = "amqp"
 	DefaultBackend                             = DefaultBackendElasticSearch
 	DefaultBackendAuthTypeBasic                = "basic"
+	DefaultBackendAuthTypeIRSA                 = "irsa"
+	DefaultBackendAuthTypeStatic               = "static"
+	DefaultBackendAuthTypeAPIKey               = "apikey"
+	DefaultBackendAuthTypeBearer               = "bearer"
+	DefaultBackendAuthTypeNone                 = "none"
 	DefaultBackendElasticSearchAuthType        = DefaultBackendAuthTypeBasic
 	DefaultBackendElasticIndex                 = "ocm_service_logs"
 	defaultBackendElasticSearchURL             = "http://localhost:9200"
 	defaultBackendElasticSearchSecretName      = "elastic-auth"
 	defaultBackendElasticSearchSecretNamespace = "ocm-log-forwarder"
-	DefaultBackendElasticTLSCertificate        = "/etc/pki/tls.crt"
-	DefaultBackendElasticTLSKey                = "/etc/pki/tls.key"
-	DefaultBackendElasticTLSVerify             = "true"
+	defaultBackendElasticSecretAPIKeyEncoded   = "encoded"
+	defaultBackendElasticSecretAPIKeyID        = "id"
+	defaultBackendElasticSecretAPIKey          = "api_key"
+	defaultBackendElasticSecretBearerToken     = "token"
+	defaultBackendAWSSecretAccessKeyID         = "access_key_id"
+	defaultBackendAWSSecretSecretAccessKey     = "secret_access_key"
 )
 
 func GetElasticSearchIndex() string {
@@ -63,12 +89,6 @@ func GetElasticSearchAuthType() string {
 	return utils.FromEnvironment(defaultEnvironmentBackendElasticSearchAuthType, DefaultBackendElasticSearchAuthType)
 }
 
-func GetElasticSearchTLSConnectionInfo() (tlsCert, tlsKey string, tlsVerify bool) {
-	return utils.FromEnvironment(DefaultEnvironmentBackendElasticTLSCertificate, DefaultBackendElasticTLSCertificate),
-		utils.FromEnvironment(DefaultEnvironmentBackendElasticTLSKey, DefaultBackendElasticTLSKey),
-		utils.BoolFromString(utils.FromEnvironment(DefaultEnvironmentBackendElasticTLSVerify, DefaultBackendElasticTLSVerify))
-}
-
 func GetElasticSearchAuthTypeBasic(client *kubernetes.Clientset, ctx context.Context) (username, password string, err error) {
 	secretName, secretNamespace := getElasticSearchAuthSecretName(), getElasticSearchAuthSecretNamespace()
 
@@ -113,6 +133,49 @@ func GetElasticSearchAuthTypeBasic(client *kubernetes.Clientset, ctx context.Con
 	return "", "", ErrBackendAuthUnkownError
 }
 
+// GetElasticSearchAuthTypeAPIKey returns the encoded api key used in the 'ApiKey' authorization
+// header.  The secret either contains the already encoded key in the 'encoded' key, or the 'id'
+// and 'api_key' keys as returned by the elasticsearch create api key api.
+func GetElasticSearchAuthTypeAPIKey(client *kubernetes.Clientset, ctx context.Context) (string, error) {
+	secretName, secretNamespace := getElasticSearchAuthSecretName(), getElasticSearchAuthSecretNamespace()
+
+	secret, err := utils.GetKubernetesSecret(client, ctx, secretName, secretNamespace)
+	if err != nil {
+		return "", fmt.Errorf("error fetching secret containing elasticsearch auth info - %w", err)
+	}
+
+	if encoded := secret.Data[defaultBackendElasticSecretAPIKeyEncoded]; len(encoded) > 0 {
+		return string(encoded), nil
+	}
+
+	id, key := secret.Data[defaultBackendElasticSecretAPIKeyID], secret.Data[defaultBackendElasticSecretAPIKey]
+	if len(id) == 0 || len(key) == 0 {
+		return "", fmt.Errorf(
+			"key [%s] or keys [%s] and [%s] in secret [%s/%s] - %w",
+			defaultBackendElasticSecretAPIKeyEncoded,
+			defaultBackendElasticSecretAPIKeyID,
+			defaultBackendElasticSecretAPIKey,
+			secretNamespace,
+			secretName,
+			ErrBackendSecretMissingKey,
+		)
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(string(id) + ":" + string(key))), nil
+}
+
+// GetElasticSearchAuthTypeBearer returns the token used in the 'Bearer' authorization header,
+// which is stored in the 'token' key of the secret.
+func GetElasticSearchAuthTypeBearer(client *kubernetes.Clientset, ctx context.Context) (string, error) {
+	return getSecretValue(
+		client,
+		ctx,
+		getElasticSearchAuthSecretName(),
+		getElasticSearchAuthSecretNamespace(),
+		defaultBackendElasticSecretBearerToken,
+	)
+}
+
 func getBackendConfig() (string, error) {
 	var backend string
 
@@ -124,6 +187,40 @@ func getBackendConfig() (string, error) {
 		return DefaultBackendElasticSearch, nil
 	case backendType == DefaultBackendStdOut:
 		return DefaultBackendStdOut, nil
+	case backendType == DefaultBackendSlack:
+		return DefaultBackendSlack, nil
+	case backendType == DefaultBackendTeams:
+		return DefaultBackendTeams, nil
+	case backendType == DefaultBackendPagerDuty:
+		return DefaultBackendPagerDuty, nil
+	case backendType == DefaultBackendS3:
+		return DefaultBackendS3, nil
+	case backendType == DefaultBackendCloudWatch:
+		return DefaultBackendCloudWatch, nil
+	case backendType == DefaultBackendSyslog:
+		return DefaultBackendSyslog, nil
+	case backendType == DefaultBackendOTLP:
+		return DefaultBackendOTLP, nil
+	case backendType == DefaultBackendGELF:
+		return DefaultBackendGELF, nil
+	case backendType == DefaultBackendForward:
+		return DefaultBackendForward, nil
+	case backendType == DefaultBackendOpenSearch:
+		return DefaultBackendOpenSearch, nil
+	case backendType == DefaultBackendPostgres:
+		return DefaultBackendPostgres, nil
+	case backendType == DefaultBackendFile:
+		return DefaultBackendFile, nil
+	case backendType == DefaultBackendEvents:
+		return DefaultBackendEvents, nil
+	case backendType == DefaultBackendServiceLog:
+		return DefaultBackendServiceLog, nil
+	case backendType == DefaultBackendNATS:
+		return DefaultBackendNATS, nil
+	case backendType == DefaultBackendRedis:
+		return DefaultBackendRedis, nil
+	case backendType == DefaultBackendAMQP:
+		return DefaultBackendAMQP, nil
 	default:
 		return backend, fmt.Errorf("backend type [%s] - %w", backendType, ErrBackendUnknown)
 	}
@@ -142,3 +239,60 @@ func getElasticSearchAuthSecretNamespace() string {
 		defaultBackendElasticSearchSecretNamespace,
 	)
 }
+
+// getSeverityThreshold returns a validated service log severity from the environment
+// which is used as a minimum severity for backends which filter messages.
+func getSeverityThreshold(variable, def string) (v1.Severity, error) {
+	severity := v1.Severity(utils.FromEnvironment(variable, def))
+
+	if utils.SeverityLevel(severity) == utils.SeverityLevelUnknown {
+		return severity, fmt.Errorf(
+			"severity threshold from environment [%s=%s] - %w",
+			variable,
+			severity,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	return severity, nil
+}
+
+// getSecretValue returns the value of a specific key from a kubernetes secret.
+func getSecretValue(client *kubernetes.Clientset, ctx context.Context, secretName, secretNamespace, key string) (string, error) {
+	secret, err := utils.GetKubernetesSecret(client, ctx, secretName, secretNamespace)
+	if err != nil {
+		return "", fmt.Errorf("error fetching secret [%s/%s] - %w", secretNamespace, secretName, err)
+	}
+
+	value, ok := secret.Data[key]
+	if !ok || len(value) == 0 {
+		return "", fmt.Errorf(
+			"key [%s] in secret [%s/%s] - %w",
+			key,
+			secretNamespace,
+			secretName,
+			ErrBackendSecretMissingKey,
+		)
+	}
+
+	return string(value), nil
+}
+
+// getAWSStaticCredentials returns static aws credentials from a kubernetes secret.
+func getAWSStaticCredentials(
+	client *kubernetes.Clientset,
+	ctx context.Context,
+	secretName, secretNamespace string,
+) (accessKeyID, secretAccessKey string, err error) {
+	accessKeyID, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultBackendAWSSecretAccessKeyID)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve aws access key id - %w", err)
+	}
+
+	secretAccessKey, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultBackendAWSSecretSecretAccessKey)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve aws secret access key - %w", err)
+	}
+
+	return accessKeyID, secretAccessKey, nil
+}
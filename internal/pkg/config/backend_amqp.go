@@ -0,0 +1,131 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendAMQPURL               = "BACKEND_AMQP_URL"
+	defaultEnvironmentBackendAMQPExchange          = "BACKEND_AMQP_EXCHANGE"
+	defaultEnvironmentBackendAMQPRoutingKey        = "BACKEND_AMQP_ROUTING_KEY"
+	defaultEnvironmentBackendAMQPMandatory         = "BACKEND_AMQP_MANDATORY"
+	defaultEnvironmentBackendAMQPAckTimeout        = "BACKEND_AMQP_ACK_TIMEOUT_SECONDS"
+	defaultEnvironmentBackendAMQPReconnectAttempts = "BACKEND_AMQP_RECONNECT_ATTEMPTS"
+	defaultEnvironmentBackendAMQPAuthType          = "BACKEND_AMQP_AUTH_TYPE"
+	defaultEnvironmentBackendAMQPSecretName        = "BACKEND_AMQP_SECRET_NAME"
+	defaultEnvironmentBackendAMQPSecretNamespace   = "BACKEND_AMQP_SECRET_NAMESPACE"
+	defaultEnvironmentBackendAMQPTLSCA             = "BACKEND_AMQP_TLS_CA"
+
+	// Default Settings for Environment Variables.
+	defaultBackendAMQPURL               = "amqp://localhost:5672/"
+	defaultBackendAMQPExchange          = "ocm.service_logs"
+	defaultBackendAMQPRoutingKey        = "ocm.{severity}.{cluster_id}"
+	defaultBackendAMQPMandatory         = "true"
+	defaultBackendAMQPAckTimeout        = 5
+	defaultBackendAMQPReconnectAttempts = 5
+	defaultBackendAMQPAuthType          = DefaultBackendAuthTypeBasic
+	defaultBackendAMQPSecretName        = "amqp-auth"
+	defaultBackendAMQPSecretNamespace   = "ocm-log-forwarder"
+	defaultBackendAMQPSecretUsername    = "username"
+	defaultBackendAMQPSecretPassword    = "password"
+)
+
+// AMQPConfig represents the configuration of the amqp backend.  The routing key is a template
+// which is resolved for each service log.
+type AMQPConfig struct {
+	URL               string
+	Exchange          string
+	RoutingKey        string
+	Mandatory         bool
+	AckTimeout        time.Duration
+	ReconnectAttempts int
+	AuthType          string
+	TLSCA             string
+}
+
+// GetAMQPConfig returns the validated configuration of the amqp backend from the environment.
+func GetAMQPConfig() (*AMQPConfig, error) {
+	amqpConfig := &AMQPConfig{
+		URL:        utils.FromEnvironment(defaultEnvironmentBackendAMQPURL, defaultBackendAMQPURL),
+		Exchange:   utils.FromEnvironment(defaultEnvironmentBackendAMQPExchange, defaultBackendAMQPExchange),
+		RoutingKey: utils.FromEnvironment(defaultEnvironmentBackendAMQPRoutingKey, defaultBackendAMQPRoutingKey),
+		Mandatory: utils.BoolFromString(
+			utils.FromEnvironment(defaultEnvironmentBackendAMQPMandatory, defaultBackendAMQPMandatory),
+		),
+		AuthType: utils.FromEnvironment(defaultEnvironmentBackendAMQPAuthType, defaultBackendAMQPAuthType),
+		TLSCA:    utils.FromEnvironment(defaultEnvironmentBackendAMQPTLSCA, ""),
+	}
+
+	switch amqpConfig.AuthType {
+	case DefaultBackendAuthTypeBasic, DefaultBackendAuthTypeNone:
+	default:
+		return amqpConfig, fmt.Errorf("auth type [%s] - %w", amqpConfig.AuthType, ErrBackendAuthUnknown)
+	}
+
+	if err := validateTemplate(defaultEnvironmentBackendAMQPRoutingKey, amqpConfig.RoutingKey); err != nil {
+		return amqpConfig, err
+	}
+
+	timeout, err := utils.IntFromEnvironment(defaultEnvironmentBackendAMQPAckTimeout, defaultBackendAMQPAckTimeout)
+	if err != nil {
+		return amqpConfig, fmt.Errorf("ack timeout from environment - %w", err)
+	}
+
+	if timeout < 1 {
+		return amqpConfig, fmt.Errorf(
+			"ack timeout from environment [%s=%d] must be at least 1 - %w",
+			defaultEnvironmentBackendAMQPAckTimeout,
+			timeout,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	attempts, err := utils.IntFromEnvironment(defaultEnvironmentBackendAMQPReconnectAttempts, defaultBackendAMQPReconnectAttempts)
+	if err != nil {
+		return amqpConfig, fmt.Errorf("reconnect attempts from environment - %w", err)
+	}
+
+	if attempts < 0 {
+		return amqpConfig, fmt.Errorf(
+			"reconnect attempts from environment [%s=%d] must not be negative - %w",
+			defaultEnvironmentBackendAMQPReconnectAttempts,
+			attempts,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	amqpConfig.AckTimeout = time.Duration(timeout) * time.Second
+	amqpConfig.ReconnectAttempts = attempts
+
+	return amqpConfig, nil
+}
+
+// GetAMQPBasicAuth returns the username and password for the amqp backend, which are stored in
+// the 'username' and 'password' keys of a kubernetes secret.
+func GetAMQPBasicAuth(client *kubernetes.Clientset, ctx context.Context) (username, password string, err error) {
+	secretName := utils.FromEnvironment(defaultEnvironmentBackendAMQPSecretName, defaultBackendAMQPSecretName)
+	secretNamespace := utils.FromEnvironment(defaultEnvironmentBackendAMQPSecretNamespace, defaultBackendAMQPSecretNamespace)
+
+	username, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultBackendAMQPSecretUsername)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve amqp username - %w", err)
+	}
+
+	password, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultBackendAMQPSecretPassword)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve amqp password - %w", err)
+	}
+
+	return username, password, nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendChatSecretName      = "BACKEND_CHAT_SECRET_NAME"
+	defaultEnvironmentBackendChatSecretNamespace = "BACKEND_CHAT_SECRET_NAMESPACE"
+	defaultEnvironmentBackendChatSeverity        = "BACKEND_CHAT_SEVERITY_THRESHOLD"
+	defaultEnvironmentBackendChatRateLimit       = "BACKEND_CHAT_RATE_LIMIT_PER_MINUTE"
+	defaultEnvironmentBackendChatConsoleURL      = "BACKEND_CHAT_CONSOLE_URL"
+	defaultEnvironmentBackendChatFormat          = "BACKEND_CHAT_FORMAT"
+
+	// Default Settings for Environment Variables.
+	DefaultBackendChatFormatCard      = "card"
+	defaultBackendChatSecretName      = "chat-webhook"
+	defaultBackendChatSecretNamespace = "ocm-log-forwarder"
+	defaultBackendChatSecretKey       = "url"
+	defaultBackendChatSeverity        = string(v1.SeverityWarning)
+	defaultBackendChatRateLimit       = 10
+	defaultBackendChatConsoleURL      = "https://console.redhat.com/openshift/details"
+	defaultBackendChatFormat          = DefaultBackendChatFormatCard
+)
+
+// GetChatWebhookURL returns the incoming webhook url for a chat backend, which is
+// stored in the 'url' key of a kubernetes secret.
+func GetChatWebhookURL(client *kubernetes.Clientset, ctx context.Context) (string, error) {
+	url, err := getSecretValue(
+		client,
+		ctx,
+		utils.FromEnvironment(defaultEnvironmentBackendChatSecretName, defaultBackendChatSecretName),
+		utils.FromEnvironment(defaultEnvironmentBackendChatSecretNamespace, defaultBackendChatSecretNamespace),
+		defaultBackendChatSecretKey,
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve chat webhook url - %w", err)
+	}
+
+	return url, nil
+}
+
+// GetChatSeverityThreshold returns the minimum severity of a service log that
+// results in a chat notification.
+func GetChatSeverityThreshold() (v1.Severity, error) {
+	return getSeverityThreshold(defaultEnvironmentBackendChatSeverity, defaultBackendChatSeverity)
+}
+
+// GetChatRateLimit returns the maximum number of chat notifications which may be
+// sent per minute.
+func GetChatRateLimit() (int, error) {
+	limit, err := utils.IntFromEnvironment(defaultEnvironmentBackendChatRateLimit, defaultBackendChatRateLimit)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get chat rate limit - %w", err)
+	}
+
+	if limit < 1 {
+		return 0, fmt.Errorf(
+			"rate limit from environment [%s=%d] must be greater than zero - %w",
+			defaultEnvironmentBackendChatRateLimit,
+			limit,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	return limit, nil
+}
+
+// GetChatConsoleURL returns the base url of the OpenShift Cluster Manager console
+// which is used to link a notification back to the cluster.
+func GetChatConsoleURL() string {
+	return utils.FromEnvironment(defaultEnvironmentBackendChatConsoleURL, defaultBackendChatConsoleURL)
+}
+
+// GetChatFormat returns the format of chat notifications, which is either a card built for the
+// chat platform or the plain text of a service log rendered by one of the output formats.
+func GetChatFormat() (string, error) {
+	return getFormat(
+		defaultEnvironmentBackendChatFormat,
+		defaultBackendChatFormat,
+		DefaultBackendChatFormatCard,
+		DefaultFormatJSON,
+		DefaultFormatCEF,
+		DefaultFormatLEEF,
+	)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendCloudWatchRegion          = "BACKEND_CLOUDWATCH_REGION"
+	defaultEnvironmentBackendCloudWatchEndpoint        = "BACKEND_CLOUDWATCH_ENDPOINT"
+	defaultEnvironmentBackendCloudWatchLogGroup        = "BACKEND_CLOUDWATCH_LOG_GROUP"
+	defaultEnvironmentBackendCloudWatchLogStream       = "BACKEND_CLOUDWATCH_LOG_STREAM"
+	defaultEnvironmentBackendCloudWatchAuthType        = "BACKEND_CLOUDWATCH_AUTH_TYPE"
+	defaultEnvironmentBackendCloudWatchSecretName      = "BACKEND_CLOUDWATCH_SECRET_NAME"
+	defaultEnvironmentBackendCloudWatchSecretNamespace = "BACKEND_CLOUDWATCH_SECRET_NAMESPACE"
+
+	// Default Settings for Environment Variables.
+	defaultBackendCloudWatchRegion          = "us-east-1"
+	defaultBackendCloudWatchLogGroup        = "/ocm-log-forwarder/{cluster_id}"
+	defaultBackendCloudWatchLogStream       = "service-logs"
+	defaultBackendCloudWatchAuthType        = DefaultBackendAuthTypeIRSA
+	defaultBackendCloudWatchSecretName      = "cloudwatch-auth"
+	defaultBackendCloudWatchSecretNamespace = "ocm-log-forwarder"
+)
+
+// CloudWatchConfig represents the configuration of the cloudwatch backend.
+type CloudWatchConfig struct {
+	Region    string
+	Endpoint  string
+	LogGroup  string
+	LogStream string
+	AuthType  string
+}
+
+// GetCloudWatchConfig returns the validated configuration of the cloudwatch backend from the
+// environment.  Any '{cluster_id}' placeholder in the log group and log stream names is
+// replaced with the cluster id.
+func GetCloudWatchConfig(clusterID string) (*CloudWatchConfig, error) {
+	region := utils.FromEnvironment(defaultEnvironmentBackendCloudWatchRegion, defaultBackendCloudWatchRegion)
+
+	cloudWatchConfig := &CloudWatchConfig{
+		Region: region,
+		Endpoint: utils.FromEnvironment(
+			defaultEnvironmentBackendCloudWatchEndpoint,
+			fmt.Sprintf("https://logs.%s.amazonaws.com", region),
+		),
+		LogGroup: resolveClusterID(
+			utils.FromEnvironment(defaultEnvironmentBackendCloudWatchLogGroup, defaultBackendCloudWatchLogGroup),
+			clusterID,
+		),
+		LogStream: resolveClusterID(
+			utils.FromEnvironment(defaultEnvironmentBackendCloudWatchLogStream, defaultBackendCloudWatchLogStream),
+			clusterID,
+		),
+		AuthType: utils.FromEnvironment(defaultEnvironmentBackendCloudWatchAuthType, defaultBackendCloudWatchAuthType),
+	}
+
+	switch cloudWatchConfig.AuthType {
+	case DefaultBackendAuthTypeIRSA, DefaultBackendAuthTypeStatic:
+	default:
+		return cloudWatchConfig, fmt.Errorf("auth type [%s] - %w", cloudWatchConfig.AuthType, ErrBackendAuthUnknown)
+	}
+
+	return cloudWatchConfig, nil
+}
+
+// GetCloudWatchStaticCredentials returns the static aws credentials for the cloudwatch backend, which
+// are stored in the 'access_key_id' and 'secret_access_key' keys of a kubernetes secret.
+func GetCloudWatchStaticCredentials(client *kubernetes.Clientset, ctx context.Context) (accessKeyID, secretAccessKey string, err error) {
+	return getAWSStaticCredentials(
+		client,
+		ctx,
+		utils.FromEnvironment(defaultEnvironmentBackendCloudWatchSecretName, defaultBackendCloudWatchSecretName),
+		utils.FromEnvironment(defaultEnvironmentBackendCloudWatchSecretNamespace, defaultBackendCloudWatchSecretNamespace),
+	)
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendElasticDataStream        = "BACKEND_ES_DATA_STREAM"
+	defaultEnvironmentBackendElasticIndexTemplateName = "BACKEND_ES_INDEX_TEMPLATE_NAME"
+	defaultEnvironmentBackendElasticBootstrap         = "BACKEND_ES_BOOTSTRAP"
+	defaultEnvironmentBackendElasticILMPolicyName     = "BACKEND_ES_ILM_POLICY_NAME"
+	defaultEnvironmentBackendElasticILMRolloverAge    = "BACKEND_ES_ILM_ROLLOVER_MAX_AGE"
+	defaultEnvironmentBackendElasticILMRolloverSize   = "BACKEND_ES_ILM_ROLLOVER_MAX_SIZE"
+	defaultEnvironmentBackendElasticILMDeleteAfter    = "BACKEND_ES_ILM_DELETE_AFTER"
+	defaultEnvironmentBackendElasticRetryMaxAttempts  = "BACKEND_ES_RETRY_MAX_ATTEMPTS"
+	defaultEnvironmentBackendElasticPipeline          = "BACKEND_ES_PIPELINE"
+	defaultEnvironmentBackendElasticDedupSeedSize     = "BACKEND_ES_DEDUP_SEED_SIZE"
+	defaultEnvironmentBackendElasticDedupLookup       = "BACKEND_ES_DEDUP_LOOKUP"
+	defaultEnvironmentBackendElasticRouting           = "BACKEND_ES_ROUTING"
+	defaultEnvironmentBackendElasticDeadLetter        = "BACKEND_ES_DEAD_LETTER"
+	defaultEnvironmentBackendElasticDeadLetterFile    = "BACKEND_ES_DEAD_LETTER_FILE"
+	defaultEnvironmentBackendElasticDeadLetterIndex   = "BACKEND_ES_DEAD_LETTER_INDEX"
+	defaultEnvironmentBackendElasticDeadLetterQueue   = "BACKEND_ES_DEAD_LETTER_QUEUE"
+
+	defaultEnvironmentBackendElasticDeadLetterRedisAddress         = "BACKEND_ES_DEAD_LETTER_REDIS_ADDRESS"
+	defaultEnvironmentBackendElasticDeadLetterRedisDatabase        = "BACKEND_ES_DEAD_LETTER_REDIS_DATABASE"
+	defaultEnvironmentBackendElasticDeadLetterRedisTimeout         = "BACKEND_ES_DEAD_LETTER_REDIS_TIMEOUT_SECONDS"
+	defaultEnvironmentBackendElasticDeadLetterRedisAuthType        = "BACKEND_ES_DEAD_LETTER_REDIS_AUTH_TYPE"
+	defaultEnvironmentBackendElasticDeadLetterRedisSecretName      = "BACKEND_ES_DEAD_LETTER_REDIS_SECRET_NAME"
+	defaultEnvironmentBackendElasticDeadLetterRedisSecretNamespace = "BACKEND_ES_DEAD_LETTER_REDIS_SECRET_NAMESPACE"
+	defaultEnvironmentBackendElasticDeadLetterRedisTLS             = "BACKEND_ES_DEAD_LETTER_REDIS_TLS"
+	defaultEnvironmentBackendElasticDeadLetterRedisTLSCA           = "BACKEND_ES_DEAD_LETTER_REDIS_TLS_CA"
+
+	// Default Settings for Environment Variables.
+	defaultBackendElasticDataStream        = "false"
+	defaultBackendElasticIndexTemplateName = "ocm-service-logs"
+	defaultBackendElasticBootstrap         = "false"
+	defaultBackendElasticILMPolicyName     = "ocm-service-logs"
+	defaultBackendElasticILMRolloverAge    = "1d"
+	defaultBackendElasticILMRolloverSize   = "50gb"
+	defaultBackendElasticILMDeleteAfter    = "30d"
+	DefaultBackendElasticILMDeleteNever    = "none"
+	defaultBackendElasticRetryMaxAttempts  = 5
+	defaultBackendElasticDedupSeedSize     = 1000
+	defaultBackendElasticDedupLookup       = "true"
+	DefaultBackendElasticDeadLetterNone    = "none"
+	DefaultBackendElasticDeadLetterFile    = "file"
+	DefaultBackendElasticDeadLetterIndex   = "index"
+	DefaultBackendElasticDeadLetterQueue   = "queue"
+	defaultBackendElasticDeadLetter        = DefaultBackendElasticDeadLetterNone
+	defaultBackendElasticDeadLetterFile    = "/tmp/ocm-log-forwarder/dead-letter.ndjson"
+	defaultBackendElasticDeadLetterIndex   = "ocm_service_logs_dead_letter"
+	defaultBackendElasticDeadLetterQueue   = "ocm-service-logs-dead-letter"
+)
+
+// ElasticSearchILMConfig represents the index lifecycle management policy which is installed
+// by the elasticsearch backend.  A delete after value of 'none' disables deletion.
+type ElasticSearchILMConfig struct {
+	PolicyName      string
+	RolloverMaxAge  string
+	RolloverMaxSize string
+	DeleteAfter     string
+}
+
+// GetElasticSearchDataStream returns whether documents are written to a data stream rather
+// than an index.
+func GetElasticSearchDataStream() bool {
+	return utils.BoolFromString(utils.FromEnvironment(defaultEnvironmentBackendElasticDataStream, defaultBackendElasticDataStream))
+}
+
+// GetElasticSearchPipeline returns the name of the ingest pipeline which documents are sent
+// through.  An empty name uses the default pipeline of the index, if any.
+func GetElasticSearchPipeline() string {
+	return utils.FromEnvironment(defaultEnvironmentBackendElasticPipeline, "")
+}
+
+// GetElasticSearchRouting returns the routing template which is resolved for each document.  An
+// empty template routes documents by their id.
+func GetElasticSearchRouting() string {
+	return utils.FromEnvironment(defaultEnvironmentBackendElasticRouting, "")
+}
+
+// GetElasticSearchIndexTemplateName returns the name of the index template which is managed
+// by the elasticsearch backend.
+func GetElasticSearchIndexTemplateName() string {
+	return utils.FromEnvironment(defaultEnvironmentBackendElasticIndexTemplateName, defaultBackendElasticIndexTemplateName)
+}
+
+// GetElasticSearchBootstrap returns whether the elasticsearch backend installs its index
+// template and lifecycle policy on startup.
+func GetElasticSearchBootstrap() bool {
+	return utils.BoolFromString(utils.FromEnvironment(defaultEnvironmentBackendElasticBootstrap, defaultBackendElasticBootstrap))
+}
+
+// GetElasticSearchILMConfig returns the index lifecycle management policy configuration.
+func GetElasticSearchILMConfig() *ElasticSearchILMConfig {
+	return &ElasticSearchILMConfig{
+		PolicyName:      utils.FromEnvironment(defaultEnvironmentBackendElasticILMPolicyName, defaultBackendElasticILMPolicyName),
+		RolloverMaxAge:  utils.FromEnvironment(defaultEnvironmentBackendElasticILMRolloverAge, defaultBackendElasticILMRolloverAge),
+		RolloverMaxSize: utils.FromEnvironment(defaultEnvironmentBackendElasticILMRolloverSize, defaultBackendElasticILMRolloverSize),
+		DeleteAfter:     utils.FromEnvironment(defaultEnvironmentBackendElasticILMDeleteAfter, defaultBackendElasticILMDeleteAfter),
+	}
+}
+
+// ElasticSearchDeadLetterConfig represents the destination of documents which are permanently
+// rejected by elasticsearch.  The queue is a redis stream, which is written to using its own
+// connection settings.
+type ElasticSearchDeadLetterConfig struct {
+	Destination string
+	File        string
+	Index       string
+	Queue       string
+	Redis       *RedisConnectionConfig
+}
+
+// GetElasticSearchRetryMaxAttempts returns the number of times a retryable bulk failure is
+// retried before the documents are left for the next poll.
+func GetElasticSearchRetryMaxAttempts() (int, error) {
+	attempts, err := utils.IntFromEnvironment(defaultEnvironmentBackendElasticRetryMaxAttempts, defaultBackendElasticRetryMaxAttempts)
+	if err != nil {
+		return attempts, fmt.Errorf("retry max attempts from environment - %w", err)
+	}
+
+	if attempts < 0 {
+		return attempts, fmt.Errorf(
+			"retry max attempts from environment [%s=%d] must not be negative - %w",
+			defaultEnvironmentBackendElasticRetryMaxAttempts,
+			attempts,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	return attempts, nil
+}
+
+// GetElasticSearchDedupSeedSize returns the number of recently indexed documents for the cluster
+// which are marked as sent on startup.  A size of 0 disables seeding.
+func GetElasticSearchDedupSeedSize() (int, error) {
+	size, err := utils.IntFromEnvironment(defaultEnvironmentBackendElasticDedupSeedSize, defaultBackendElasticDedupSeedSize)
+	if err != nil {
+		return size, fmt.Errorf("dedup seed size from environment - %w", err)
+	}
+
+	if size < 0 {
+		return size, fmt.Errorf(
+			"dedup seed size from environment [%s=%d] must not be negative - %w",
+			defaultEnvironmentBackendElasticDedupSeedSize,
+			size,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	return size, nil
+}
+
+// GetElasticSearchDedupLookup returns whether documents which have not been sent by this process
+// are looked up in elasticsearch before they are sent.
+func GetElasticSearchDedupLookup() bool {
+	return utils.BoolFromString(utils.FromEnvironment(defaultEnvironmentBackendElasticDedupLookup, defaultBackendElasticDedupLookup))
+}
+
+// GetElasticSearchDeadLetterConfig returns the validated dead letter configuration.
+func GetElasticSearchDeadLetterConfig() (*ElasticSearchDeadLetterConfig, error) {
+	deadLetterConfig := &ElasticSearchDeadLetterConfig{
+		Destination: utils.FromEnvironment(defaultEnvironmentBackendElasticDeadLetter, defaultBackendElasticDeadLetter),
+		File:        utils.FromEnvironment(defaultEnvironmentBackendElasticDeadLetterFile, defaultBackendElasticDeadLetterFile),
+		Index:       utils.FromEnvironment(defaultEnvironmentBackendElasticDeadLetterIndex, defaultBackendElasticDeadLetterIndex),
+		Queue:       utils.FromEnvironment(defaultEnvironmentBackendElasticDeadLetterQueue, defaultBackendElasticDeadLetterQueue),
+	}
+
+	switch deadLetterConfig.Destination {
+	case DefaultBackendElasticDeadLetterNone, DefaultBackendElasticDeadLetterFile, DefaultBackendElasticDeadLetterIndex:
+	case DefaultBackendElasticDeadLetterQueue:
+		redisConfig, err := getRedisConnectionConfig(&redisConnectionEnvironment{
+			Address:         defaultEnvironmentBackendElasticDeadLetterRedisAddress,
+			Database:        defaultEnvironmentBackendElasticDeadLetterRedisDatabase,
+			Timeout:         defaultEnvironmentBackendElasticDeadLetterRedisTimeout,
+			AuthType:        defaultEnvironmentBackendElasticDeadLetterRedisAuthType,
+			SecretName:      defaultEnvironmentBackendElasticDeadLetterRedisSecretName,
+			SecretNamespace: defaultEnvironmentBackendElasticDeadLetterRedisSecretNamespace,
+			TLS:             defaultEnvironmentBackendElasticDeadLetterRedisTLS,
+			TLSCA:           defaultEnvironmentBackendElasticDeadLetterRedisTLSCA,
+		})
+		if err != nil {
+			return deadLetterConfig, fmt.Errorf("dead letter queue - %w", err)
+		}
+
+		deadLetterConfig.Redis = redisConfig
+	default:
+		return deadLetterConfig, fmt.Errorf(
+			"dead letter destination from environment [%s=%s] - %w",
+			defaultEnvironmentBackendElasticDeadLetter,
+			deadLetterConfig.Destination,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	return deadLetterConfig, nil
+}
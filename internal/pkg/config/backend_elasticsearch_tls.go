@@ -0,0 +1,173 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	DefaultEnvironmentBackendElasticTLSCertificate       = "BACKEND_ES_CERT"
+	DefaultEnvironmentBackendElasticTLSKey               = "BACKEND_ES_KEY"
+	DefaultEnvironmentBackendElasticTLSVerify            = "BACKEND_ES_TLS_VERIFY"
+	DefaultEnvironmentBackendElasticTLSCA                = "BACKEND_ES_CA"
+	DefaultEnvironmentBackendElasticTLSCASecretName      = "BACKEND_ES_CA_SECRET_NAME"
+	DefaultEnvironmentBackendElasticTLSCASecretNamespace = "BACKEND_ES_CA_SECRET_NAMESPACE"
+	DefaultEnvironmentBackendElasticTLSServerName        = "BACKEND_ES_TLS_SERVER_NAME"
+	DefaultEnvironmentBackendElasticTLSMinVersion        = "BACKEND_ES_TLS_MIN_VERSION"
+
+	// Default Settings for Environment Variables.
+	DefaultBackendElasticTLSCertificate       = "/etc/pki/tls.crt"
+	DefaultBackendElasticTLSKey               = "/etc/pki/tls.key"
+	DefaultBackendElasticTLSVerify            = "true"
+	defaultBackendElasticTLSCASecretNamespace = "ocm-log-forwarder"
+	defaultBackendElasticTLSCASecretKey       = "ca.crt"
+	defaultBackendElasticTLSMinVersion        = "1.2"
+)
+
+// ElasticSearchTLSConfig represents the tls configuration of the elasticsearch client.  Server
+// verification and client certificates are independent of one another; the certificate
+// authority bundle is used to verify the server and the optional client certificate is
+// presented to the server for mutual tls.
+type ElasticSearchTLSConfig struct {
+	Verify         bool
+	CABundle       []byte
+	Certificate    string
+	Key            string
+	ServerName     string
+	MinVersion     uint16
+	CASource       string
+	HasCertificate bool
+}
+
+// GetElasticSearchTLSConfig returns the validated tls configuration of the elasticsearch client
+// from the environment.  The certificate authority bundle is read from either a file or the
+// 'ca.crt' key of a kubernetes secret, but not both.  The client certificate is only used when
+// both the certificate and key are set, or when neither is set and both exist at their default
+// locations.
+func GetElasticSearchTLSConfig(client *kubernetes.Clientset, ctx context.Context) (*ElasticSearchTLSConfig, error) {
+	tlsConfig := &ElasticSearchTLSConfig{
+		Verify: utils.BoolFromString(
+			utils.FromEnvironment(DefaultEnvironmentBackendElasticTLSVerify, DefaultBackendElasticTLSVerify),
+		),
+		Certificate: os.Getenv(DefaultEnvironmentBackendElasticTLSCertificate),
+		Key:         os.Getenv(DefaultEnvironmentBackendElasticTLSKey),
+		ServerName:  os.Getenv(DefaultEnvironmentBackendElasticTLSServerName),
+	}
+
+	minVersion := utils.FromEnvironment(DefaultEnvironmentBackendElasticTLSMinVersion, defaultBackendElasticTLSMinVersion)
+
+	version, ok := tlsVersions()[minVersion]
+	if !ok {
+		return tlsConfig, fmt.Errorf(
+			"minimum tls version from environment [%s=%s] - %w",
+			DefaultEnvironmentBackendElasticTLSMinVersion,
+			minVersion,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	tlsConfig.MinVersion = version
+
+	if err := tlsConfig.setCertificate(); err != nil {
+		return tlsConfig, err
+	}
+
+	if err := tlsConfig.setCABundle(client, ctx); err != nil {
+		return tlsConfig, err
+	}
+
+	return tlsConfig, nil
+}
+
+// setCertificate validates the client certificate configuration.
+func (tlsConfig *ElasticSearchTLSConfig) setCertificate() error {
+	// fall back to the default locations, but only if they both exist, as a client
+	// certificate is optional
+	if tlsConfig.Certificate == "" && tlsConfig.Key == "" {
+		if !fileExists(DefaultBackendElasticTLSCertificate) || !fileExists(DefaultBackendElasticTLSKey) {
+			return nil
+		}
+
+		tlsConfig.Certificate, tlsConfig.Key = DefaultBackendElasticTLSCertificate, DefaultBackendElasticTLSKey
+	}
+
+	// a client certificate requires both the certificate and the key
+	if tlsConfig.Certificate == "" || tlsConfig.Key == "" {
+		return fmt.Errorf(
+			"both [%s] and [%s] must be set for a client certificate - %w",
+			DefaultEnvironmentBackendElasticTLSCertificate,
+			DefaultEnvironmentBackendElasticTLSKey,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	tlsConfig.HasCertificate = true
+
+	return nil
+}
+
+// setCABundle reads the certificate authority bundle from either a file or a secret.
+func (tlsConfig *ElasticSearchTLSConfig) setCABundle(client *kubernetes.Clientset, ctx context.Context) (err error) {
+	caFile := os.Getenv(DefaultEnvironmentBackendElasticTLSCA)
+	caSecretName := os.Getenv(DefaultEnvironmentBackendElasticTLSCASecretName)
+
+	switch {
+	case caFile != "" && caSecretName != "":
+		return fmt.Errorf(
+			"only one of [%s] and [%s] may be set - %w",
+			DefaultEnvironmentBackendElasticTLSCA,
+			DefaultEnvironmentBackendElasticTLSCASecretName,
+			ErrBackendConfigInvalid,
+		)
+	case caFile != "":
+		tlsConfig.CABundle, err = os.ReadFile(caFile)
+		if err != nil {
+			return fmt.Errorf("unable to read certificate authority [%s] - %w", caFile, err)
+		}
+
+		tlsConfig.CASource = caFile
+	case caSecretName != "":
+		caSecretNamespace := utils.FromEnvironment(
+			DefaultEnvironmentBackendElasticTLSCASecretNamespace,
+			defaultBackendElasticTLSCASecretNamespace,
+		)
+
+		caBundle, err := getSecretValue(client, ctx, caSecretName, caSecretNamespace, defaultBackendElasticTLSCASecretKey)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve certificate authority - %w", err)
+		}
+
+		tlsConfig.CABundle = []byte(caBundle)
+		tlsConfig.CASource = fmt.Sprintf("%s/%s", caSecretNamespace, caSecretName)
+	}
+
+	return nil
+}
+
+// tlsVersions returns the supported minimum tls versions.
+func tlsVersions() map[string]uint16 {
+	return map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+}
+
+// fileExists determines if a file exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
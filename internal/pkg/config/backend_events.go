@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendEventsNamespace = "BACKEND_EVENTS_NAMESPACE"
+	defaultEnvironmentBackendEventsComponent = "BACKEND_EVENTS_COMPONENT"
+
+	// Default Settings for Environment Variables.
+	defaultBackendEventsNamespace = "ocm-log-forwarder"
+	defaultBackendEventsComponent = "ocm-log-forwarder"
+)
+
+// EventsConfig represents the configuration of the kubernetes events backend.
+type EventsConfig struct {
+	Namespace string
+	Component string
+}
+
+// GetEventsConfig returns the configuration of the kubernetes events backend from the environment.
+func GetEventsConfig() *EventsConfig {
+	return &EventsConfig{
+		Namespace: utils.FromEnvironment(defaultEnvironmentBackendEventsNamespace, defaultBackendEventsNamespace),
+		Component: utils.FromEnvironment(defaultEnvironmentBackendEventsComponent, defaultBackendEventsComponent),
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendFilePath       = "BACKEND_FILE_PATH"
+	defaultEnvironmentBackendFileMaxSizeMB  = "BACKEND_FILE_MAX_SIZE_MB"
+	defaultEnvironmentBackendFileMaxAge     = "BACKEND_FILE_MAX_AGE_HOURS"
+	defaultEnvironmentBackendFileCompress   = "BACKEND_FILE_COMPRESS"
+	defaultEnvironmentBackendFileMaxFiles   = "BACKEND_FILE_MAX_FILES"
+	defaultEnvironmentBackendFileMaxTotalMB = "BACKEND_FILE_MAX_TOTAL_MB"
+	defaultEnvironmentBackendFileFormat     = "BACKEND_FILE_FORMAT"
+
+	// Default Settings for Environment Variables.
+	defaultBackendFilePath       = "/var/log/ocm-log-forwarder/service-logs.ndjson"
+	defaultBackendFileMaxSizeMB  = 100
+	defaultBackendFileMaxAge     = 24
+	defaultBackendFileCompress   = "true"
+	defaultBackendFileMaxFiles   = 10
+	defaultBackendFileMaxTotalMB = 0
+	defaultBackendFileFormat     = DefaultFormatJSON
+
+	bytesPerMegabyte = 1024 * 1024
+)
+
+// FileConfig represents the configuration of the file backend.  A maximum age, number of files
+// or total size of 0 disables the limit.
+type FileConfig struct {
+	Path          string
+	MaxSize       int64
+	MaxAge        time.Duration
+	Compress      bool
+	MaxFiles      int
+	MaxTotalBytes int64
+	Format        string
+}
+
+// GetFileConfig returns the validated configuration of the file backend from the environment.
+func GetFileConfig() (*FileConfig, error) {
+	fileConfig := &FileConfig{
+		Path: utils.FromEnvironment(defaultEnvironmentBackendFilePath, defaultBackendFilePath),
+		Compress: utils.BoolFromString(
+			utils.FromEnvironment(defaultEnvironmentBackendFileCompress, defaultBackendFileCompress),
+		),
+	}
+
+	format, err := getFormat(
+		defaultEnvironmentBackendFileFormat,
+		defaultBackendFileFormat,
+		DefaultFormatJSON,
+		DefaultFormatCEF,
+		DefaultFormatLEEF,
+	)
+	if err != nil {
+		return fileConfig, err
+	}
+
+	fileConfig.Format = format
+
+	limits := map[string]int{}
+
+	for variable, def := range map[string]int{
+		defaultEnvironmentBackendFileMaxSizeMB:  defaultBackendFileMaxSizeMB,
+		defaultEnvironmentBackendFileMaxAge:     defaultBackendFileMaxAge,
+		defaultEnvironmentBackendFileMaxFiles:   defaultBackendFileMaxFiles,
+		defaultEnvironmentBackendFileMaxTotalMB: defaultBackendFileMaxTotalMB,
+	} {
+		limit, err := utils.IntFromEnvironment(variable, def)
+		if err != nil {
+			return fileConfig, fmt.Errorf("limit from environment - %w", err)
+		}
+
+		if limit < 0 {
+			return fileConfig, fmt.Errorf(
+				"limit from environment [%s=%d] must not be negative - %w",
+				variable,
+				limit,
+				ErrBackendConfigInvalid,
+			)
+		}
+
+		limits[variable] = limit
+	}
+
+	if limits[defaultEnvironmentBackendFileMaxSizeMB] == 0 {
+		return fileConfig, fmt.Errorf(
+			"max size from environment [%s=0] must be at least 1 - %w",
+			defaultEnvironmentBackendFileMaxSizeMB,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	fileConfig.MaxSize = int64(limits[defaultEnvironmentBackendFileMaxSizeMB]) * bytesPerMegabyte
+	fileConfig.MaxAge = time.Duration(limits[defaultEnvironmentBackendFileMaxAge]) * time.Hour
+	fileConfig.MaxFiles = limits[defaultEnvironmentBackendFileMaxFiles]
+	fileConfig.MaxTotalBytes = int64(limits[defaultEnvironmentBackendFileMaxTotalMB]) * bytesPerMegabyte
+
+	return fileConfig, nil
+}
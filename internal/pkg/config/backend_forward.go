@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendForwardAddress         = "BACKEND_FORWARD_ADDRESS"
+	defaultEnvironmentBackendForwardTag             = "BACKEND_FORWARD_TAG"
+	defaultEnvironmentBackendForwardHostname        = "BACKEND_FORWARD_HOSTNAME"
+	defaultEnvironmentBackendForwardAckTimeout      = "BACKEND_FORWARD_ACK_TIMEOUT_SECONDS"
+	defaultEnvironmentBackendForwardSecretName      = "BACKEND_FORWARD_SECRET_NAME"
+	defaultEnvironmentBackendForwardSecretNamespace = "BACKEND_FORWARD_SECRET_NAMESPACE"
+	defaultEnvironmentBackendForwardTLS             = "BACKEND_FORWARD_TLS"
+	defaultEnvironmentBackendForwardTLSCA           = "BACKEND_FORWARD_TLS_CA"
+	defaultEnvironmentBackendForwardTLSVerify       = "BACKEND_FORWARD_TLS_VERIFY"
+
+	// Default Settings for Environment Variables.
+	defaultBackendForwardAddress         = "localhost:24224"
+	defaultBackendForwardTag             = "ocm.servicelog.{cluster_id}"
+	defaultBackendForwardHostname        = "ocm-log-forwarder"
+	defaultBackendForwardAckTimeout      = 30
+	defaultBackendForwardSecretNamespace = "ocm-log-forwarder"
+	defaultBackendForwardTLS             = "false"
+	defaultBackendForwardTLSVerify       = "true"
+
+	defaultBackendForwardSecretSharedKey = "shared_key"
+	defaultBackendForwardSecretUsername  = "username"
+	defaultBackendForwardSecretPassword  = "password"
+)
+
+// ForwardConfig represents the configuration of the forward backend.
+type ForwardConfig struct {
+	Address    string
+	Tag        string
+	Hostname   string
+	AckTimeout time.Duration
+	TLS        bool
+	TLSCA      string
+	TLSVerify  bool
+}
+
+// ForwardAuth represents the credentials used in the forward protocol handshake.  The username
+// and password are only required when the aggregator enables user authentication.
+type ForwardAuth struct {
+	SharedKey string
+	Username  string
+	Password  string
+}
+
+// GetForwardConfig returns the validated configuration of the forward backend from the
+// environment.  Any '{cluster_id}' placeholder in the tag is replaced with the cluster id.
+func GetForwardConfig(clusterID string) (*ForwardConfig, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = defaultBackendForwardHostname
+	}
+
+	forwardConfig := &ForwardConfig{
+		Address: utils.FromEnvironment(defaultEnvironmentBackendForwardAddress, defaultBackendForwardAddress),
+		Tag: resolveClusterID(
+			utils.FromEnvironment(defaultEnvironmentBackendForwardTag, defaultBackendForwardTag),
+			clusterID,
+		),
+		Hostname: utils.FromEnvironment(defaultEnvironmentBackendForwardHostname, hostname),
+		TLS:      utils.BoolFromString(utils.FromEnvironment(defaultEnvironmentBackendForwardTLS, defaultBackendForwardTLS)),
+		TLSCA:    utils.FromEnvironment(defaultEnvironmentBackendForwardTLSCA, ""),
+		TLSVerify: utils.BoolFromString(
+			utils.FromEnvironment(defaultEnvironmentBackendForwardTLSVerify, defaultBackendForwardTLSVerify),
+		),
+	}
+
+	if forwardConfig.Tag == "" {
+		return forwardConfig, fmt.Errorf("tag from environment [%s] - %w", defaultEnvironmentBackendForwardTag, ErrBackendConfigInvalid)
+	}
+
+	ackTimeout, err := utils.IntFromEnvironment(defaultEnvironmentBackendForwardAckTimeout, defaultBackendForwardAckTimeout)
+	if err != nil {
+		return forwardConfig, fmt.Errorf("ack timeout from environment - %w", err)
+	}
+
+	if ackTimeout <= 0 {
+		return forwardConfig, fmt.Errorf(
+			"ack timeout from environment [%s=%d] must be positive - %w",
+			defaultEnvironmentBackendForwardAckTimeout,
+			ackTimeout,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	forwardConfig.AckTimeout = time.Duration(ackTimeout) * time.Second
+
+	return forwardConfig, nil
+}
+
+// GetForwardAuth returns the credentials for the forward protocol handshake, which are stored
+// in the 'shared_key' and the optional 'username' and 'password' keys of a kubernetes secret.
+// No credentials are returned if a secret is not configured.
+func GetForwardAuth(client *kubernetes.Clientset, ctx context.Context) (*ForwardAuth, error) {
+	secretName := utils.FromEnvironment(defaultEnvironmentBackendForwardSecretName, "")
+	if secretName == "" {
+		return nil, nil
+	}
+
+	secretNamespace := utils.FromEnvironment(defaultEnvironmentBackendForwardSecretNamespace, defaultBackendForwardSecretNamespace)
+
+	secret, err := utils.GetKubernetesSecret(client, ctx, secretName, secretNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching secret [%s/%s] - %w", secretNamespace, secretName, err)
+	}
+
+	sharedKey, ok := secret.Data[defaultBackendForwardSecretSharedKey]
+	if !ok || len(sharedKey) == 0 {
+		return nil, fmt.Errorf(
+			"key [%s] in secret [%s/%s] - %w",
+			defaultBackendForwardSecretSharedKey,
+			secretNamespace,
+			secretName,
+			ErrBackendSecretMissingKey,
+		)
+	}
+
+	return &ForwardAuth{
+		SharedKey: string(sharedKey),
+		Username:  string(secret.Data[defaultBackendForwardSecretUsername]),
+		Password:  string(secret.Data[defaultBackendForwardSecretPassword]),
+	}, nil
+}
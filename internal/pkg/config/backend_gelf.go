@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendGELFAddress   = "BACKEND_GELF_ADDRESS"
+	defaultEnvironmentBackendGELFProtocol  = "BACKEND_GELF_PROTOCOL"
+	defaultEnvironmentBackendGELFHost      = "BACKEND_GELF_HOST"
+	defaultEnvironmentBackendGELFCompress  = "BACKEND_GELF_COMPRESS"
+	defaultEnvironmentBackendGELFChunkSize = "BACKEND_GELF_CHUNK_SIZE"
+
+	// Default Settings for Environment Variables.
+	DefaultBackendGELFProtocolUDP = "udp"
+	DefaultBackendGELFProtocolTCP = "tcp"
+	defaultBackendGELFAddress     = "localhost:12201"
+	defaultBackendGELFProtocol    = DefaultBackendGELFProtocolUDP
+	defaultBackendGELFHost        = "ocm-log-forwarder"
+	defaultBackendGELFCompress    = "true"
+	defaultBackendGELFChunkSize   = 1420
+
+	// a chunk must be able to hold the 12 byte chunk header and some data
+	minimumBackendGELFChunkSize = 64
+)
+
+// GELFConfig represents the configuration of the gelf backend.
+type GELFConfig struct {
+	Address   string
+	Protocol  string
+	Host      string
+	Compress  bool
+	ChunkSize int
+}
+
+// GetGELFConfig returns the validated configuration of the gelf backend from the environment.
+func GetGELFConfig() (*GELFConfig, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = defaultBackendGELFHost
+	}
+
+	gelfConfig := &GELFConfig{
+		Address:  utils.FromEnvironment(defaultEnvironmentBackendGELFAddress, defaultBackendGELFAddress),
+		Protocol: utils.FromEnvironment(defaultEnvironmentBackendGELFProtocol, defaultBackendGELFProtocol),
+		Host:     utils.FromEnvironment(defaultEnvironmentBackendGELFHost, hostname),
+		Compress: utils.BoolFromString(
+			utils.FromEnvironment(defaultEnvironmentBackendGELFCompress, defaultBackendGELFCompress),
+		),
+	}
+
+	switch gelfConfig.Protocol {
+	case DefaultBackendGELFProtocolUDP, DefaultBackendGELFProtocolTCP:
+	default:
+		return gelfConfig, fmt.Errorf(
+			"protocol from environment [%s=%s] - %w",
+			defaultEnvironmentBackendGELFProtocol,
+			gelfConfig.Protocol,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	gelfConfig.ChunkSize, err = utils.IntFromEnvironment(defaultEnvironmentBackendGELFChunkSize, defaultBackendGELFChunkSize)
+	if err != nil {
+		return gelfConfig, fmt.Errorf("chunk size from environment - %w", err)
+	}
+
+	if gelfConfig.ChunkSize < minimumBackendGELFChunkSize {
+		return gelfConfig, fmt.Errorf(
+			"chunk size from environment [%s=%d] must be at least [%d] - %w",
+			defaultEnvironmentBackendGELFChunkSize,
+			gelfConfig.ChunkSize,
+			minimumBackendGELFChunkSize,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	return gelfConfig, nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendNATSURL             = "BACKEND_NATS_URL"
+	defaultEnvironmentBackendNATSSubject         = "BACKEND_NATS_SUBJECT"
+	defaultEnvironmentBackendNATSAuthType        = "BACKEND_NATS_AUTH_TYPE"
+	defaultEnvironmentBackendNATSSecretName      = "BACKEND_NATS_SECRET_NAME"
+	defaultEnvironmentBackendNATSSecretNamespace = "BACKEND_NATS_SECRET_NAMESPACE"
+	defaultEnvironmentBackendNATSTLSCA           = "BACKEND_NATS_TLS_CA"
+	defaultEnvironmentBackendNATSAckTimeout      = "BACKEND_NATS_ACK_TIMEOUT_SECONDS"
+
+	// Default Settings for Environment Variables.
+	defaultBackendNATSURL             = "nats://localhost:4222"
+	defaultBackendNATSSubject         = "ocm.service_logs.{cluster_id}"
+	defaultBackendNATSAuthType        = DefaultBackendAuthTypeNone
+	defaultBackendNATSSecretName      = "nats-auth"
+	defaultBackendNATSSecretNamespace = "ocm-log-forwarder"
+	defaultBackendNATSAckTimeout      = 5
+	defaultBackendNATSSecretUsername  = "username"
+	defaultBackendNATSSecretPassword  = "password"
+	defaultBackendNATSSecretToken     = "token"
+)
+
+// NATSConfig represents the configuration of the nats jetstream backend.  The subject is a
+// template which is resolved for each service log.
+type NATSConfig struct {
+	URL        string
+	Subject    string
+	AuthType   string
+	TLSCA      string
+	AckTimeout time.Duration
+}
+
+// GetNATSConfig returns the validated configuration of the nats jetstream backend from the
+// environment.
+func GetNATSConfig() (*NATSConfig, error) {
+	natsConfig := &NATSConfig{
+		URL:      utils.FromEnvironment(defaultEnvironmentBackendNATSURL, defaultBackendNATSURL),
+		Subject:  utils.FromEnvironment(defaultEnvironmentBackendNATSSubject, defaultBackendNATSSubject),
+		AuthType: utils.FromEnvironment(defaultEnvironmentBackendNATSAuthType, defaultBackendNATSAuthType),
+		TLSCA:    utils.FromEnvironment(defaultEnvironmentBackendNATSTLSCA, ""),
+	}
+
+	switch natsConfig.AuthType {
+	case DefaultBackendAuthTypeBasic, DefaultBackendAuthTypeBearer, DefaultBackendAuthTypeNone:
+	default:
+		return natsConfig, fmt.Errorf("auth type [%s] - %w", natsConfig.AuthType, ErrBackendAuthUnknown)
+	}
+
+	if err := validateTemplate(defaultEnvironmentBackendNATSSubject, natsConfig.Subject); err != nil {
+		return natsConfig, err
+	}
+
+	timeout, err := utils.IntFromEnvironment(defaultEnvironmentBackendNATSAckTimeout, defaultBackendNATSAckTimeout)
+	if err != nil {
+		return natsConfig, fmt.Errorf("ack timeout from environment - %w", err)
+	}
+
+	if timeout < 1 {
+		return natsConfig, fmt.Errorf(
+			"ack timeout from environment [%s=%d] must be at least 1 - %w",
+			defaultEnvironmentBackendNATSAckTimeout,
+			timeout,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	natsConfig.AckTimeout = time.Duration(timeout) * time.Second
+
+	return natsConfig, nil
+}
+
+// GetNATSBasicAuth returns the username and password for the nats jetstream backend, which are
+// stored in the 'username' and 'password' keys of a kubernetes secret.
+func GetNATSBasicAuth(client *kubernetes.Clientset, ctx context.Context) (username, password string, err error) {
+	secretName, secretNamespace := getNATSSecretName(), getNATSSecretNamespace()
+
+	username, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultBackendNATSSecretUsername)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve nats username - %w", err)
+	}
+
+	password, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultBackendNATSSecretPassword)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve nats password - %w", err)
+	}
+
+	return username, password, nil
+}
+
+// GetNATSToken returns the token for the nats jetstream backend, which is stored in the 'token'
+// key of a kubernetes secret.
+func GetNATSToken(client *kubernetes.Clientset, ctx context.Context) (string, error) {
+	token, err := getSecretValue(client, ctx, getNATSSecretName(), getNATSSecretNamespace(), defaultBackendNATSSecretToken)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve nats token - %w", err)
+	}
+
+	return token, nil
+}
+
+func getNATSSecretName() string {
+	return utils.FromEnvironment(defaultEnvironmentBackendNATSSecretName, defaultBackendNATSSecretName)
+}
+
+func getNATSSecretNamespace() string {
+	return utils.FromEnvironment(defaultEnvironmentBackendNATSSecretNamespace, defaultBackendNATSSecretNamespace)
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendOpenSearchURL             = "BACKEND_OPENSEARCH_URL"
+	defaultEnvironmentBackendOpenSearchIndex           = "BACKEND_OPENSEARCH_INDEX"
+	defaultEnvironmentBackendOpenSearchAuthType        = "BACKEND_OPENSEARCH_AUTH_TYPE"
+	defaultEnvironmentBackendOpenSearchSecretName      = "BACKEND_OPENSEARCH_SECRET_NAME"
+	defaultEnvironmentBackendOpenSearchSecretNamespace = "BACKEND_OPENSEARCH_SECRET_NAMESPACE"
+	defaultEnvironmentBackendOpenSearchRegion          = "BACKEND_OPENSEARCH_REGION"
+	defaultEnvironmentBackendOpenSearchService         = "BACKEND_OPENSEARCH_SERVICE"
+	defaultEnvironmentBackendOpenSearchTLSCA           = "BACKEND_OPENSEARCH_TLS_CA"
+	defaultEnvironmentBackendOpenSearchTLSVerify       = "BACKEND_OPENSEARCH_TLS_VERIFY"
+
+	// Default Settings for Environment Variables.
+	DefaultBackendOpenSearchServiceManaged    = "es"
+	DefaultBackendOpenSearchServiceServerless = "aoss"
+	defaultBackendOpenSearchURL               = "http://localhost:9200"
+	defaultBackendOpenSearchIndex             = "ocm_service_logs"
+	defaultBackendOpenSearchAuthType          = DefaultBackendAuthTypeBasic
+	defaultBackendOpenSearchSecretName        = "opensearch-auth"
+	defaultBackendOpenSearchSecretNamespace   = "ocm-log-forwarder"
+	defaultBackendOpenSearchRegion            = "us-east-1"
+	defaultBackendOpenSearchService           = DefaultBackendOpenSearchServiceManaged
+	defaultBackendOpenSearchTLSVerify         = "true"
+	defaultBackendOpenSearchSecretUsername    = "username"
+	defaultBackendOpenSearchSecretPassword    = "password"
+)
+
+// OpenSearchConfig represents the configuration of the opensearch backend.
+type OpenSearchConfig struct {
+	URL       string
+	Index     string
+	AuthType  string
+	Region    string
+	Service   string
+	TLSCA     string
+	TLSVerify bool
+}
+
+// GetOpenSearchConfig returns the validated configuration of the opensearch backend from the
+// environment.  Any '{cluster_id}' placeholder in the index is replaced with the cluster id.
+func GetOpenSearchConfig(clusterID string) (*OpenSearchConfig, error) {
+	openSearchConfig := &OpenSearchConfig{
+		URL: strings.TrimSuffix(utils.FromEnvironment(defaultEnvironmentBackendOpenSearchURL, defaultBackendOpenSearchURL), "/"),
+		Index: strings.ToLower(resolveClusterID(
+			utils.FromEnvironment(defaultEnvironmentBackendOpenSearchIndex, defaultBackendOpenSearchIndex),
+			clusterID,
+		)),
+		AuthType: utils.FromEnvironment(defaultEnvironmentBackendOpenSearchAuthType, defaultBackendOpenSearchAuthType),
+		Region:   utils.FromEnvironment(defaultEnvironmentBackendOpenSearchRegion, defaultBackendOpenSearchRegion),
+		Service:  utils.FromEnvironment(defaultEnvironmentBackendOpenSearchService, defaultBackendOpenSearchService),
+		TLSCA:    utils.FromEnvironment(defaultEnvironmentBackendOpenSearchTLSCA, ""),
+		TLSVerify: utils.BoolFromString(
+			utils.FromEnvironment(defaultEnvironmentBackendOpenSearchTLSVerify, defaultBackendOpenSearchTLSVerify),
+		),
+	}
+
+	switch openSearchConfig.AuthType {
+	case DefaultBackendAuthTypeBasic, DefaultBackendAuthTypeIRSA, DefaultBackendAuthTypeStatic, DefaultBackendAuthTypeNone:
+	default:
+		return openSearchConfig, fmt.Errorf("auth type [%s] - %w", openSearchConfig.AuthType, ErrBackendAuthUnknown)
+	}
+
+	switch openSearchConfig.Service {
+	case DefaultBackendOpenSearchServiceManaged, DefaultBackendOpenSearchServiceServerless:
+	default:
+		return openSearchConfig, fmt.Errorf(
+			"service from environment [%s=%s] - %w",
+			defaultEnvironmentBackendOpenSearchService,
+			openSearchConfig.Service,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	return openSearchConfig, nil
+}
+
+// GetOpenSearchBasicAuth returns the username and password for the opensearch backend, which
+// are stored in the 'username' and 'password' keys of a kubernetes secret.
+func GetOpenSearchBasicAuth(client *kubernetes.Clientset, ctx context.Context) (username, password string, err error) {
+	secretName, secretNamespace := getOpenSearchSecretName(), getOpenSearchSecretNamespace()
+
+	username, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultBackendOpenSearchSecretUsername)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve opensearch username - %w", err)
+	}
+
+	password, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultBackendOpenSearchSecretPassword)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve opensearch password - %w", err)
+	}
+
+	return username, password, nil
+}
+
+// GetOpenSearchStaticCredentials returns the static aws credentials for the opensearch backend, which
+// are stored in the 'access_key_id' and 'secret_access_key' keys of a kubernetes secret.
+func GetOpenSearchStaticCredentials(client *kubernetes.Clientset, ctx context.Context) (accessKeyID, secretAccessKey string, err error) {
+	return getAWSStaticCredentials(client, ctx, getOpenSearchSecretName(), getOpenSearchSecretNamespace())
+}
+
+func getOpenSearchSecretName() string {
+	return utils.FromEnvironment(defaultEnvironmentBackendOpenSearchSecretName, defaultBackendOpenSearchSecretName)
+}
+
+func getOpenSearchSecretNamespace() string {
+	return utils.FromEnvironment(defaultEnvironmentBackendOpenSearchSecretNamespace, defaultBackendOpenSearchSecretNamespace)
+}
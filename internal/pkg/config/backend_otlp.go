@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendOTLPEndpoint        = "BACKEND_OTLP_ENDPOINT"
+	defaultEnvironmentBackendOTLPProtocol        = "BACKEND_OTLP_PROTOCOL"
+	defaultEnvironmentBackendOTLPSecretName      = "BACKEND_OTLP_SECRET_NAME"
+	defaultEnvironmentBackendOTLPSecretNamespace = "BACKEND_OTLP_SECRET_NAMESPACE"
+	defaultEnvironmentBackendOTLPTLSCA           = "BACKEND_OTLP_TLS_CA"
+	defaultEnvironmentBackendOTLPTLSVerify       = "BACKEND_OTLP_TLS_VERIFY"
+
+	// Default Settings for Environment Variables.
+	DefaultBackendOTLPProtocolGRPC    = "grpc"
+	DefaultBackendOTLPProtocolHTTP    = "http/protobuf"
+	defaultBackendOTLPProtocol        = DefaultBackendOTLPProtocolGRPC
+	defaultBackendOTLPEndpointGRPC    = "http://localhost:4317"
+	defaultBackendOTLPEndpointHTTP    = "http://localhost:4318"
+	defaultBackendOTLPSecretNamespace = "ocm-log-forwarder"
+	defaultBackendOTLPTLSVerify       = "true"
+)
+
+// OTLPConfig represents the configuration of the otlp backend.
+type OTLPConfig struct {
+	Endpoint  string
+	Protocol  string
+	TLSCA     string
+	TLSVerify bool
+}
+
+// GetOTLPConfig returns the validated configuration of the otlp backend from the environment.
+func GetOTLPConfig() (*OTLPConfig, error) {
+	otlpConfig := &OTLPConfig{
+		Protocol: utils.FromEnvironment(defaultEnvironmentBackendOTLPProtocol, defaultBackendOTLPProtocol),
+		TLSCA:    utils.FromEnvironment(defaultEnvironmentBackendOTLPTLSCA, ""),
+		TLSVerify: utils.BoolFromString(
+			utils.FromEnvironment(defaultEnvironmentBackendOTLPTLSVerify, defaultBackendOTLPTLSVerify),
+		),
+	}
+
+	switch otlpConfig.Protocol {
+	case DefaultBackendOTLPProtocolGRPC:
+		otlpConfig.Endpoint = utils.FromEnvironment(defaultEnvironmentBackendOTLPEndpoint, defaultBackendOTLPEndpointGRPC)
+	case DefaultBackendOTLPProtocolHTTP:
+		otlpConfig.Endpoint = utils.FromEnvironment(defaultEnvironmentBackendOTLPEndpoint, defaultBackendOTLPEndpointHTTP)
+	default:
+		return otlpConfig, fmt.Errorf(
+			"protocol from environment [%s=%s] - %w",
+			defaultEnvironmentBackendOTLPProtocol,
+			otlpConfig.Protocol,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	return otlpConfig, nil
+}
+
+// GetOTLPHeaders returns the headers sent with each export request, such as authorization
+// headers.  Each key/value pair of the secret is a header.  No headers are returned if a
+// secret is not configured.
+func GetOTLPHeaders(client *kubernetes.Clientset, ctx context.Context) (map[string]string, error) {
+	headers := map[string]string{}
+
+	secretName := utils.FromEnvironment(defaultEnvironmentBackendOTLPSecretName, "")
+	if secretName == "" {
+		return headers, nil
+	}
+
+	secretNamespace := utils.FromEnvironment(defaultEnvironmentBackendOTLPSecretNamespace, defaultBackendOTLPSecretNamespace)
+
+	secret, err := utils.GetKubernetesSecret(client, ctx, secretName, secretNamespace)
+	if err != nil {
+		return headers, fmt.Errorf("error fetching secret containing otlp headers - %w", err)
+	}
+
+	for key, value := range secret.Data {
+		headers[key] = string(value)
+	}
+
+	return headers, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendPagerDutySecretName      = "BACKEND_PAGERDUTY_SECRET_NAME"
+	defaultEnvironmentBackendPagerDutySecretNamespace = "BACKEND_PAGERDUTY_SECRET_NAMESPACE"
+	defaultEnvironmentBackendPagerDutySeverity        = "BACKEND_PAGERDUTY_SEVERITY_THRESHOLD"
+	defaultEnvironmentBackendPagerDutyURL             = "BACKEND_PAGERDUTY_URL"
+	defaultEnvironmentBackendPagerDutyDedupKey        = "BACKEND_PAGERDUTY_DEDUP_KEY"
+
+	// Default Settings for Environment Variables.
+	DefaultBackendPagerDutyDedupKeyID            = "id"
+	DefaultBackendPagerDutyDedupKeyEventStreamID = "event_stream_id"
+	defaultBackendPagerDutySecretName            = "pagerduty-routing-key"
+	defaultBackendPagerDutySecretNamespace       = "ocm-log-forwarder"
+	defaultBackendPagerDutySecretKey             = "routing_key"
+	defaultBackendPagerDutySeverity              = string(v1.SeverityError)
+	defaultBackendPagerDutyURL                   = "https://events.pagerduty.com/v2/enqueue"
+	defaultBackendPagerDutyDedupKey              = DefaultBackendPagerDutyDedupKeyID
+)
+
+// GetPagerDutyRoutingKey returns the integration routing key for a pagerduty service, which is
+// stored in the 'routing_key' key of a kubernetes secret.
+func GetPagerDutyRoutingKey(client *kubernetes.Clientset, ctx context.Context) (string, error) {
+	routingKey, err := getSecretValue(
+		client,
+		ctx,
+		utils.FromEnvironment(defaultEnvironmentBackendPagerDutySecretName, defaultBackendPagerDutySecretName),
+		utils.FromEnvironment(defaultEnvironmentBackendPagerDutySecretNamespace, defaultBackendPagerDutySecretNamespace),
+		defaultBackendPagerDutySecretKey,
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to retrieve pagerduty routing key - %w", err)
+	}
+
+	return routingKey, nil
+}
+
+// GetPagerDutySeverityThreshold returns the minimum severity of a service log that
+// triggers a pagerduty event.
+func GetPagerDutySeverityThreshold() (v1.Severity, error) {
+	return getSeverityThreshold(defaultEnvironmentBackendPagerDutySeverity, defaultBackendPagerDutySeverity)
+}
+
+// GetPagerDutyURL returns the url of the pagerduty events api.
+func GetPagerDutyURL() string {
+	return utils.FromEnvironment(defaultEnvironmentBackendPagerDutyURL, defaultBackendPagerDutyURL)
+}
+
+// GetPagerDutyDedupKey returns the service log field which is used to derive the
+// pagerduty dedup key.
+func GetPagerDutyDedupKey() (string, error) {
+	switch dedupKey := utils.FromEnvironment(defaultEnvironmentBackendPagerDutyDedupKey, defaultBackendPagerDutyDedupKey); {
+	case dedupKey == DefaultBackendPagerDutyDedupKeyID, dedupKey == DefaultBackendPagerDutyDedupKeyEventStreamID:
+		return dedupKey, nil
+	default:
+		return "", fmt.Errorf(
+			"dedup key from environment [%s=%s] - %w",
+			defaultEnvironmentBackendPagerDutyDedupKey,
+			dedupKey,
+			ErrBackendConfigInvalid,
+		)
+	}
+}
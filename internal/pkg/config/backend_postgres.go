@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendPostgresHost            = "BACKEND_POSTGRES_HOST"
+	defaultEnvironmentBackendPostgresPort            = "BACKEND_POSTGRES_PORT"
+	defaultEnvironmentBackendPostgresDatabase        = "BACKEND_POSTGRES_DATABASE"
+	defaultEnvironmentBackendPostgresSchema          = "BACKEND_POSTGRES_SCHEMA"
+	defaultEnvironmentBackendPostgresTable           = "BACKEND_POSTGRES_TABLE"
+	defaultEnvironmentBackendPostgresSSLMode         = "BACKEND_POSTGRES_SSL_MODE"
+	defaultEnvironmentBackendPostgresSSLRootCert     = "BACKEND_POSTGRES_SSL_ROOT_CERT"
+	defaultEnvironmentBackendPostgresSecretName      = "BACKEND_POSTGRES_SECRET_NAME"
+	defaultEnvironmentBackendPostgresSecretNamespace = "BACKEND_POSTGRES_SECRET_NAMESPACE"
+
+	// Default Settings for Environment Variables.
+	defaultBackendPostgresHost            = "localhost"
+	defaultBackendPostgresPort            = "5432"
+	defaultBackendPostgresDatabase        = "ocm"
+	defaultBackendPostgresSchema          = "public"
+	defaultBackendPostgresTable           = "service_logs"
+	defaultBackendPostgresSSLMode         = "require"
+	defaultBackendPostgresSecretName      = "postgres-auth"
+	defaultBackendPostgresSecretNamespace = "ocm-log-forwarder"
+	defaultBackendPostgresSecretUsername  = "username"
+	defaultBackendPostgresSecretPassword  = "password"
+)
+
+// postgresIdentifier restricts schema and table names to unquoted postgres identifiers so that
+// they may safely be used in statements and do not need to be quoted by users querying them.
+var postgresIdentifier = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// PostgresConfig represents the configuration of the postgres backend.
+type PostgresConfig struct {
+	Host        string
+	Port        string
+	Database    string
+	Schema      string
+	Table       string
+	SSLMode     string
+	SSLRootCert string
+}
+
+// GetPostgresConfig returns the validated configuration of the postgres backend from the
+// environment.
+func GetPostgresConfig() (*PostgresConfig, error) {
+	postgresConfig := &PostgresConfig{
+		Host:        utils.FromEnvironment(defaultEnvironmentBackendPostgresHost, defaultBackendPostgresHost),
+		Port:        utils.FromEnvironment(defaultEnvironmentBackendPostgresPort, defaultBackendPostgresPort),
+		Database:    utils.FromEnvironment(defaultEnvironmentBackendPostgresDatabase, defaultBackendPostgresDatabase),
+		Schema:      utils.FromEnvironment(defaultEnvironmentBackendPostgresSchema, defaultBackendPostgresSchema),
+		Table:       utils.FromEnvironment(defaultEnvironmentBackendPostgresTable, defaultBackendPostgresTable),
+		SSLMode:     utils.FromEnvironment(defaultEnvironmentBackendPostgresSSLMode, defaultBackendPostgresSSLMode),
+		SSLRootCert: utils.FromEnvironment(defaultEnvironmentBackendPostgresSSLRootCert, ""),
+	}
+
+	for variable, identifier := range map[string]string{
+		defaultEnvironmentBackendPostgresSchema: postgresConfig.Schema,
+		defaultEnvironmentBackendPostgresTable:  postgresConfig.Table,
+	} {
+		if !postgresIdentifier.MatchString(identifier) {
+			return postgresConfig, fmt.Errorf(
+				"identifier from environment [%s=%s] must be lowercase letters, digits and underscores - %w",
+				variable,
+				identifier,
+				ErrBackendConfigInvalid,
+			)
+		}
+	}
+
+	switch postgresConfig.SSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		return postgresConfig, fmt.Errorf(
+			"ssl mode from environment [%s=%s] - %w",
+			defaultEnvironmentBackendPostgresSSLMode,
+			postgresConfig.SSLMode,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	return postgresConfig, nil
+}
+
+// DataSourceName returns the connection url for the database with the provided credentials.
+func (postgresConfig *PostgresConfig) DataSourceName(username, password string) string {
+	query := url.Values{}
+	query.Set("sslmode", postgresConfig.SSLMode)
+
+	if postgresConfig.SSLRootCert != "" {
+		query.Set("sslrootcert", postgresConfig.SSLRootCert)
+	}
+
+	dataSourceName := &url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(username, password),
+		Host:     net.JoinHostPort(postgresConfig.Host, postgresConfig.Port),
+		Path:     "/" + postgresConfig.Database,
+		RawQuery: query.Encode(),
+	}
+
+	return dataSourceName.String()
+}
+
+// GetPostgresCredentials returns the username and password for the postgres backend, which are
+// stored in the 'username' and 'password' keys of a kubernetes secret.
+func GetPostgresCredentials(client *kubernetes.Clientset, ctx context.Context) (username, password string, err error) {
+	secretName := utils.FromEnvironment(defaultEnvironmentBackendPostgresSecretName, defaultBackendPostgresSecretName)
+	secretNamespace := utils.FromEnvironment(defaultEnvironmentBackendPostgresSecretNamespace, defaultBackendPostgresSecretNamespace)
+
+	username, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultBackendPostgresSecretUsername)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve postgres username - %w", err)
+	}
+
+	password, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultBackendPostgresSecretPassword)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve postgres password - %w", err)
+	}
+
+	return username, password, nil
+}
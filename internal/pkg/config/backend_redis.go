@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendRedisAddress         = "BACKEND_REDIS_ADDRESS"
+	defaultEnvironmentBackendRedisDatabase        = "BACKEND_REDIS_DATABASE"
+	defaultEnvironmentBackendRedisStream          = "BACKEND_REDIS_STREAM"
+	defaultEnvironmentBackendRedisMaxLength       = "BACKEND_REDIS_MAX_LENGTH"
+	defaultEnvironmentBackendRedisDedupTTL        = "BACKEND_REDIS_DEDUP_TTL_HOURS"
+	defaultEnvironmentBackendRedisWaitReplicas    = "BACKEND_REDIS_WAIT_REPLICAS"
+	defaultEnvironmentBackendRedisAckTimeout      = "BACKEND_REDIS_ACK_TIMEOUT_SECONDS"
+	defaultEnvironmentBackendRedisAuthType        = "BACKEND_REDIS_AUTH_TYPE"
+	defaultEnvironmentBackendRedisSecretName      = "BACKEND_REDIS_SECRET_NAME"
+	defaultEnvironmentBackendRedisSecretNamespace = "BACKEND_REDIS_SECRET_NAMESPACE"
+	defaultEnvironmentBackendRedisTLS             = "BACKEND_REDIS_TLS"
+	defaultEnvironmentBackendRedisTLSCA           = "BACKEND_REDIS_TLS_CA"
+
+	// Default Settings for Environment Variables.
+	defaultBackendRedisStream       = "ocm:service_logs:{cluster_id}"
+	defaultBackendRedisMaxLength    = 0
+	defaultBackendRedisDedupTTL     = 168
+	defaultBackendRedisWaitReplicas = 0
+)
+
+// RedisConfig represents the configuration of the redis streams backend.  The stream is a
+// template which is resolved for each service log.  A maximum length, deduplication ttl or
+// number of replicas of 0 disables the feature.  The timeout of the connection is also the
+// time to wait for replicas to acknowledge an entry.
+type RedisConfig struct {
+	RedisConnectionConfig
+
+	Stream       string
+	MaxLength    int
+	DedupTTL     time.Duration
+	WaitReplicas int
+}
+
+// GetRedisConfig returns the validated configuration of the redis streams backend from the
+// environment.
+func GetRedisConfig() (*RedisConfig, error) {
+	redisConfig := &RedisConfig{
+		Stream: utils.FromEnvironment(defaultEnvironmentBackendRedisStream, defaultBackendRedisStream),
+	}
+
+	connectionConfig, err := getRedisConnectionConfig(&redisConnectionEnvironment{
+		Address:         defaultEnvironmentBackendRedisAddress,
+		Database:        defaultEnvironmentBackendRedisDatabase,
+		Timeout:         defaultEnvironmentBackendRedisAckTimeout,
+		AuthType:        defaultEnvironmentBackendRedisAuthType,
+		SecretName:      defaultEnvironmentBackendRedisSecretName,
+		SecretNamespace: defaultEnvironmentBackendRedisSecretNamespace,
+		TLS:             defaultEnvironmentBackendRedisTLS,
+		TLSCA:           defaultEnvironmentBackendRedisTLSCA,
+	})
+	if err != nil {
+		return redisConfig, err
+	}
+
+	redisConfig.RedisConnectionConfig = *connectionConfig
+
+	if err := validateTemplate(defaultEnvironmentBackendRedisStream, redisConfig.Stream); err != nil {
+		return redisConfig, err
+	}
+
+	settings := map[string]int{}
+
+	for variable, def := range map[string]int{
+		defaultEnvironmentBackendRedisMaxLength:    defaultBackendRedisMaxLength,
+		defaultEnvironmentBackendRedisDedupTTL:     defaultBackendRedisDedupTTL,
+		defaultEnvironmentBackendRedisWaitReplicas: defaultBackendRedisWaitReplicas,
+	} {
+		setting, err := utils.IntFromEnvironment(variable, def)
+		if err != nil {
+			return redisConfig, fmt.Errorf("setting from environment - %w", err)
+		}
+
+		if setting < 0 {
+			return redisConfig, fmt.Errorf(
+				"setting from environment [%s=%d] must not be negative - %w",
+				variable,
+				setting,
+				ErrBackendConfigInvalid,
+			)
+		}
+
+		settings[variable] = setting
+	}
+
+	redisConfig.MaxLength = settings[defaultEnvironmentBackendRedisMaxLength]
+	redisConfig.DedupTTL = time.Duration(settings[defaultEnvironmentBackendRedisDedupTTL]) * time.Hour
+	redisConfig.WaitReplicas = settings[defaultEnvironmentBackendRedisWaitReplicas]
+
+	return redisConfig, nil
+}
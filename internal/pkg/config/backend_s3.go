@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendS3Bucket               = "BACKEND_S3_BUCKET"
+	defaultEnvironmentBackendS3Prefix               = "BACKEND_S3_PREFIX"
+	defaultEnvironmentBackendS3Region               = "BACKEND_S3_REGION"
+	defaultEnvironmentBackendS3Endpoint             = "BACKEND_S3_ENDPOINT"
+	defaultEnvironmentBackendS3ForcePathStyle       = "BACKEND_S3_FORCE_PATH_STYLE"
+	defaultEnvironmentBackendS3AuthType             = "BACKEND_S3_AUTH_TYPE"
+	defaultEnvironmentBackendS3SecretName           = "BACKEND_S3_SECRET_NAME"
+	defaultEnvironmentBackendS3SecretNamespace      = "BACKEND_S3_SECRET_NAMESPACE"
+	defaultEnvironmentBackendS3Encryption           = "BACKEND_S3_SSE"
+	defaultEnvironmentBackendS3EncryptionKMSKeyID   = "BACKEND_S3_SSE_KMS_KEY_ID"
+	defaultEnvironmentBackendS3FlushBytes           = "BACKEND_S3_FLUSH_BYTES"
+	defaultEnvironmentBackendS3FlushIntervalMinutes = "BACKEND_S3_FLUSH_INTERVAL_MINUTES"
+
+	// Default Settings for Environment Variables.
+	DefaultBackendS3EncryptionNone       = ""
+	DefaultBackendS3EncryptionAES256     = "AES256"
+	DefaultBackendS3EncryptionKMS        = "aws:kms"
+	defaultBackendS3Region               = "us-east-1"
+	defaultBackendS3AuthType             = DefaultBackendAuthTypeIRSA
+	defaultBackendS3SecretName           = "s3-auth"
+	defaultBackendS3SecretNamespace      = "ocm-log-forwarder"
+	defaultBackendS3FlushBytes           = 5 * 1024 * 1024
+	defaultBackendS3FlushIntervalMinutes = 15
+)
+
+// S3Config represents the configuration of the s3 backend.
+type S3Config struct {
+	Bucket         string
+	Prefix         string
+	Region         string
+	Endpoint       string
+	ForcePathStyle bool
+	AuthType       string
+	Encryption     string
+	KMSKeyID       string
+	FlushBytes     int
+	FlushInterval  time.Duration
+}
+
+// GetS3Config returns the validated configuration of the s3 backend from the environment.
+func GetS3Config() (*S3Config, error) {
+	s3Config := &S3Config{
+		Bucket:         utils.FromEnvironment(defaultEnvironmentBackendS3Bucket, ""),
+		Prefix:         utils.FromEnvironment(defaultEnvironmentBackendS3Prefix, ""),
+		Region:         utils.FromEnvironment(defaultEnvironmentBackendS3Region, defaultBackendS3Region),
+		Endpoint:       utils.FromEnvironment(defaultEnvironmentBackendS3Endpoint, ""),
+		ForcePathStyle: utils.BoolFromString(utils.FromEnvironment(defaultEnvironmentBackendS3ForcePathStyle, "false")),
+		AuthType:       utils.FromEnvironment(defaultEnvironmentBackendS3AuthType, defaultBackendS3AuthType),
+		Encryption:     utils.FromEnvironment(defaultEnvironmentBackendS3Encryption, DefaultBackendS3EncryptionNone),
+		KMSKeyID:       utils.FromEnvironment(defaultEnvironmentBackendS3EncryptionKMSKeyID, ""),
+	}
+
+	if s3Config.Bucket == "" {
+		return s3Config, fmt.Errorf("missing [%s] - %w", defaultEnvironmentBackendS3Bucket, ErrMissingEnvironmentVariable)
+	}
+
+	switch s3Config.AuthType {
+	case DefaultBackendAuthTypeIRSA, DefaultBackendAuthTypeStatic:
+	default:
+		return s3Config, fmt.Errorf("auth type [%s] - %w", s3Config.AuthType, ErrBackendAuthUnknown)
+	}
+
+	switch s3Config.Encryption {
+	case DefaultBackendS3EncryptionNone, DefaultBackendS3EncryptionAES256, DefaultBackendS3EncryptionKMS:
+	default:
+		return s3Config, fmt.Errorf(
+			"server side encryption from environment [%s=%s] - %w",
+			defaultEnvironmentBackendS3Encryption,
+			s3Config.Encryption,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	flushBytes, err := utils.IntFromEnvironment(defaultEnvironmentBackendS3FlushBytes, defaultBackendS3FlushBytes)
+	if err != nil {
+		return s3Config, fmt.Errorf("unable to get s3 flush bytes - %w", err)
+	}
+
+	flushMinutes, err := utils.IntFromEnvironment(defaultEnvironmentBackendS3FlushIntervalMinutes, defaultBackendS3FlushIntervalMinutes)
+	if err != nil {
+		return s3Config, fmt.Errorf("unable to get s3 flush interval - %w", err)
+	}
+
+	if flushBytes < 1 || flushMinutes < 0 {
+		return s3Config, fmt.Errorf(
+			"flush settings [%s=%d, %s=%d] out of range - %w",
+			defaultEnvironmentBackendS3FlushBytes,
+			flushBytes,
+			defaultEnvironmentBackendS3FlushIntervalMinutes,
+			flushMinutes,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	s3Config.FlushBytes = flushBytes
+	s3Config.FlushInterval = time.Duration(flushMinutes) * time.Minute
+
+	return s3Config, nil
+}
+
+// GetS3StaticCredentials returns the static aws credentials for the s3 backend, which are
+// stored in the 'access_key_id' and 'secret_access_key' keys of a kubernetes secret.
+func GetS3StaticCredentials(client *kubernetes.Clientset, ctx context.Context) (accessKeyID, secretAccessKey string, err error) {
+	return getAWSStaticCredentials(
+		client,
+		ctx,
+		utils.FromEnvironment(defaultEnvironmentBackendS3SecretName, defaultBackendS3SecretName),
+		utils.FromEnvironment(defaultEnvironmentBackendS3SecretNamespace, defaultBackendS3SecretNamespace),
+	)
+}
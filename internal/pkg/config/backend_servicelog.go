@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendServiceLogNamespace = "BACKEND_SERVICELOG_NAMESPACE"
+	defaultEnvironmentBackendServiceLogRetention = "BACKEND_SERVICELOG_RETENTION_HOURS"
+
+	// Default Settings for Environment Variables.
+	defaultBackendServiceLogNamespace = "ocm-log-forwarder"
+	defaultBackendServiceLogRetention = 720
+)
+
+// ServiceLogConfig represents the configuration of the servicelog custom resource backend.  A
+// retention of 0 disables garbage collection.
+type ServiceLogConfig struct {
+	Namespace string
+	Retention time.Duration
+}
+
+// GetServiceLogConfig returns the validated configuration of the servicelog custom resource backend
+// from the environment.
+func GetServiceLogConfig() (*ServiceLogConfig, error) {
+	serviceLogConfig := &ServiceLogConfig{
+		Namespace: utils.FromEnvironment(defaultEnvironmentBackendServiceLogNamespace, defaultBackendServiceLogNamespace),
+	}
+
+	retention, err := utils.IntFromEnvironment(defaultEnvironmentBackendServiceLogRetention, defaultBackendServiceLogRetention)
+	if err != nil {
+		return serviceLogConfig, fmt.Errorf("retention from environment - %w", err)
+	}
+
+	if retention < 0 {
+		return serviceLogConfig, fmt.Errorf(
+			"retention from environment [%s=%d] must not be negative - %w",
+			defaultEnvironmentBackendServiceLogRetention,
+			retention,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	serviceLogConfig.Retention = time.Duration(retention) * time.Hour
+
+	return serviceLogConfig, nil
+}
@@ -0,0 +1,32 @@
+package config
+
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendStdOutFormat = "BACKEND_STDOUT_FORMAT"
+
+	// Default Settings for Environment Variables.
+	DefaultBackendStdOutFormatLog   = "log"
+	DefaultBackendStdOutFormatTable = "table"
+	defaultBackendStdOutFormat      = DefaultBackendStdOutFormatLog
+)
+
+// StdOutConfig represents the configuration of the stdout backend.
+type StdOutConfig struct {
+	Format string
+}
+
+// GetStdOutConfig returns the validated configuration of the stdout backend from the environment.
+func GetStdOutConfig() (*StdOutConfig, error) {
+	format, err := getFormat(
+		defaultEnvironmentBackendStdOutFormat,
+		defaultBackendStdOutFormat,
+		DefaultBackendStdOutFormatLog,
+		DefaultBackendStdOutFormatTable,
+		DefaultFormatJSON,
+		DefaultFormatLogfmt,
+		DefaultFormatCEF,
+		DefaultFormatLEEF,
+	)
+
+	return &StdOutConfig{Format: format}, err
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	// Default Environment Variables.
+	defaultEnvironmentBackendSyslogAddress        = "BACKEND_SYSLOG_ADDRESS"
+	defaultEnvironmentBackendSyslogProtocol       = "BACKEND_SYSLOG_PROTOCOL"
+	defaultEnvironmentBackendSyslogFacility       = "BACKEND_SYSLOG_FACILITY"
+	defaultEnvironmentBackendSyslogHostname       = "BACKEND_SYSLOG_HOSTNAME"
+	defaultEnvironmentBackendSyslogAppName        = "BACKEND_SYSLOG_APP_NAME"
+	defaultEnvironmentBackendSyslogSDID           = "BACKEND_SYSLOG_SD_ID"
+	defaultEnvironmentBackendSyslogTLSCA          = "BACKEND_SYSLOG_TLS_CA"
+	defaultEnvironmentBackendSyslogTLSCertificate = "BACKEND_SYSLOG_TLS_CERT"
+	defaultEnvironmentBackendSyslogTLSKey         = "BACKEND_SYSLOG_TLS_KEY"
+	defaultEnvironmentBackendSyslogTLSVerify      = "BACKEND_SYSLOG_TLS_VERIFY"
+	defaultEnvironmentBackendSyslogFormat         = "BACKEND_SYSLOG_FORMAT"
+
+	// Default Settings for Environment Variables.
+	DefaultBackendSyslogProtocolUDP = "udp"
+	DefaultBackendSyslogProtocolTCP = "tcp"
+	DefaultBackendSyslogProtocolTLS = "tls"
+	DefaultBackendSyslogFormatSD    = "rfc5424"
+	defaultBackendSyslogAddress     = "localhost:514"
+	defaultBackendSyslogProtocol    = DefaultBackendSyslogProtocolUDP
+	defaultBackendSyslogFacility    = "local0"
+	defaultBackendSyslogAppName     = "ocm-log-forwarder"
+	defaultBackendSyslogSDID        = "ocm@32473"
+	defaultBackendSyslogTLSVerify   = "true"
+	defaultBackendSyslogFormat      = DefaultBackendSyslogFormatSD
+)
+
+// SyslogConfig represents the configuration of the syslog backend.
+type SyslogConfig struct {
+	Address        string
+	Protocol       string
+	Facility       int
+	Hostname       string
+	AppName        string
+	SDID           string
+	TLSCA          string
+	TLSCertificate string
+	TLSKey         string
+	TLSVerify      bool
+	Format         string
+}
+
+// GetSyslogConfig returns the validated configuration of the syslog backend from the environment.
+func GetSyslogConfig() (*SyslogConfig, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = defaultBackendSyslogAppName
+	}
+
+	syslogConfig := &SyslogConfig{
+		Address:        utils.FromEnvironment(defaultEnvironmentBackendSyslogAddress, defaultBackendSyslogAddress),
+		Protocol:       utils.FromEnvironment(defaultEnvironmentBackendSyslogProtocol, defaultBackendSyslogProtocol),
+		Hostname:       utils.FromEnvironment(defaultEnvironmentBackendSyslogHostname, hostname),
+		AppName:        utils.FromEnvironment(defaultEnvironmentBackendSyslogAppName, defaultBackendSyslogAppName),
+		SDID:           utils.FromEnvironment(defaultEnvironmentBackendSyslogSDID, defaultBackendSyslogSDID),
+		TLSCA:          utils.FromEnvironment(defaultEnvironmentBackendSyslogTLSCA, ""),
+		TLSCertificate: utils.FromEnvironment(defaultEnvironmentBackendSyslogTLSCertificate, ""),
+		TLSKey:         utils.FromEnvironment(defaultEnvironmentBackendSyslogTLSKey, ""),
+		TLSVerify: utils.BoolFromString(
+			utils.FromEnvironment(defaultEnvironmentBackendSyslogTLSVerify, defaultBackendSyslogTLSVerify),
+		),
+	}
+
+	switch syslogConfig.Protocol {
+	case DefaultBackendSyslogProtocolUDP, DefaultBackendSyslogProtocolTCP, DefaultBackendSyslogProtocolTLS:
+	default:
+		return syslogConfig, fmt.Errorf(
+			"protocol from environment [%s=%s] - %w",
+			defaultEnvironmentBackendSyslogProtocol,
+			syslogConfig.Protocol,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	syslogConfig.Format, err = getFormat(
+		defaultEnvironmentBackendSyslogFormat,
+		defaultBackendSyslogFormat,
+		DefaultBackendSyslogFormatSD,
+		DefaultFormatJSON,
+		DefaultFormatCEF,
+		DefaultFormatLEEF,
+	)
+	if err != nil {
+		return syslogConfig, err
+	}
+
+	facilityName := utils.FromEnvironment(defaultEnvironmentBackendSyslogFacility, defaultBackendSyslogFacility)
+
+	facility, ok := syslogFacilities()[facilityName]
+	if !ok {
+		return syslogConfig, fmt.Errorf(
+			"facility from environment [%s=%s] - %w",
+			defaultEnvironmentBackendSyslogFacility,
+			facilityName,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	syslogConfig.Facility = facility
+
+	// a client certificate requires both the certificate and the key
+	if (syslogConfig.TLSCertificate == "") != (syslogConfig.TLSKey == "") {
+		return syslogConfig, fmt.Errorf(
+			"both [%s] and [%s] must be set for a client certificate - %w",
+			defaultEnvironmentBackendSyslogTLSCertificate,
+			defaultEnvironmentBackendSyslogTLSKey,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	return syslogConfig, nil
+}
+
+// syslogFacilities returns the syslog facility codes as defined by RFC 5424.
+func syslogFacilities() map[string]int {
+	return map[string]int{
+		"kern":     0,
+		"user":     1,
+		"mail":     2,
+		"daemon":   3,
+		"auth":     4,
+		"syslog":   5,
+		"lpr":      6,
+		"news":     7,
+		"uucp":     8,
+		"cron":     9,
+		"authpriv": 10,
+		"ftp":      11,
+		"ntp":      12,
+		"security": 13,
+		"console":  14,
+		"solaris":  15,
+		"local0":   16,
+		"local1":   17,
+		"local2":   18,
+		"local3":   19,
+		"local4":   20,
+		"local5":   21,
+		"local6":   22,
+		"local7":   23,
+	}
+}
@@ -18,6 +18,7 @@ type Config struct {
 	SecretNamespace string
 	SecretFile      string
 	TokenFile       string
+	MetricsAddress  string
 
 	Debug bool
 }
@@ -49,6 +50,7 @@ func Initialize() (*Config, error) {
 		SecretNamespace: getSecretNamespace(),
 		SecretFile:      getTokenFile(),
 		TokenFile:       getTokenFile(),
+		MetricsAddress:  getMetricsAddress(),
 		Debug:           getDebug(),
 	}, nil
 }
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// Formats in which backends may render service logs.
+const (
+	DefaultFormatJSON   = "json"
+	DefaultFormatCEF    = "cef"
+	DefaultFormatLEEF   = "leef"
+	DefaultFormatLogfmt = "logfmt"
+)
+
+// getFormat returns the output format of a backend from the environment, which must be one of
+// the formats supported by the backend.
+func getFormat(variable, def string, formats ...string) (string, error) {
+	format := utils.FromEnvironment(variable, def)
+
+	for i := range formats {
+		if format == formats[i] {
+			return format, nil
+		}
+	}
+
+	return format, fmt.Errorf("format from environment [%s=%s] - %w", variable, format, ErrBackendConfigInvalid)
+}
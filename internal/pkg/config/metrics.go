@@ -0,0 +1,17 @@
+package config
+
+import (
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+const (
+	defaultEnvironmentMetricsAddress = "METRICS_ADDRESS"
+
+	// DefaultMetricsAddressDisabled disables the metrics server.
+	DefaultMetricsAddressDisabled = "none"
+	defaultMetricsAddress         = DefaultMetricsAddressDisabled
+)
+
+func getMetricsAddress() string {
+	return utils.FromEnvironment(defaultEnvironmentMetricsAddress, defaultMetricsAddress)
+}
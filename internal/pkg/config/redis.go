@@ -0,0 +1,130 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// NOTE: we are not storing credentials rather pointers to credentials here so
+// we do not need to lint this.
+//
+//nolint:gosec
+const (
+	// Default Settings for the Environment Variables of a Redis Connection.
+	defaultRedisAddress         = "localhost:6379"
+	defaultRedisDatabase        = 0
+	defaultRedisTimeout         = 5
+	defaultRedisAuthType        = DefaultBackendAuthTypeNone
+	defaultRedisSecretName      = "redis-auth"
+	defaultRedisSecretNamespace = "ocm-log-forwarder"
+	defaultRedisTLS             = "false"
+	defaultRedisSecretUsername  = "username"
+	defaultRedisSecretPassword  = "password"
+)
+
+// RedisConnectionConfig represents the settings used to connect to a redis server, which are
+// read from the environment variables of the backend or feature which connects to redis.
+type RedisConnectionConfig struct {
+	Address         string
+	Database        int
+	Timeout         time.Duration
+	AuthType        string
+	SecretName      string
+	SecretNamespace string
+	TLS             bool
+	TLSCA           string
+}
+
+// redisConnectionEnvironment represents the names of the environment variables of a redis
+// connection.
+type redisConnectionEnvironment struct {
+	Address         string
+	Database        string
+	Timeout         string
+	AuthType        string
+	SecretName      string
+	SecretNamespace string
+	TLS             string
+	TLSCA           string
+}
+
+// getRedisConnectionConfig returns the validated settings of a redis connection from the
+// environment.
+func getRedisConnectionConfig(environment *redisConnectionEnvironment) (*RedisConnectionConfig, error) {
+	connectionConfig := &RedisConnectionConfig{
+		Address:         utils.FromEnvironment(environment.Address, defaultRedisAddress),
+		AuthType:        utils.FromEnvironment(environment.AuthType, defaultRedisAuthType),
+		SecretName:      utils.FromEnvironment(environment.SecretName, defaultRedisSecretName),
+		SecretNamespace: utils.FromEnvironment(environment.SecretNamespace, defaultRedisSecretNamespace),
+		TLS:             utils.BoolFromString(utils.FromEnvironment(environment.TLS, defaultRedisTLS)),
+		TLSCA:           utils.FromEnvironment(environment.TLSCA, ""),
+	}
+
+	switch connectionConfig.AuthType {
+	case DefaultBackendAuthTypeBasic, DefaultBackendAuthTypeNone:
+	default:
+		return connectionConfig, fmt.Errorf("auth type [%s] - %w", connectionConfig.AuthType, ErrBackendAuthUnknown)
+	}
+
+	database, err := utils.IntFromEnvironment(environment.Database, defaultRedisDatabase)
+	if err != nil {
+		return connectionConfig, fmt.Errorf("database from environment - %w", err)
+	}
+
+	if database < 0 {
+		return connectionConfig, fmt.Errorf(
+			"database from environment [%s=%d] must not be negative - %w",
+			environment.Database,
+			database,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	timeout, err := utils.IntFromEnvironment(environment.Timeout, defaultRedisTimeout)
+	if err != nil {
+		return connectionConfig, fmt.Errorf("timeout from environment - %w", err)
+	}
+
+	if timeout < 1 {
+		return connectionConfig, fmt.Errorf(
+			"timeout from environment [%s=%d] must be at least 1 - %w",
+			environment.Timeout,
+			timeout,
+			ErrBackendConfigInvalid,
+		)
+	}
+
+	connectionConfig.Database = database
+	connectionConfig.Timeout = time.Duration(timeout) * time.Second
+
+	return connectionConfig, nil
+}
+
+// GetRedisBasicAuth returns the username and password of a redis connection, which are stored in
+// the 'username' and 'password' keys of a kubernetes secret.  The username is optional, in which
+// case the default user is authenticated with the password.
+func GetRedisBasicAuth(
+	client *kubernetes.Clientset,
+	ctx context.Context,
+	connectionConfig *RedisConnectionConfig,
+) (username, password string, err error) {
+	secretName, secretNamespace := connectionConfig.SecretName, connectionConfig.SecretNamespace
+
+	username, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultRedisSecretUsername)
+	if err != nil && !errors.Is(err, ErrBackendSecretMissingKey) {
+		return "", "", fmt.Errorf("unable to retrieve redis username - %w", err)
+	}
+
+	password, err = getSecretValue(client, ctx, secretName, secretNamespace, defaultRedisSecretPassword)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to retrieve redis password - %w", err)
+	}
+
+	return username, password, nil
+}
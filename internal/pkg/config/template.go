@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// validateTemplate validates that a message name template from the environment is not empty and
+// that each of its placeholders is known.
+func validateTemplate(variable, template string) error {
+	if template == "" {
+		return fmt.Errorf("template from environment [%s] must not be empty - %w", variable, ErrBackendConfigInvalid)
+	}
+
+	for _, placeholder := range utils.TemplatePlaceholders(template) {
+		switch placeholder {
+		case utils.TemplateFieldClusterID, utils.TemplateFieldSeverity, utils.TemplateFieldServiceName:
+		default:
+			return fmt.Errorf(
+				"template from environment [%s=%s] has unknown placeholder [{%s}] - %w",
+				variable,
+				template,
+				placeholder,
+				ErrBackendConfigInvalid,
+			)
+		}
+	}
+
+	return nil
+}
+
+// resolveClusterID replaces the cluster id placeholder of a name which is resolved once on startup,
+// rather than for each service log.
+func resolveClusterID(template, clusterID string) string {
+	return utils.ResolveTemplate(template, map[string]string{utils.TemplateFieldClusterID: clusterID})
+}
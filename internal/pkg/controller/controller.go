@@ -8,6 +8,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/backend"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/metrics"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/poller"
 	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
 )
@@ -55,6 +56,17 @@ func (controller *Controller) Run() error {
 	// create a channel to send errors
 	errorSignal := make(chan error)
 
+	// serve metrics; a failure to serve metrics is logged but does not stop forwarding
+	if controller.Config.MetricsAddress != config.DefaultMetricsAddressDisabled {
+		controller.Processor.Log(log.Info().Str("address", controller.Config.MetricsAddress), "starting metrics server")
+
+		go func() {
+			if err := metrics.Serve(controller.Config.MetricsAddress); err != nil {
+				controller.Processor.Log(log.Err(err), "metrics server failed")
+			}
+		}()
+	}
+
 	// start the go routine
 	controller.Processor.Log(log.Info(), "starting main program loop")
 	go controller.Loop(loopSignal, errorSignal)
@@ -0,0 +1,82 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+// NOTE: see the ArcSight Common Event Format (CEF) implementation standard for the format of a
+// CEF event.
+const cefVersion = 0
+
+// CEF renders a service log as an ArcSight Common Event Format event.
+type CEF struct{}
+
+func (formatter *CEF) Format(logEntry *v1.LogEntry) ([]byte, error) {
+	header := []string{
+		fmt.Sprintf("CEF:%d", cefVersion),
+		cefHeader(deviceVendor),
+		cefHeader(deviceProduct),
+		cefHeader(deviceVersion),
+		cefHeader(eventID(logEntry)),
+		cefHeader(logEntry.Summary()),
+		strconv.Itoa(severity(logEntry.Severity())),
+	}
+
+	extensions := [][2]string{
+		{"externalId", logEntry.ID()},
+		{"msg", logEntry.Description()},
+		{"suser", logEntry.Username()},
+		{"cat", string(logEntry.LogType())},
+	}
+
+	// fields without a predefined key are carried as custom strings, which are numbered by their
+	// position so that a field always uses the same custom string
+	for i, custom := range [][2]string{
+		{"clusterId", logEntry.ClusterID()},
+		{"clusterUuid", logEntry.ClusterUUID()},
+		{"subscriptionId", logEntry.SubscriptionID()},
+		{"eventStreamId", logEntry.EventStreamID()},
+		{"internalOnly", strconv.FormatBool(logEntry.InternalOnly())},
+	} {
+		if custom[1] == "" {
+			continue
+		}
+
+		extensions = append(extensions,
+			[2]string{fmt.Sprintf("cs%dLabel", i+1), custom[0]},
+			[2]string{fmt.Sprintf("cs%d", i+1), custom[1]},
+		)
+	}
+
+	if !logEntry.Timestamp().IsZero() {
+		extensions = append([][2]string{{"rt", strconv.FormatInt(logEntry.Timestamp().UnixMilli(), 10)}}, extensions...)
+	}
+
+	pairs := make([]string, 0, len(extensions))
+
+	for _, extension := range extensions {
+		if extension[1] == "" {
+			continue
+		}
+
+		pairs = append(pairs, extension[0]+"="+cefExtension(extension[1]))
+	}
+
+	return []byte(strings.Join(header, "|") + "|" + strings.Join(pairs, " ")), nil
+}
+
+// cefHeader escapes a header field.  Backslashes and pipes are escaped, and line breaks, which
+// are not allowed within a header, are replaced with spaces.
+func cefHeader(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r\n", " ", "\r", " ", "\n", " ").Replace(value)
+}
+
+// cefExtension escapes an extension value.  Backslashes and equal signs are escaped, and line
+// breaks are encoded.
+func cefExtension(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\r", `\r`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,92 @@
+package format
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+var (
+	ErrFormatUnknown = errors.New("format is unknown")
+)
+
+// Device fields of the CEF and LEEF headers, which identify the source of the events.
+const (
+	deviceVendor  = "Red Hat"
+	deviceProduct = "OpenShift Cluster Manager"
+	deviceVersion = "v1"
+
+	// deviceEventDefault is the event id of service logs without a service name.
+	deviceEventDefault = "ServiceLog"
+
+	// Severities on the 0 to 10 scale which is shared by CEF and LEEF.
+	severityCritical      = 10
+	severityError         = 7
+	severityWarning       = 5
+	severityInformational = 3
+	severityDebug         = 1
+)
+
+// Formatter renders a service log as a single line, without a trailing newline, so that it can be
+// used by line oriented backends such as files and syslog.
+type Formatter interface {
+	Format(*v1.LogEntry) ([]byte, error)
+}
+
+// NewFormatter returns the formatter of a format.
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case config.DefaultFormatJSON:
+		return &JSON{}, nil
+	case config.DefaultFormatCEF:
+		return &CEF{}, nil
+	case config.DefaultFormatLEEF:
+		return &LEEF{}, nil
+	case config.DefaultFormatLogfmt:
+		return &Logfmt{}, nil
+	default:
+		return nil, fmt.Errorf("format [%s] - %w", format, ErrFormatUnknown)
+	}
+}
+
+// JSON renders a service log as the compact json returned by the OCM API.
+type JSON struct{}
+
+func (formatter *JSON) Format(logEntry *v1.LogEntry) ([]byte, error) {
+	line, err := utils.MarshalLogEntry(logEntry)
+	if err != nil {
+		return nil, fmt.Errorf("unable to format service log as json - %w", err)
+	}
+
+	return line, nil
+}
+
+// severity maps a service log severity to the 0 to 10 severity scale which is shared by CEF and
+// LEEF.
+func severity(logSeverity v1.Severity) int {
+	switch utils.SeverityLevel(logSeverity) {
+	case utils.SeverityLevelCritical:
+		return severityCritical
+	case utils.SeverityLevelError:
+		return severityError
+	case utils.SeverityLevelWarning:
+		return severityWarning
+	case utils.SeverityLevelDebug:
+		return severityDebug
+	default:
+		return severityInformational
+	}
+}
+
+// eventID returns the event id of a service log, which is its service name.
+func eventID(logEntry *v1.LogEntry) string {
+	if logEntry.ServiceName() == "" {
+		return deviceEventDefault
+	}
+
+	return logEntry.ServiceName()
+}
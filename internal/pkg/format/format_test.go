@@ -0,0 +1,109 @@
+package format
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+func TestFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	timestamp := time.Date(2023, 4, 5, 10, 44, 53, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		format      string
+		severity    v1.Severity
+		serviceName string
+		summary     string
+		description string
+		want        string
+	}{
+		{
+			name:        "ensure cef header and extension values are escaped",
+			format:      "cef",
+			severity:    v1.SeverityError,
+			serviceName: "SREManualAction",
+			summary:     "node|failure\nreplaced",
+			description: `path=C:\tmp` + "\nline",
+			want: `CEF:0|Red Hat|OpenShift Cluster Manager|v1|SREManualAction|node\|failure replaced|7|` +
+				`rt=1680691493000 externalId=1 msg=path\=C:\\tmp\nline cs1Label=clusterId cs1=test ` +
+				`cs5Label=internalOnly cs5=false`,
+		},
+		{
+			name:     "ensure cef uses the default event id without a service name",
+			format:   "cef",
+			severity: "Critical",
+			summary:  "cluster unreachable",
+			want: "CEF:0|Red Hat|OpenShift Cluster Manager|v1|ServiceLog|cluster unreachable|10|" +
+				"rt=1680691493000 externalId=1 cs1Label=clusterId cs1=test cs5Label=internalOnly cs5=false",
+		},
+		{
+			name:        "ensure leef attribute values are escaped",
+			format:      "leef",
+			severity:    v1.SeverityWarning,
+			serviceName: "Cluster|Upgrade",
+			summary:     "upgrade\tscheduled",
+			description: "a=b\nc",
+			want: "LEEF:1.0|Red Hat|OpenShift Cluster Manager|v1|Cluster\\|Upgrade|" +
+				"devTime=Apr 05 2023 10:44:53.000 UTC\tdevTimeFormat=MMM dd yyyy HH:mm:ss.SSS z\tsev=5\tid=1\t" +
+				"summary=upgrade scheduled\tdescription=a=b c\tseverity=Warning\tserviceName=Cluster|Upgrade\t" +
+				"clusterId=test\tinternalOnly=false",
+		},
+		{
+			name:        "ensure logfmt values with spaces and line breaks are quoted",
+			format:      "logfmt",
+			severity:    v1.SeverityInfo,
+			serviceName: "SREManualAction",
+			summary:     "node replaced",
+			description: "a=b\nc",
+			want: `timestamp=2023-04-05T10:44:53Z id=1 cluster_id=test severity=Info service_name=SREManualAction ` +
+				`internal_only=false summary="node replaced" description="a=b\nc"`,
+		},
+		{
+			name:     "ensure json is the service log as returned by the api",
+			format:   "json",
+			severity: v1.SeverityInfo,
+			summary:  "test",
+			want: `{"kind":"LogEntry","id":"1","cluster_id":"test","description":"","internal_only":false,` +
+				`"service_name":"","severity":"Info","summary":"test","timestamp":"2023-04-05T10:44:53Z"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			logEntry, err := v1.NewLogEntry().
+				ID("1").
+				ClusterID("test").
+				Severity(tt.severity).
+				ServiceName(tt.serviceName).
+				Summary(tt.summary).
+				Description(tt.description).
+				Timestamp(timestamp).
+				InternalOnly(false).
+				Build()
+			if err != nil {
+				t.Fatalf("unable to build log entry - %v", err)
+			}
+
+			formatter, err := NewFormatter(tt.format)
+			if err != nil {
+				t.Fatalf("NewFormatter() error = %v", err)
+			}
+
+			got, err := formatter.Format(logEntry)
+			if err != nil {
+				t.Fatalf("Formatter.Format() error = %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Formatter.Format() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
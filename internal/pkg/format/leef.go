@@ -0,0 +1,79 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+// NOTE: see the IBM QRadar Log Event Extended Format (LEEF) specification for the format of a
+// LEEF event.  Version 1.0 is used as it is supported by every QRadar release, and separates
+// attributes with a tab.
+const (
+	leefVersion         = "1.0"
+	leefDelimiter       = "\t"
+	leefTimeFormat      = "Jan 02 2006 15:04:05.000 MST"
+	leefTimeFormatValue = "MMM dd yyyy HH:mm:ss.SSS z"
+)
+
+// LEEF renders a service log as an IBM QRadar Log Event Extended Format event.
+type LEEF struct{}
+
+func (formatter *LEEF) Format(logEntry *v1.LogEntry) ([]byte, error) {
+	header := []string{
+		"LEEF:" + leefVersion,
+		leefHeader(deviceVendor),
+		leefHeader(deviceProduct),
+		leefHeader(deviceVersion),
+		leefHeader(eventID(logEntry)),
+	}
+
+	attributes := [][2]string{
+		{"sev", strconv.Itoa(severity(logEntry.Severity()))},
+		{"cat", string(logEntry.LogType())},
+		{"usrName", logEntry.Username()},
+		{"id", logEntry.ID()},
+		{"summary", logEntry.Summary()},
+		{"description", logEntry.Description()},
+		{"severity", string(logEntry.Severity())},
+		{"serviceName", logEntry.ServiceName()},
+		{"clusterId", logEntry.ClusterID()},
+		{"clusterUuid", logEntry.ClusterUUID()},
+		{"subscriptionId", logEntry.SubscriptionID()},
+		{"eventStreamId", logEntry.EventStreamID()},
+		{"internalOnly", strconv.FormatBool(logEntry.InternalOnly())},
+	}
+
+	if !logEntry.Timestamp().IsZero() {
+		attributes = append([][2]string{
+			{"devTime", logEntry.Timestamp().UTC().Format(leefTimeFormat)},
+			{"devTimeFormat", leefTimeFormatValue},
+		}, attributes...)
+	}
+
+	pairs := make([]string, 0, len(attributes))
+
+	for _, attribute := range attributes {
+		if attribute[1] == "" {
+			continue
+		}
+
+		pairs = append(pairs, fmt.Sprintf("%s=%s", attribute[0], leefAttribute(attribute[1])))
+	}
+
+	return []byte(strings.Join(header, "|") + "|" + strings.Join(pairs, leefDelimiter)), nil
+}
+
+// leefHeader escapes a header field.  Backslashes and pipes are escaped, and line breaks, which
+// are not allowed within a header, are replaced with spaces.
+func leefHeader(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r\n", " ", "\r", " ", "\n", " ").Replace(value)
+}
+
+// leefAttribute escapes an attribute value.  LEEF has no escape for the delimiter, so tabs and
+// line breaks are replaced with spaces.
+func leefAttribute(value string) string {
+	return strings.NewReplacer("\t", " ", "\r\n", " ", "\r", " ", "\n", " ").Replace(value)
+}
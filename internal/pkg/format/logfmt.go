@@ -0,0 +1,65 @@
+package format
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+// Logfmt renders a service log as a line of logfmt key=value pairs.  The keys are the field names
+// of the service log as returned by the OCM API.
+type Logfmt struct{}
+
+func (formatter *Logfmt) Format(logEntry *v1.LogEntry) ([]byte, error) {
+	pairs := [][2]string{
+		{"id", logEntry.ID()},
+		{"cluster_id", logEntry.ClusterID()},
+		{"cluster_uuid", logEntry.ClusterUUID()},
+		{"subscription_id", logEntry.SubscriptionID()},
+		{"event_stream_id", logEntry.EventStreamID()},
+		{"log_type", string(logEntry.LogType())},
+		{"severity", string(logEntry.Severity())},
+		{"service_name", logEntry.ServiceName()},
+		{"username", logEntry.Username()},
+		{"internal_only", strconv.FormatBool(logEntry.InternalOnly())},
+		{"summary", logEntry.Summary()},
+		{"description", logEntry.Description()},
+	}
+
+	if !logEntry.Timestamp().IsZero() {
+		pairs = append([][2]string{{"timestamp", logEntry.Timestamp().UTC().Format(time.RFC3339Nano)}}, pairs...)
+	}
+
+	var line strings.Builder
+
+	for _, pair := range pairs {
+		if pair[1] == "" {
+			continue
+		}
+
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+
+		line.WriteString(pair[0] + "=" + logfmtValue(pair[1]))
+	}
+
+	return []byte(line.String()), nil
+}
+
+// logfmtValue quotes a value which contains spaces, quotes, equals signs or control characters,
+// such as line breaks, so that the value is parsed as a single value.
+func logfmtValue(value string) string {
+	needsQuote := strings.IndexFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || unicode.IsControl(r)
+	}) >= 0
+
+	if needsQuote {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	metricsNamespace         = "ocm_log_forwarder"
+	metricsPath              = "/metrics"
+	metricsReadHeaderTimeout = 10 * time.Second
+)
+
+// NOTE: metrics are registered with the default registry when the package is loaded, which is
+// the idiomatic way to define prometheus metrics.
+//
+//nolint:gochecknoglobals
+var (
+	// ElasticSearchRetries counts the documents which were retried after a retryable failure.
+	ElasticSearchRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "elasticsearch",
+		Name:      "retries_total",
+		Help:      "Number of documents retried after a retryable bulk failure.",
+	}, []string{"cluster"})
+
+	// ElasticSearchDeadLetters counts the documents which were permanently rejected and written
+	// to the dead letter destination.
+	ElasticSearchDeadLetters = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "elasticsearch",
+		Name:      "dead_letters_total",
+		Help:      "Number of documents permanently rejected and written to the dead letter destination.",
+	}, []string{"cluster", "destination"})
+)
+
+// Serve serves the metrics of the default registry at the given address.  It blocks until the
+// server fails.
+func Serve(address string) error {
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.Handler())
+
+	server := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: metricsReadHeaderTimeout,
+	}
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("unable to serve metrics on [%s] - %w", address, err)
+	}
+
+	return nil
+}
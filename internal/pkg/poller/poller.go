@@ -70,6 +70,20 @@ func (poller *Poller) Request(proc *processor.Processor) (response Response, err
 	return response, nil
 }
 
+func (poller *Poller) RequestClusterName(proc *processor.Processor) (string, error) {
+	response, err := poller.Client.ClustersMgmt().
+		V1().
+		Clusters().
+		Cluster(proc.Config.ClusterID).
+		Get().
+		Send()
+	if err != nil {
+		return "", fmt.Errorf("error requesting cluster - %w", err)
+	}
+
+	return response.Body().Name(), nil
+}
+
 func (poller *Poller) RequestPage(proc *processor.Processor, pageNum int) (*v1.ClusterLogsListResponse, error) {
 	request := poller.Client.ServiceLogs().
 		V1().
@@ -7,6 +7,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/net/context"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -20,6 +21,9 @@ type Processor struct {
 	Context      context.Context
 	ResponseData []byte
 	KubeClient   *kubernetes.Clientset
+
+	// KubeDynamicClient is used for custom resources, which have no typed client.
+	KubeDynamicClient dynamic.Interface
 }
 
 func NewProcessor(cfg *config.Config) (*Processor, error) {
@@ -35,28 +39,33 @@ func NewProcessor(cfg *config.Config) (*Processor, error) {
 		Context: context.Background(),
 	}
 
-	// create the kubernetes client and store it on the processor
-	client, err := newKubeClient(*processor)
+	// create the kubernetes clients and store them on the processor
+	kubeConfig, err := newKubeConfig(*processor)
+	if err != nil {
+		return &Processor{}, fmt.Errorf("error creating kubernetes client - %w", err)
+	}
+
+	client, err := newKubeClient(kubeConfig)
 	if err != nil {
 		return &Processor{}, fmt.Errorf("error creating kubernetes client - %w", err)
 	}
 	processor.KubeClient = client
 
+	dynamicClient, err := newKubeDynamicClient(kubeConfig)
+	if err != nil {
+		return &Processor{}, fmt.Errorf("error creating kubernetes client - %w", err)
+	}
+	processor.KubeDynamicClient = dynamicClient
+
 	return processor, nil
 }
 
-func newKubeClient(proc Processor) (*kubernetes.Clientset, error) {
+func newKubeConfig(proc Processor) (*rest.Config, error) {
 	proc.Log(log.Info().Str("cluster", proc.Config.ClusterID), "initializing kubernetes cluster config")
 	cfg, err := rest.InClusterConfig()
 
 	if err == nil {
-		// create the clientset for the config
-		client, clusterErr := kubernetes.NewForConfig(cfg)
-		if clusterErr != nil {
-			return &kubernetes.Clientset{}, fmt.Errorf("unable to create kubernetes client from in cluster - %w", clusterErr)
-		}
-
-		return client, nil
+		return cfg, nil
 	}
 
 	proc.Log(log.Warn().Str("cluster", proc.Config.ClusterID), "unable to initialize in-cluster config; attempting file initialization")
@@ -66,16 +75,30 @@ func newKubeClient(proc Processor) (*kubernetes.Clientset, error) {
 	proc.Log(log.Info().Str("cluster", proc.Config.ClusterID), "initializing kubernetes file config")
 	cfg, err = clientcmd.BuildConfigFromFlags("", kubeConfig)
 	if err == nil {
-		// create the clientset for the config
-		client, fileErr := kubernetes.NewForConfig(cfg)
-		if fileErr != nil {
-			return &kubernetes.Clientset{}, fmt.Errorf("unable to create kubernetes client from kubeconfig - %w", fileErr)
-		}
+		return cfg, nil
+	}
+
+	return &rest.Config{}, fmt.Errorf("unable to create kubernetes client config - %w", err)
+}
 
-		return client, nil
+func newKubeClient(cfg *rest.Config) (*kubernetes.Clientset, error) {
+	// create the clientset for the config
+	client, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return &kubernetes.Clientset{}, fmt.Errorf("unable to create kubernetes client - %w", err)
+	}
+
+	return client, nil
+}
+
+func newKubeDynamicClient(cfg *rest.Config) (dynamic.Interface, error) {
+	// create the dynamic client for the config, which is used for custom resources
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create kubernetes dynamic client - %w", err)
 	}
 
-	return &kubernetes.Clientset{}, fmt.Errorf("unable to create kubernetes client - %w", err)
+	return client, nil
 }
 
 func (proc Processor) Log(event *zerolog.Event, message string) {
@@ -0,0 +1,63 @@
+package redisclient
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/config"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/processor"
+	"github.com/scottd018/ocm-log-forwarder/internal/pkg/utils"
+)
+
+// New returns a client which is connected to the redis server of a connection.  It is shared by
+// everything which writes to redis, such as the redis streams backend and the elasticsearch dead
+// letter queue.
+func New(proc *processor.Processor, connectionConfig *config.RedisConnectionConfig) (*redis.Client, error) {
+	options := &redis.Options{
+		Addr:         connectionConfig.Address,
+		DB:           connectionConfig.Database,
+		ReadTimeout:  connectionConfig.Timeout,
+		WriteTimeout: connectionConfig.Timeout,
+	}
+
+	var err error
+
+	if connectionConfig.AuthType == config.DefaultBackendAuthTypeBasic {
+		options.Username, options.Password, err = config.GetRedisBasicAuth(proc.KubeClient, proc.Context, connectionConfig)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve redis credentials - %w", err)
+		}
+	}
+
+	if connectionConfig.TLS {
+		if options.TLSConfig, err = getTLSConfig(connectionConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	client := redis.NewClient(options)
+
+	// ping the server now so that an unreachable server or invalid credentials fail fast
+	if err := client.Ping(proc.Context).Err(); err != nil {
+		return nil, fmt.Errorf("unable to connect to redis [%s] - %w", connectionConfig.Address, err)
+	}
+
+	return client, nil
+}
+
+func getTLSConfig(connectionConfig *config.RedisConnectionConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if connectionConfig.TLSCA != "" {
+		pool, err := utils.CertPoolFromFile(connectionConfig.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
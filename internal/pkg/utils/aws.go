@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// GetAWSConfig returns the aws configuration for a region.  Static credentials are used if
+// provided, otherwise the default credential chain is used which includes IRSA credentials
+// injected by the pod identity webhook.
+func GetAWSConfig(ctx context.Context, region, accessKeyID, secretAccessKey string) (aws.Config, error) {
+	options := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion(region),
+	}
+
+	if accessKeyID != "" {
+		options = append(options, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, ""),
+		))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("unable to load aws config - %w", err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+// MarshalLogEntry returns the raw service log as compact json.  The OCM SDK marshals
+// with indentation, which is not suitable for line delimited output.
+func MarshalLogEntry(logEntry *v1.LogEntry) ([]byte, error) {
+	var indented, compact bytes.Buffer
+
+	if err := v1.MarshalLogEntry(logEntry, &indented); err != nil {
+		return nil, fmt.Errorf("unable to marshal service log [%s] - %w", logEntry.ID(), err)
+	}
+
+	if err := json.Compact(&compact, indented.Bytes()); err != nil {
+		return nil, fmt.Errorf("unable to compact service log [%s] - %w", logEntry.ID(), err)
+	}
+
+	return compact.Bytes(), nil
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+// SeverityCritical is not part of the generated OCM SDK enumeration, however it
+// is returned by the service log API for some critical notices.
+const SeverityCritical v1.Severity = "Critical"
+
+// Severity levels ordered from least to most severe.  These allow comparing
+// service log severities against a configured threshold.
+const (
+	SeverityLevelUnknown = iota
+	SeverityLevelDebug
+	SeverityLevelInfo
+	SeverityLevelWarning
+	SeverityLevelError
+	SeverityLevelCritical
+)
+
+// SeverityLevel returns the comparable level of a service log severity.
+func SeverityLevel(severity v1.Severity) int {
+	return map[string]int{
+		strings.ToLower(string(v1.SeverityDebug)):   SeverityLevelDebug,
+		strings.ToLower(string(v1.SeverityInfo)):    SeverityLevelInfo,
+		strings.ToLower(string(v1.SeverityWarning)): SeverityLevelWarning,
+		strings.ToLower(string(v1.SeverityError)):   SeverityLevelError,
+		strings.ToLower(string(v1.SeverityFatal)):   SeverityLevelCritical,
+		strings.ToLower(string(SeverityCritical)):   SeverityLevelCritical,
+	}[strings.ToLower(string(severity))]
+}
+
+// SeverityAtLeast determines if a service log severity is at or above a threshold severity.
+func SeverityAtLeast(severity, threshold v1.Severity) bool {
+	level := SeverityLevel(severity)
+
+	return level != SeverityLevelUnknown && level >= SeverityLevel(threshold)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"regexp"
+
+	v1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+// Placeholders which may be used in the templates of message names, such as subjects, streams
+// and routing keys.
+const (
+	TemplateFieldClusterID   = "cluster_id"
+	TemplateFieldSeverity    = "severity"
+	TemplateFieldServiceName = "service_name"
+	TemplateFieldExternalID  = "external_id"
+)
+
+// templatePlaceholder matches a placeholder within a template, such as '{cluster_id}'.
+var templatePlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// TemplateFields returns the values of the template placeholders for a service log.
+func TemplateFields(clusterID string, logEntry *v1.LogEntry) map[string]string {
+	return map[string]string{
+		TemplateFieldClusterID:   clusterID,
+		TemplateFieldSeverity:    string(logEntry.Severity()),
+		TemplateFieldServiceName: logEntry.ServiceName(),
+	}
+}
+
+// TemplatePlaceholders returns the names of the placeholders within a template.
+func TemplatePlaceholders(template string) []string {
+	placeholders := []string{}
+
+	for _, match := range templatePlaceholder.FindAllStringSubmatch(template, -1) {
+		placeholders = append(placeholders, match[1])
+	}
+
+	return placeholders
+}
+
+// ResolveTemplate replaces the placeholders of a template with their values.  Placeholders
+// without a value are left unchanged.
+func ResolveTemplate(template string, fields map[string]string) string {
+	return ResolveTemplateFunc(template, func(placeholder string) (string, bool) {
+		value, ok := fields[placeholder]
+
+		return value, ok
+	})
+}
+
+// ResolveTemplateFunc replaces the placeholders of a template with the values returned by the
+// resolve function, which is passed the name of each placeholder.  Placeholders which are not
+// resolved are left unchanged.
+func ResolveTemplateFunc(template string, resolve func(placeholder string) (string, bool)) string {
+	return templatePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		if value, ok := resolve(placeholder[1 : len(placeholder)-1]); ok {
+			return value
+		}
+
+		return placeholder
+	})
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrInvalidCA = errors.New("unable to parse certificate authority")
+
+// CertPoolFromFile returns a certificate pool with the certificate authorities of a pem file.
+func CertPoolFromFile(path string) (*x509.CertPool, error) {
+	caBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read certificate authority [%s] - %w", path, err)
+	}
+
+	return CertPoolFromPEM(path, caBytes)
+}
+
+// CertPoolFromPEM returns a certificate pool with the certificate authorities of a pem bundle.
+// The source of the bundle, such as its path, is used to identify it in errors.
+func CertPoolFromPEM(source string, caBytes []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBytes) {
+		return nil, fmt.Errorf("certificate authority [%s] - %w", source, ErrInvalidCA)
+	}
+
+	return pool, nil
+}
@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -25,3 +27,28 @@ func BoolFromString(str string) bool {
 		"t":    true,
 	}[lower]
 }
+
+func IntFromEnvironment(variable string, def int) (int, error) {
+	varFromEnv := os.Getenv(variable)
+	if varFromEnv == "" {
+		return def, nil
+	}
+
+	value, err := strconv.Atoi(varFromEnv)
+	if err != nil {
+		return 0, fmt.Errorf("unable to convert environment variable [%s=%s] to int value - %w", variable, varFromEnv, err)
+	}
+
+	return value, nil
+}
+
+// Truncate shortens a string to a maximum number of characters, including a suffix which marks the
+// string as truncated.  The string is cut between characters, so that it remains valid UTF-8.
+func Truncate(str string, length int, suffix string) string {
+	runes := []rune(str)
+	if len(runes) <= length {
+		return str
+	}
+
+	return string(runes[:length-len([]rune(suffix))]) + suffix
+}